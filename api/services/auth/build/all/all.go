@@ -6,14 +6,33 @@ import (
 
 	"github.com/ardanlabs/service/app/domain/authapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
+	"github.com/ardanlabs/service/app/domain/oauthapp"
 	"github.com/ardanlabs/service/app/domain/publicapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/domain/identitybus/stores/identitydb"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus/stores/lockoutdb"
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/domain/mfabus/stores/mfadb"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus/stores/outboxdb"
 	"github.com/ardanlabs/service/business/domain/publicuesrbus"
 	publicuserdb "github.com/ardanlabs/service/business/domain/publicuesrbus/stores/publicuserdb"
 	pubicusercache "github.com/ardanlabs/service/business/domain/publicuesrbus/stores/usercache"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus/stores/sessiondb"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/domain/verifybus/stores/verifydb"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/foundation/web"
 )
@@ -32,7 +51,15 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
 	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
+	outboxBus := outboxbus.NewBusiness(cfg.Log, outboxdb.NewStore(cfg.Log, cfg.DB))
+	auditBus := auditbus.NewBusiness(cfg.Log, auditdb.NewStore(cfg.Log, cfg.DB))
+	userBus := userbus.NewBusiness(cfg.Log, delegate, outboxBus, auditBus, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
+	apiKeyBus := apikeybus.NewBusiness(cfg.Log, userBus, apikeydb.NewStore(cfg.Log, cfg.DB))
+	lockoutBus := lockoutbus.NewBusiness(cfg.Log, cfg.AuthConfig.Lockout, lockoutdb.NewStore(cfg.Log, cfg.DB))
+	mfaBus := mfabus.NewBusiness(cfg.Log, cfg.AuthConfig.MFA, mfadb.NewStore(cfg.Log, cfg.DB))
+	tokenBus := tokenbus.NewBusiness(cfg.Log, tokendb.NewStore(cfg.Log, cfg.DB))
+	sessionBus := sessionbus.NewBusiness(cfg.Log, tokenBus, sessiondb.NewStore(cfg.Log, cfg.DB))
+	verifyBus := verifybus.NewBusiness(cfg.Log, verifydb.NewStore(cfg.Log, cfg.DB))
 	publicUserBus := publicuesrbus.NewBusiness(cfg.Log, delegate, pubicusercache.NewStore(cfg.Log, publicuserdb.NewStore(cfg.Log, cfg.DB), time.Minute))
 
 	checkapp.Routes(app, checkapp.Config{
@@ -42,13 +69,42 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	})
 
 	authapp.Routes(app, authapp.Config{
-		UserBus: userBus,
-		Auth:    cfg.Auth,
+		UserBus:          userBus,
+		APIKeyBus:        apiKeyBus,
+		LockoutBus:       lockoutBus,
+		SessionBus:       sessionBus,
+		Auth:             cfg.Auth,
+		ImpersonationTTL: cfg.AuthConfig.ImpersonationTTL,
 	})
 
 	publicapp.Routes(app, publicapp.Config{
 		Auth:             cfg.Auth,
+		DB:               cfg.DB,
 		PublicNewUserBus: publicUserBus,
-		Kid:              "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1",
+		UserBus:          userBus,
+		TokenBus:         tokenBus,
+		VerifyBus:        verifyBus,
+		LockoutBus:       lockoutBus,
+		MFABus:           mfaBus,
+		SessionBus:       sessionBus,
+		Mailer:           cfg.AuthConfig.Mailer,
+		AppURL:           cfg.AuthConfig.AppURL,
+		AccessTTL:        cfg.AuthConfig.AccessTokenTTL,
+		RefreshTTL:       cfg.AuthConfig.RefreshTokenTTL,
 	})
+
+	// OAuth logins are only available when at least one provider is enabled.
+	if cfg.AuthConfig.OAuth != nil {
+		oauthapp.Routes(app, oauthapp.Config{
+			Auth:        cfg.Auth,
+			Log:         cfg.Log,
+			DB:          cfg.DB,
+			IdentityBus: identitybus.NewBusiness(cfg.Log, identitybus.Config{TrustedProviders: cfg.AuthConfig.OAuthTrusted}, userBus, identitydb.NewStore(cfg.Log, cfg.DB)),
+			Registry:    cfg.AuthConfig.OAuth,
+			UIURL:       cfg.AuthConfig.OAuthUIURL,
+			SessionBus:  sessionBus,
+			MFABus:      mfaBus,
+			VerifyBus:   verifyBus,
+		})
+	}
 }
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"expvar"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
 	"runtime"
@@ -17,9 +19,13 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/debug"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/oauth"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/keystore"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/mailer"
 	"github.com/ardanlabs/service/foundation/otel"
 )
 
@@ -70,12 +76,70 @@ func run(ctx context.Context, log *logger.Logger) error {
 			APIHost            string        `conf:"default:0.0.0.0:6000"`
 			DebugHost          string        `conf:"default:0.0.0.0:6010"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			TrustedProxies     []string
 		}
 		Auth struct {
-			KeysEnvVar string
-			KeysFolder string `conf:"default:zarf/keys/"`
-			ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
-			Issuer     string `conf:"default:service project"`
+			KeysEnvVar       string
+			KeysFolder       string        `conf:"default:zarf/keys/"`
+			ActiveKID        string        `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+			Issuer           string        `conf:"default:service project"`
+			AccessTTL        time.Duration `conf:"default:15m"`
+			RefreshTTL       time.Duration `conf:"default:720h"`
+			ImpersonationTTL time.Duration `conf:"default:15m"`
+			KeysReload       time.Duration `conf:"default:0s"`
+			PolicyPath       string
+			PolicyReload     time.Duration `conf:"default:30s"`
+		}
+		Lockout struct {
+			EmailThreshold int           `conf:"default:5"`
+			IPThreshold    int           `conf:"default:20"`
+			BaseLockout    time.Duration `conf:"default:1m"`
+			MaxLockout     time.Duration `conf:"default:1h"`
+			ResetAfter     time.Duration `conf:"default:1h"`
+		}
+		MFA struct {
+			Key    string `conf:"mask"`
+			Issuer string `conf:"default:Ardan Labs Service"`
+		}
+		Mail struct {
+			Host     string
+			Port     int `conf:"default:587"`
+			Username string
+			Password string `conf:"mask"`
+			From     string `conf:"default:noreply@localhost"`
+			AppURL   string `conf:"default:http://localhost:3000"`
+			Dev      bool   `conf:"default:false"`
+		}
+		OAuth struct {
+			StateKey     string `conf:"mask"`
+			CallbackHost string `conf:"default:http://localhost:6000"`
+			UIURL        string `conf:"default:http://localhost:3000"`
+			SecureCookie bool   `conf:"default:false"`
+			Trusted      []string
+			GitHub       struct {
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				CallbackURL  string
+			}
+			GitLab struct {
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				CallbackURL  string
+				BaseURL      string `conf:"default:https://gitlab.com"`
+			}
+			Google struct {
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				CallbackURL  string
+			}
+			OIDC struct {
+				Name         string `conf:"default:oidc"`
+				IssuerURL    string
+				ClientID     string
+				ClientSecret string `conf:"mask"`
+				CallbackURL  string
+				Scopes       []string
+			}
 		}
 		DB struct {
 			User         string `conf:"default:postgres"`
@@ -170,11 +234,53 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("no keys exist: %w", err)
 	}
 
+	if err := ks.SetActive(cfg.Auth.ActiveKID); err != nil {
+		return fmt.Errorf("setting active kid[%s]: %w", cfg.Auth.ActiveKID, err)
+	}
+
+	// Keys are rotated by provisioning the new key to every replica and then
+	// changing the active kid. When a reload interval is configured, the keys
+	// folder is checked for new keys on that schedule so every replica can
+	// verify tokens signed with a new key before any replica signs with it.
+	// Keys removed from the folder stay loaded until the next restart.
+
+	if cfg.Auth.KeysReload > 0 {
+		ctxKeys, cancelKeys := context.WithCancel(ctx)
+		defer cancelKeys()
+
+		go func() {
+			known := n1 + n2
+
+			ticker := time.NewTicker(cfg.Auth.KeysReload)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					n, err := ks.LoadByFileSystem(os.DirFS(cfg.Auth.KeysFolder))
+					if err != nil {
+						log.Error(ctxKeys, "keys reload", "folder", cfg.Auth.KeysFolder, "err", err)
+						continue
+					}
+					if n != known {
+						log.Info(ctxKeys, "keys reload", "status", "new keys loaded", "folder", cfg.Auth.KeysFolder, "keys", n)
+						known = n
+					}
+
+				case <-ctxKeys.Done():
+					return
+				}
+			}
+		}()
+	}
+
 	authCfg := auth.Config{
-		Log:       log,
-		DB:        db,
-		KeyLookup: ks,
-		Issuer:    cfg.Auth.Issuer,
+		Log:        log,
+		DB:         db,
+		KeyLookup:  ks,
+		Issuer:     cfg.Auth.Issuer,
+		ActiveKID:  cfg.Auth.ActiveKID,
+		PolicyPath: cfg.Auth.PolicyPath,
 	}
 
 	ath, err := auth.New(authCfg)
@@ -182,6 +288,163 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	// When the policies are loaded from disk, they are checked for changes on
+	// the reload interval. A bad policy is logged and the last good policy
+	// remains in use.
+
+	if cfg.Auth.PolicyPath != "" && cfg.Auth.PolicyReload > 0 {
+		ctxReload, cancelReload := context.WithCancel(ctx)
+		defer cancelReload()
+
+		go func() {
+			ticker := time.NewTicker(cfg.Auth.PolicyReload)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					reloaded, err := ath.ReloadPolicy(ctxReload)
+					if err != nil {
+						log.Error(ctxReload, "policy reload", "path", cfg.Auth.PolicyPath, "err", err)
+						continue
+					}
+					if reloaded {
+						log.Info(ctxReload, "policy reload", "status", "new policy active", "path", cfg.Auth.PolicyPath)
+					}
+
+				case <-ctxReload.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize mail support
+
+	log.Info(ctx, "startup", "status", "initializing mail support")
+
+	// Without an SMTP host the messages are kept in memory, which is only
+	// acceptable in development environments. The tokens they carry would
+	// never be delivered and would pile up, so this must be asked for.
+
+	var mlr mailer.Mailer = mailer.NewMemory()
+	switch {
+	case cfg.Mail.Host != "":
+		mlr, err = mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			Username: cfg.Mail.Username,
+			Password: cfg.Mail.Password,
+			From:     cfg.Mail.From,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing mailer: %w", err)
+		}
+
+	case cfg.Mail.Dev:
+		log.Info(ctx, "startup", "status", "no smtp host configured, mail will not be delivered")
+
+	default:
+		return errors.New("mail: a host is required unless dev mode is enabled")
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize OAuth support
+
+	log.Info(ctx, "startup", "status", "initializing oauth support")
+
+	// A provider is enabled by configuring its client id. When no callback
+	// url is configured for a provider, one is built from the callback host.
+	// Only the providers named in Trusted can sign in to an existing user
+	// with the same email, and never to an admin.
+
+	callbackURL := func(url string, provider string) string {
+		if url != "" {
+			return url
+		}
+		return fmt.Sprintf("%s/api/auth/%s/callback", cfg.OAuth.CallbackHost, provider)
+	}
+
+	var providers []oauth.ProviderConfig
+
+	if cfg.OAuth.GitHub.ClientID != "" {
+		providers = append(providers, oauth.ProviderConfig{
+			Name:         "github",
+			Kind:         oauth.KindGitHub,
+			ClientID:     cfg.OAuth.GitHub.ClientID,
+			ClientSecret: cfg.OAuth.GitHub.ClientSecret,
+			CallbackURL:  callbackURL(cfg.OAuth.GitHub.CallbackURL, "github"),
+		})
+	}
+
+	if cfg.OAuth.GitLab.ClientID != "" {
+		providers = append(providers, oauth.ProviderConfig{
+			Name:         "gitlab",
+			Kind:         oauth.KindGitLab,
+			ClientID:     cfg.OAuth.GitLab.ClientID,
+			ClientSecret: cfg.OAuth.GitLab.ClientSecret,
+			CallbackURL:  callbackURL(cfg.OAuth.GitLab.CallbackURL, "gitlab"),
+			BaseURL:      cfg.OAuth.GitLab.BaseURL,
+		})
+	}
+
+	if cfg.OAuth.Google.ClientID != "" {
+		providers = append(providers, oauth.ProviderConfig{
+			Name:         "google",
+			Kind:         oauth.KindOIDC,
+			ClientID:     cfg.OAuth.Google.ClientID,
+			ClientSecret: cfg.OAuth.Google.ClientSecret,
+			CallbackURL:  callbackURL(cfg.OAuth.Google.CallbackURL, "google"),
+			IssuerURL:    "https://accounts.google.com",
+		})
+	}
+
+	if cfg.OAuth.OIDC.ClientID != "" {
+		providers = append(providers, oauth.ProviderConfig{
+			Name:         cfg.OAuth.OIDC.Name,
+			Kind:         oauth.KindOIDC,
+			ClientID:     cfg.OAuth.OIDC.ClientID,
+			ClientSecret: cfg.OAuth.OIDC.ClientSecret,
+			CallbackURL:  callbackURL(cfg.OAuth.OIDC.CallbackURL, cfg.OAuth.OIDC.Name),
+			IssuerURL:    cfg.OAuth.OIDC.IssuerURL,
+			Scopes:       cfg.OAuth.OIDC.Scopes,
+		})
+	}
+
+	var oauthRegistry *oauth.Registry
+	if len(providers) > 0 {
+		oauthRegistry, err = oauth.New(ctx, oauth.Config{
+			Providers: providers,
+			StateKey:  []byte(cfg.OAuth.StateKey),
+			Secure:    cfg.OAuth.SecureCookie,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing oauth registry: %w", err)
+		}
+
+		log.Info(ctx, "startup", "status", "oauth providers enabled", "providers", oauthRegistry.Providers())
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize MFA Support
+
+	// The key is base64 encoded and must decode to 32 bytes for AES-256. Users
+	// can't enrol in multi-factor authentication when it isn't provided.
+	var mfaKey []byte
+	if cfg.MFA.Key != "" {
+		mfaKey, err = base64.StdEncoding.DecodeString(cfg.MFA.Key)
+		if err != nil {
+			return fmt.Errorf("decoding mfa key: %w", err)
+		}
+
+		if len(mfaKey) != 32 {
+			return fmt.Errorf("mfa key must be 32 bytes, got %d", len(mfaKey))
+		}
+	} else {
+		log.Info(ctx, "startup", "status", "mfa key not provided, mfa enrolment disabled")
+	}
+
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
 
@@ -223,19 +486,55 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	// The lockout and session records use the client address, which only
+	// comes from the X-Forwarded-For header behind one of these proxies. A
+	// proxy can be given as a single address or as a CIDR range.
+
+	trustedProxies := make([]netip.Prefix, len(cfg.Web.TrustedProxies))
+	for i, proxy := range cfg.Web.TrustedProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return fmt.Errorf("parsing trusted proxy %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		trustedProxies[i] = prefix
+	}
+
 	cfgMux := mux.Config{
 		Build:  build,
 		Log:    log,
 		DB:     db,
 		Tracer: tracer,
 		AuthConfig: mux.AuthConfig{
-			Auth: ath,
+			Auth:             ath,
+			AccessTokenTTL:   cfg.Auth.AccessTTL,
+			RefreshTokenTTL:  cfg.Auth.RefreshTTL,
+			ImpersonationTTL: cfg.Auth.ImpersonationTTL,
+			Mailer:           mlr,
+			AppURL:           cfg.Mail.AppURL,
+			OAuth:            oauthRegistry,
+			OAuthUIURL:       cfg.OAuth.UIURL,
+			OAuthTrusted:     cfg.OAuth.Trusted,
+			Lockout: lockoutbus.Config{
+				EmailThreshold: cfg.Lockout.EmailThreshold,
+				IPThreshold:    cfg.Lockout.IPThreshold,
+				BaseLockout:    cfg.Lockout.BaseLockout,
+				MaxLockout:     cfg.Lockout.MaxLockout,
+				ResetAfter:     cfg.Lockout.ResetAfter,
+			},
+			MFA: mfabus.Config{
+				Key:    mfaKey,
+				Issuer: cfg.MFA.Issuer,
+			},
 		},
 	}
 
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, all.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins)),
+		Handler:      mux.WebAPI(cfgMux, all.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins), mux.WithTrustedProxies(trustedProxies)),
 		ReadTimeout:  cfg.Web.ReadTimeout,
 		WriteTimeout: cfg.Web.WriteTimeout,
 		IdleTimeout:  cfg.Web.IdleTimeout,
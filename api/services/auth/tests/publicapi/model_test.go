@@ -0,0 +1,32 @@
+package public_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/mailer"
+)
+
+const (
+	registerEmail = "ada@ardanlabs.com"
+	unknownEmail  = "nobody@ardanlabs.com"
+	newPassword   = "gophers-rule"
+)
+
+var tokenRegEx = regexp.MustCompile(`token=(\S+)`)
+
+// mailToken returns the token carried by the last message sent to the
+// specified address.
+func mailToken(t *testing.T, mlr *mailer.Memory, to string) string {
+	msg, exists := mlr.Last(to)
+	if !exists {
+		t.Fatalf("Should have sent a message to %s", to)
+	}
+
+	m := tokenRegEx.FindStringSubmatch(msg.Body)
+	if m == nil {
+		t.Fatalf("Should have a token in the message to %s : %s", to, msg.Body)
+	}
+
+	return m[1]
+}
@@ -0,0 +1,107 @@
+package public_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/publicapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func forgotPassword204(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/password/forgot",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input: &publicapp.ForgotPasswordRequest{
+				Email: sd.Users[0].Email.Address,
+			},
+		},
+		{
+			Name:       "unknown-email",
+			URL:        "/v1/password/forgot",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input: &publicapp.ForgotPasswordRequest{
+				Email: unknownEmail,
+			},
+		},
+	}
+
+	return table
+}
+
+func forgotPassword400() []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/password/forgot",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &publicapp.ForgotPasswordRequest{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"email\",\"error\":\"email is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func resetPassword204(token string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/password/reset",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input: &publicapp.ResetPasswordRequest{
+				Token:           token,
+				Password:        newPassword,
+				PasswordConfirm: newPassword,
+			},
+		},
+	}
+
+	return table
+}
+
+func resetPassword400(usedToken string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/password/reset",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &publicapp.ResetPasswordRequest{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"token\",\"error\":\"token is a required field\"},{\"field\":\"password\",\"error\":\"password is a required field\"},{\"field\":\"passwordConfirm\",\"error\":\"passwordConfirm is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "used-token",
+			URL:        "/v1/password/reset",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &publicapp.ResetPasswordRequest{
+				Token:           usedToken,
+				Password:        newPassword,
+				PasswordConfirm: newPassword,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "invalid or expired token"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,110 @@
+package public_test
+
+import (
+	"context"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+)
+
+func Test_Public(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Public")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	ctx := context.Background()
+	userBus := test.DB.BusDomain.User
+
+	// -------------------------------------------------------------------------
+
+	test.RunAuth(t, register200(), "register-200")
+
+	token := mailToken(t, test.Mailer, registerEmail)
+
+	test.RunAuth(t, verifyEmail204(token), "verify-email-204")
+	test.RunAuth(t, verifyEmail400(token), "verify-email-400")
+
+	usr, err := userBus.QueryByEmail(ctx, mail.Address{Address: registerEmail})
+	if err != nil {
+		t.Fatalf("Should be able to query the registered user : %s", err)
+	}
+
+	if !usr.EmailVerified || !usr.Enabled {
+		t.Fatalf("Should have a verified and enabled user : verified[%t] enabled[%t]", usr.EmailVerified, usr.Enabled)
+	}
+
+	// -------------------------------------------------------------------------
+
+	disabled := sd.Users[1]
+
+	raw, _, err := test.DB.BusDomain.Verify.Create(ctx, disabled.ID, verifybus.PurposeEmailVerify, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to create a verification token : %s", err)
+	}
+
+	test.RunAuth(t, verifyEmail204(raw), "verify-email-disabled-204")
+
+	usr, err = userBus.QueryByID(ctx, disabled.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the disabled user : %s", err)
+	}
+
+	if !usr.EmailVerified || usr.Enabled {
+		t.Fatalf("Should have a verified user that is still disabled : verified[%t] enabled[%t]", usr.EmailVerified, usr.Enabled)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.RunAuth(t, forgotPassword204(sd), "forgot-password-204")
+	test.RunAuth(t, forgotPassword400(), "forgot-password-400")
+
+	if _, exists := test.Mailer.Last(unknownEmail); exists {
+		t.Fatalf("Should not send mail to an unknown address")
+	}
+
+	token = mailToken(t, test.Mailer, sd.Users[0].Email.Address)
+
+	sess, err := sessionbus.TestGenerateSeedSessions(ctx, 1, test.DB.BusDomain.Session, sd.Users[0].ID)
+	if err != nil {
+		t.Fatalf("Should be able to seed a session : %s", err)
+	}
+
+	emailKey := lockoutbus.EmailKey(sd.Users[0].Email)
+	for range lockoutbus.DefaultConfig.EmailThreshold {
+		if err := test.DB.BusDomain.Lockout.Fail(ctx, emailKey); err != nil {
+			t.Fatalf("Should be able to record a failure : %s", err)
+		}
+	}
+
+	test.RunAuth(t, resetPassword204(token), "reset-password-204")
+	test.RunAuth(t, resetPassword400(token), "reset-password-400")
+
+	if _, err := userBus.Authenticate(ctx, sd.Users[0].Email, newPassword); err != nil {
+		t.Fatalf("Should be able to authenticate with the new password : %s", err)
+	}
+
+	revoked, err := test.DB.BusDomain.Token.IsRevoked(ctx, sess[0].TokenID)
+	if err != nil {
+		t.Fatalf("Should be able to check the session token : %s", err)
+	}
+
+	if !revoked {
+		t.Fatalf("Should revoke the access token of every session")
+	}
+
+	if err := test.DB.BusDomain.Lockout.Check(ctx, emailKey); err != nil {
+		t.Fatalf("Should clear the lockout on the email : %s", err)
+	}
+}
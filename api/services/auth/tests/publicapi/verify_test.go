@@ -0,0 +1,116 @@
+package public_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/publicapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func register200() []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/register",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &publicapp.RegisterRequest{
+				Name:            "Ada Lovelace",
+				Email:           registerEmail,
+				Department:      "ITO",
+				Password:        "123456789",
+				PasswordConfirm: "123456789",
+			},
+			GotResp: &publicapp.User{},
+			ExpResp: &publicapp.User{
+				Name:          "Ada Lovelace",
+				Email:         registerEmail,
+				Roles:         []string{"USER"},
+				Department:    "ITO",
+				Enabled:       true,
+				EmailVerified: false,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*publicapp.User)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*publicapp.User)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func verifyEmail204(token string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/verify-email",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input: &publicapp.VerifyEmailRequest{
+				Token: token,
+			},
+		},
+	}
+
+	return table
+}
+
+func verifyEmail400(usedToken string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/verify-email",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &publicapp.VerifyEmailRequest{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"token\",\"error\":\"token is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "used-token",
+			URL:        "/v1/verify-email",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &publicapp.VerifyEmailRequest{
+				Token: usedToken,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "invalid or expired token"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unknown-token",
+			URL:        "/v1/verify-email",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &publicapp.VerifyEmailRequest{
+				Token: "not-a-token",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "invalid or expired token"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
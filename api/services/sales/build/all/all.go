@@ -4,24 +4,46 @@ package all
 import (
 	"time"
 
+	"github.com/ardanlabs/service/app/domain/apikeyapp"
+	"github.com/ardanlabs/service/app/domain/auditapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
+	"github.com/ardanlabs/service/app/domain/decisionapp"
 	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/domain/orgapp"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/domain/rawapp"
+	"github.com/ardanlabs/service/app/domain/roleapp"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/domain/vproductapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/ardanlabs/service/business/domain/decisionbus/stores/decisiondb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus/stores/lockoutdb"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/domain/orgbus/stores/orgdb"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus/stores/outboxdb"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/rolebus/stores/roledb"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus/stores/sessiondb"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
-	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -38,11 +60,33 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
-	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
-	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, productdb.NewStore(cfg.Log, cfg.DB))
-	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, homedb.NewStore(cfg.Log, cfg.DB))
+	delegate := cfg.Delegate
+	outboxBus := outboxbus.NewBusiness(cfg.Log, outboxdb.NewStore(cfg.Log, cfg.DB))
+	auditBus := auditbus.NewBusiness(cfg.Log, auditdb.NewStore(cfg.Log, cfg.DB))
+	userBus := userbus.NewBusiness(cfg.Log, delegate, outboxBus, auditBus, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
+	apiKeyBus := apikeybus.NewBusiness(cfg.Log, userBus, apikeydb.NewStore(cfg.Log, cfg.DB))
+	lockoutBus := lockoutbus.NewBusiness(cfg.Log, lockoutbus.DefaultConfig, lockoutdb.NewStore(cfg.Log, cfg.DB))
+	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, auditBus, productdb.NewStore(cfg.Log, cfg.DB))
+	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, auditBus, homedb.NewStore(cfg.Log, cfg.DB))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(cfg.Log, cfg.DB))
+	decisionBus := decisionbus.NewBusiness(cfg.Log, decisiondb.NewStore(cfg.Log, cfg.DB))
+	roleBus := rolebus.NewBusiness(cfg.Log, roledb.NewStore(cfg.Log, cfg.DB))
+	orgBus := orgbus.NewBusiness(cfg.Log, orgdb.NewStore(cfg.Log, cfg.DB))
+	tokenBus := tokenbus.NewBusiness(cfg.Log, tokendb.NewStore(cfg.Log, cfg.DB))
+	sessionBus := sessionbus.NewBusiness(cfg.Log, tokenBus, sessiondb.NewStore(cfg.Log, cfg.DB))
+
+	apikeyapp.Routes(app, apikeyapp.Config{
+		Log:        cfg.Log,
+		APIKeyBus:  apiKeyBus,
+		UserBus:    userBus,
+		AuthClient: cfg.AuthClient,
+	})
+
+	auditapp.Routes(app, auditapp.Config{
+		Log:        cfg.Log,
+		AuditBus:   auditBus,
+		AuthClient: cfg.AuthClient,
+	})
 
 	checkapp.Routes(app, checkapp.Config{
 		Build: cfg.Build,
@@ -50,20 +94,41 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		DB:    cfg.DB,
 	})
 
+	decisionapp.Routes(app, decisionapp.Config{
+		Log:         cfg.Log,
+		DecisionBus: decisionBus,
+		AuthClient:  cfg.AuthClient,
+	})
+
 	homeapp.Routes(app, homeapp.Config{
 		Log:        cfg.Log,
+		DB:         cfg.DB,
 		HomeBus:    homeBus,
 		AuthClient: cfg.AuthClient,
 	})
 
+	orgapp.Routes(app, orgapp.Config{
+		Log:        cfg.Log,
+		OrgBus:     orgBus,
+		AuthClient: cfg.AuthClient,
+	})
+
 	productapp.Routes(app, productapp.Config{
 		Log:        cfg.Log,
+		DB:         cfg.DB,
 		ProductBus: productBus,
 		AuthClient: cfg.AuthClient,
 	})
 
 	rawapp.Routes(app)
 
+	roleapp.Routes(app, roleapp.Config{
+		Log:        cfg.Log,
+		RoleBus:    roleBus,
+		UserBus:    userBus,
+		AuthClient: cfg.AuthClient,
+	})
+
 	tranapp.Routes(app, tranapp.Config{
 		Log:        cfg.Log,
 		DB:         cfg.DB,
@@ -74,7 +139,10 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	userapp.Routes(app, userapp.Config{
 		Log:        cfg.Log,
+		DB:         cfg.DB,
 		UserBus:    userBus,
+		LockoutBus: lockoutBus,
+		SessionBus: sessionBus,
 		AuthClient: cfg.AuthClient,
 	})
 
@@ -10,14 +10,17 @@ import (
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus/stores/outboxdb"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productdb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
-	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -34,10 +37,12 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
-	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
-	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, productdb.NewStore(cfg.Log, cfg.DB))
-	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, homedb.NewStore(cfg.Log, cfg.DB))
+	delegate := cfg.Delegate
+	outboxBus := outboxbus.NewBusiness(cfg.Log, outboxdb.NewStore(cfg.Log, cfg.DB))
+	auditBus := auditbus.NewBusiness(cfg.Log, auditdb.NewStore(cfg.Log, cfg.DB))
+	userBus := userbus.NewBusiness(cfg.Log, delegate, outboxBus, auditBus, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Minute))
+	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, auditBus, productdb.NewStore(cfg.Log, cfg.DB))
+	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, auditBus, homedb.NewStore(cfg.Log, cfg.DB))
 
 	checkapp.Routes(app, checkapp.Config{
 		Build: cfg.Build,
@@ -46,11 +51,15 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	})
 
 	homeapp.Routes(app, homeapp.Config{
+		Log:        cfg.Log,
+		DB:         cfg.DB,
 		HomeBus:    homeBus,
 		AuthClient: cfg.AuthClient,
 	})
 
 	productapp.Routes(app, productapp.Config{
+		Log:        cfg.Log,
+		DB:         cfg.DB,
 		ProductBus: productBus,
 		AuthClient: cfg.AuthClient,
 	})
@@ -64,6 +73,8 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	})
 
 	userapp.Routes(app, userapp.Config{
+		Log:        cfg.Log,
+		DB:         cfg.DB,
 		UserBus:    userBus,
 		AuthClient: cfg.AuthClient,
 	})
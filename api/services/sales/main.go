@@ -20,9 +20,13 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/debug"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus/stores/outboxdb"
+	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/worker"
 )
 
 /*
@@ -82,7 +86,12 @@ func run(ctx context.Context, log *logger.Logger) error {
 			CORSAllowedOrigins []string      `conf:"default:*"`
 		}
 		Auth struct {
-			Host string `conf:"default:http://auth-service:6000"`
+			Host         string        `conf:"default:http://auth-service:6000"`
+			Local        bool          `conf:"default:false"`
+			Issuer       string        `conf:"default:service project"`
+			JWKSRefresh  time.Duration `conf:"default:5m"`
+			PolicyPath   string
+			PolicyReload time.Duration `conf:"default:30s"`
 		}
 		DB struct {
 			User         string `conf:"default:postgres"`
@@ -93,6 +102,22 @@ func run(ctx context.Context, log *logger.Logger) error {
 			MaxOpenConns int    `conf:"default:0"`
 			DisableTLS   bool   `conf:"default:true"`
 		}
+		Delegate struct {
+			MaxRunning  int           `conf:"default:50"`
+			Timeout     time.Duration `conf:"default:10s"`
+			MaxAttempts int           `conf:"default:3"`
+			BaseBackoff time.Duration `conf:"default:500ms"`
+			MaxBackoff  time.Duration `conf:"default:10s"`
+			SlotWait    time.Duration `conf:"default:100ms"`
+		}
+		Outbox struct {
+			Interval    time.Duration `conf:"default:1s"`
+			BatchSize   int           `conf:"default:100"`
+			MaxAttempts int           `conf:"default:10"`
+			BaseBackoff time.Duration `conf:"default:1s"`
+			MaxBackoff  time.Duration `conf:"default:5m"`
+			Lease       time.Duration `conf:"default:1m"`
+		}
 		Tempo struct {
 			Host        string  `conf:"default:tempo:4317"`
 			ServiceName string  `conf:"default:sales"`
@@ -159,7 +184,103 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	log.Info(ctx, "startup", "status", "initializing authentication support")
 
-	authClient := authclient.New(log, cfg.Auth.Host)
+	var authOptions []func(cln *authclient.Client)
+	if cfg.Auth.Local {
+		lc := authclient.LocalConfig{
+			DB:          db,
+			Issuer:      cfg.Auth.Issuer,
+			PolicyPath:  cfg.Auth.PolicyPath,
+			JWKSRefresh: cfg.Auth.JWKSRefresh,
+		}
+		authOptions = append(authOptions, authclient.WithLocalVerification(lc))
+	}
+
+	authClient := authclient.New(log, cfg.Auth.Host, authOptions...)
+
+	// When the policies are evaluated locally and loaded from disk, they are
+	// checked for changes on the reload interval just as the auth service
+	// does. A bad policy is logged and the last good policy remains in use.
+
+	if cfg.Auth.Local && cfg.Auth.PolicyPath != "" && cfg.Auth.PolicyReload > 0 {
+		ctxReload, cancelReload := context.WithCancel(ctx)
+		defer cancelReload()
+
+		go func() {
+			ticker := time.NewTicker(cfg.Auth.PolicyReload)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					reloaded, err := authClient.ReloadPolicy(ctxReload)
+					if err != nil {
+						log.Error(ctxReload, "policy reload", "path", cfg.Auth.PolicyPath, "err", err)
+						continue
+					}
+					if reloaded {
+						log.Info(ctxReload, "policy reload", "status", "new policy active", "path", cfg.Auth.PolicyPath)
+					}
+
+				case <-ctxReload.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Outbox Relay
+
+	log.Info(ctx, "startup", "status", "initializing outbox relay")
+
+	// Functions registered to run asynchronously are executed as jobs on the
+	// worker, which bounds how many run at the same time. Events taken out of
+	// the outbox by the relay run them inline so failures are dead lettered.
+
+	wrk, err := worker.New(cfg.Delegate.MaxRunning)
+	if err != nil {
+		return fmt.Errorf("constructing worker: %w", err)
+	}
+
+	asyncCfg := delegate.AsyncConfig{
+		Timeout:     cfg.Delegate.Timeout,
+		MaxAttempts: cfg.Delegate.MaxAttempts,
+		BaseBackoff: cfg.Delegate.BaseBackoff,
+		MaxBackoff:  cfg.Delegate.MaxBackoff,
+		SlotWait:    cfg.Delegate.SlotWait,
+	}
+
+	// The delegate is shared with the routes so the functions they register
+	// receive the events the relay takes out of the outbox.
+
+	delegate := delegate.New(log, delegate.WithAsync(wrk, asyncCfg))
+
+	relayCfg := outboxbus.RelayConfig{
+		Interval:    cfg.Outbox.Interval,
+		BatchSize:   cfg.Outbox.BatchSize,
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		BaseBackoff: cfg.Outbox.BaseBackoff,
+		MaxBackoff:  cfg.Outbox.MaxBackoff,
+		Lease:       cfg.Outbox.Lease,
+	}
+
+	relay := outboxbus.NewRelay(log, relayCfg, outboxbus.NewBusiness(log, outboxdb.NewStore(log, db)), delegate)
+
+	// The relay is stopped and waited for before the database is closed, so
+	// an event isn't left half processed.
+
+	ctxRelay, cancelRelay := context.WithCancel(ctx)
+	relayDone := make(chan struct{})
+
+	go func() {
+		defer close(relayDone)
+		relay.Run(ctxRelay)
+	}()
+
+	defer func() {
+		cancelRelay()
+		<-relayDone
+	}()
 
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
@@ -209,6 +330,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 		Tracer: tracer,
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
+			Delegate:   delegate,
 		},
 	}
 
@@ -253,6 +375,13 @@ func run(ctx context.Context, log *logger.Logger) error {
 			api.Close()
 			return fmt.Errorf("could not stop server gracefully: %w", err)
 		}
+
+		cancelRelay()
+		<-relayDone
+
+		if err := wrk.Shutdown(ctx); err != nil {
+			return fmt.Errorf("could not stop delegate jobs gracefully: %w", err)
+		}
 	}
 
 	return nil
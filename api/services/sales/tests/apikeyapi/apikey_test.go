@@ -0,0 +1,34 @@
+package apikey_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_APIKey(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_APIKey")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create401(sd), "create-401")
+	test.Run(t, create400(sd), "create-400")
+
+	test.Run(t, use200(sd), "use-200")
+
+	test.Run(t, tenant200(sd), "tenant-200")
+	test.Run(t, tenant404(sd), "tenant-404")
+
+	test.Run(t, revoke200(sd), "revoke-200")
+	test.Run(t, revoke401(sd), "revoke-401")
+}
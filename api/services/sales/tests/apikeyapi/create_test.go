@@ -0,0 +1,118 @@
+package apikey_test
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/app/domain/apikeyapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func create200(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/apikeys",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &apikeyapp.NewAPIKey{
+				UserID: sd.Users[0].ID.String(),
+				Name:   "billing",
+				Roles:  []string{"USER"},
+			},
+			GotResp: &apikeyapp.IssuedAPIKey{},
+			ExpResp: &apikeyapp.IssuedAPIKey{
+				APIKey: apikeyapp.APIKey{
+					UserID: sd.Users[0].ID.String(),
+					Name:   "billing",
+					Roles:  []string{"USER"},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*apikeyapp.IssuedAPIKey)
+				if !exists {
+					return "error occurred"
+				}
+
+				if !strings.HasPrefix(gotResp.Key, "sk_"+gotResp.APIKey.Prefix+"_") {
+					return "key does not carry its prefix"
+				}
+
+				expResp := exp.(*apikeyapp.IssuedAPIKey)
+
+				expResp.Key = gotResp.Key
+				expResp.APIKey.ID = gotResp.APIKey.ID
+				expResp.APIKey.Prefix = gotResp.APIKey.Prefix
+				expResp.APIKey.DateCreated = gotResp.APIKey.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/apikeys",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &apikeyapp.NewAPIKey{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"userID\",\"error\":\"userID is a required field\"},{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"roles\",\"error\":\"roles is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "escalate",
+			URL:        "/v1/apikeys",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &apikeyapp.NewAPIKey{
+				UserID: sd.Users[0].ID.String(),
+				Name:   "escalate",
+				Roles:  []string{"ADMIN"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.FailedPrecondition, "api key roles must be a subset of the user's roles"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "asuser",
+			URL:        "/v1/apikeys",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &apikeyapp.NewAPIKey{
+				UserID: sd.Users[0].ID.String(),
+				Name:   "billing",
+				Roles:  []string{"USER"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
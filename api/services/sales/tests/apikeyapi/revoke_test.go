@@ -0,0 +1,44 @@
+package apikey_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func revoke200(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "asadmin",
+			URL:        fmt.Sprintf("/v1/apikeys/%s", sd.keyID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
+
+func revoke401(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "revoked",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      "ApiKey " + sd.key,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.New(errs.Unauthenticated, apikeybus.ErrRevoked),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
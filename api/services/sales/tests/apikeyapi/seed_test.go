@@ -0,0 +1,92 @@
+package apikey_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+// seedData extends the api test seed data with the api key issued to the
+// first user.
+type seedData struct {
+	apitest.SeedData
+	key   string
+	keyID string
+}
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (seedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	raw, key, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+		UserID: usrs[0].ID,
+		Name:   "seed",
+		Roles:  []role.Role{role.User},
+	})
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding api keys : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	org, err := busDomain.Org.Create(ctx, orgbus.NewOrg{Name: name.MustParse("Acme")})
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding org : %w", err)
+	}
+
+	nu := userbus.TestNewUsers(1, role.Admin)[0]
+	nu.OrgID = org.ID
+
+	usr, err := busDomain.User.Create(ctx, nu)
+	if err != nil {
+		return seedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu3 := apitest.User{
+		User:  usr,
+		Token: apitest.Token(db.BusDomain.User, ath, usr.Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := seedData{
+		SeedData: apitest.SeedData{
+			Users:  []apitest.User{tu1},
+			Admins: []apitest.User{tu2, tu3},
+		},
+		key:   raw,
+		keyID: key.ID.String(),
+	}
+
+	return sd, nil
+}
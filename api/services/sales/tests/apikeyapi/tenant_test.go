@@ -0,0 +1,87 @@
+package apikey_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/apikeyapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The second admin belongs to another tenant and must not be able to see or
+// manage the keys of the default tenant.
+
+func tenant200(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "query-other-org",
+			URL:        "/v1/apikeys?page=1&rows=10",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[apikeyapp.APIKey]{},
+			ExpResp: &query.Result[apikeyapp.APIKey]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       0,
+				Items:       []apikeyapp.APIKey{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func tenant404(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "querybyid-other-org",
+			URL:        fmt.Sprintf("/v1/apikeys/%s", sd.keyID),
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "api key not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "revoke-other-org",
+			URL:        fmt.Sprintf("/v1/apikeys/%s", sd.keyID),
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "api key not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "create-other-org",
+			URL:        "/v1/apikeys",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNotFound,
+			Input: &apikeyapp.NewAPIKey{
+				UserID: sd.Users[0].ID.String(),
+				Name:   "escalate",
+				Roles:  []string{"USER"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.NotFound, "user not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,36 @@
+package apikey_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func use200(sd seedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "querybyid",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      "ApiKey " + sd.key,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &userapp.User{},
+			ExpResp:    &userapp.User{ID: sd.Users[0].ID.String()},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*userapp.User)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*userapp.User)
+
+				return cmp.Diff(gotResp.ID, expResp.ID)
+			},
+		},
+	}
+
+	return table
+}
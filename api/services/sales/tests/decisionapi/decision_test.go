@@ -0,0 +1,27 @@
+package decision_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_Decision(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Decision")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The denied request made here is what query-200 looks for.
+	test.Run(t, query401(sd), "query-401")
+	test.Run(t, query200(sd), "query-200")
+	test.Run(t, query400(sd), "query-400")
+}
@@ -0,0 +1,104 @@
+package decision_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/decisionapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "denied",
+			URL:        fmt.Sprintf("/v1/decisions?user_id=%s&rule=%s&allowed=false", sd.Users[0].ID, auth.RuleAdminOnly),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[decisionapp.Decision]{},
+			ExpResp:    true,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[decisionapp.Decision])
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.Total != 1 || len(gotResp.Items) != 1 {
+					return fmt.Sprintf("expected a single decision, got %d", gotResp.Total)
+				}
+
+				dec := gotResp.Items[0]
+				match := dec.Subject == sd.Users[0].ID.String() &&
+					dec.Rule == auth.RuleAdminOnly &&
+					!dec.Allowed &&
+					dec.Reason != ""
+
+				return cmp.Diff(match, exp)
+			},
+		},
+		{
+			// The denied request belongs to the default tenant so an admin
+			// in another tenant can't see it.
+			Name:       "other-org",
+			URL:        fmt.Sprintf("/v1/decisions?user_id=%s", sd.Users[0].ID),
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[decisionapp.Decision]{},
+			ExpResp: &query.Result[decisionapp.Decision]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       0,
+				Items:       []decisionapp.Decision{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-query-filter",
+			URL:        "/v1/decisions?user_id=abc",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"user_id\",\"error\":\"invalid UUID length: 3\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        "/v1/decisions",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
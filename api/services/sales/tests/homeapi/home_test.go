@@ -31,7 +31,19 @@ func Test_Home(t *testing.T) {
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update412(sd), "update-412")
+
+	test.Run(t, patch200(sd), "patch-200")
+	test.Run(t, patch400(sd), "patch-400")
+	test.Run(t, patch409(sd), "patch-409")
+	test.Run(t, patch412(sd), "patch-412")
+	test.Run(t, patch413(sd), "patch-413")
+	test.Run(t, patch415(sd), "patch-415")
 
 	test.Run(t, delete200(sd), "delete-200")
 	test.Run(t, delete401(sd), "delete-401")
+
+	test.Run(t, restore200(sd), "restore-200")
+	test.Run(t, restore401(sd), "restore-401")
+	test.Run(t, restore404(sd), "restore-404")
 }
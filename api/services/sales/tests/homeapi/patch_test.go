@@ -0,0 +1,251 @@
+package home_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
+	"github.com/ardanlabs/service/app/sdk/patch"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The patch tests use the second home so they don't interfere with the
+// versions the update and delete tests expect. Every successful patch moves
+// the home to the next version.
+
+func patch200(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	merged := toAppHomePtr(hme)
+	merged.Type = "CONDO"
+	merged.Address.Address2 = ""
+
+	patched := toAppHomePtr(hme)
+	patched.Type = "CONDO"
+	patched.Address.Address2 = ""
+	patched.Address.City = "Huntsville"
+
+	table := []apitest.Table{
+		{
+			Name:   "merge",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(hme.Version),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`{"type":"CONDO","address":{"address2":null}}`),
+			GotResp:    &homeapp.Home{},
+			ExpResp:    merged,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*homeapp.Home)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*homeapp.Home)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:   "json",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(hme.Version + 1),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`[{"op":"test","path":"/type","value":"CONDO"},{"op":"replace","path":"/address/city","value":"Huntsville"}]`),
+			GotResp:    &homeapp.Home{},
+			ExpResp:    patched,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*homeapp.Home)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*homeapp.Home)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch400(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "required",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`[{"op":"remove","path":"/address/address1"}]`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"address1\",\"error\":\"address1 is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "unknown-field",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`{"color":"red"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"color\",\"error\":\"unknown field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "bad-type",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`{"type":"BAD TYPE"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "parse: invalid home type \"BAD TYPE\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch409(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "test-failed",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusConflict,
+			Input:      json.RawMessage(`[{"op":"test","path":"/type","value":"SINGLE FAMILY"},{"op":"remove","path":"/address/address2"}]`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Aborted, "operation[0]: test: test operation failed: path \"/type\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch412(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "stale",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(hme.Version),
+			},
+			StatusCode: http.StatusPreconditionFailed,
+			Input:      json.RawMessage(`{"type":"SINGLE FAMILY"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PreconditionFailed, "resource has changed"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch413(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "too-large",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Input:      json.RawMessage(fmt.Sprintf(`{"address1":%q}`, strings.Repeat("a", patch.MaxSize))),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PayloadTooLarge, "patch is too large: limit %d bytes", patch.MaxSize),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch415(sd apitest.SeedData) []apitest.Table {
+	hme := sd.Users[0].Homes[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "media-type",
+			URL:    fmt.Sprintf("/v1/homes/%s", hme.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+				"If-Match":     etag.Format(hme.Version + 2),
+			},
+			StatusCode: http.StatusUnsupportedMediaType,
+			Input:      json.RawMessage(`{"type":"CONDO"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.UnsupportedMediaType, "unsupported patch media type: \"application/json\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
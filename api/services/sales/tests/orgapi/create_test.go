@@ -0,0 +1,82 @@
+package org_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/orgapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/orgs",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &orgapp.NewOrg{
+				Name: "Globex",
+			},
+			GotResp: &orgapp.Org{},
+			ExpResp: &orgapp.Org{
+				Name: "Globex",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*orgapp.Org)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*orgapp.Org)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "wronguser",
+			URL:        "/v1/orgs",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &orgapp.NewOrg{
+				Name: "Initech",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "tenant-admin",
+			URL:        "/v1/orgs",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &orgapp.NewOrg{
+				Name: "Initech",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[ADMIN]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
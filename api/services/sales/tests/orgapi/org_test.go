@@ -0,0 +1,28 @@
+package org_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_Org(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Org")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create401(sd), "create-401")
+
+	test.Run(t, tenant200(sd), "tenant-200")
+	test.Run(t, tenant401(sd), "tenant-401")
+}
@@ -0,0 +1,98 @@
+package org_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func tenant200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "default-org",
+			URL:        "/v1/products?page=1&rows=10",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items:       []productapp.Product{toAppProduct(sd.Users[0].Products[0])},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "other-org",
+			URL:        "/v1/products?page=1&rows=10",
+			Token:      sd.Users[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items:       []productapp.Product{toAppProduct(sd.Users[1].Products[0])},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func tenant401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "product-other-org",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[1].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "product not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "user-other-org",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      sd.Users[1].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "user not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func toAppProduct(prd productbus.Product) productapp.Product {
+	return productapp.Product{
+		ID:          prd.ID.String(),
+		UserID:      prd.UserID.String(),
+		Name:        prd.Name.String(),
+		Cost:        prd.Cost.Value(),
+		Quantity:    prd.Quantity.Value(),
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,88 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/auditapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func audit200(sd apitest.SeedData) []apitest.Table {
+	type entry struct {
+		Action  string
+		ActorID string
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "history",
+			URL:        fmt.Sprintf("/v1/audit?entity=product&id=%s&orderBy=action,ASC", sd.Admins[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[auditapp.Audit]{},
+			ExpResp: []entry{
+				{Action: "created"},
+				{Action: "deleted", ActorID: sd.Admins[0].ID.String()},
+				{Action: "restored", ActorID: sd.Admins[0].ID.String()},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[auditapp.Audit])
+				if !exists {
+					return "error occurred"
+				}
+
+				entries := make([]entry, len(gotResp.Items))
+				for i, aud := range gotResp.Items {
+					entries[i] = entry{Action: aud.Action, ActorID: aud.ActorID}
+				}
+
+				return cmp.Diff(entries, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func audit400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-entity",
+			URL:        "/v1/audit?entity=car",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"entity\",\"error\":\"unknown entity \\\"car\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func audit401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "asuser",
+			URL:        fmt.Sprintf("/v1/audit?entity=product&id=%s", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
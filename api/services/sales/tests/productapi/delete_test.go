@@ -6,16 +6,19 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/google/go-cmp/cmp"
 )
 
 func delete200(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
+			// The update tests moved the product to its next version.
 			Name:       "asuser",
 			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
 			Token:      sd.Users[0].Token,
 			Method:     http.MethodDelete,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Users[0].Products[0].Version + 1)},
 			StatusCode: http.StatusNoContent,
 		},
 		{
@@ -23,6 +26,7 @@ func delete200(sd apitest.SeedData) []apitest.Table {
 			URL:        fmt.Sprintf("/v1/products/%s", sd.Admins[0].Products[0].ID),
 			Token:      sd.Admins[0].Token,
 			Method:     http.MethodDelete,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Admins[0].Products[0].Version)},
 			StatusCode: http.StatusNoContent,
 		},
 	}
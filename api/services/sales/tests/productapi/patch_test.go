@@ -0,0 +1,143 @@
+package product_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
+	"github.com/ardanlabs/service/app/sdk/patch"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The patch tests use the second product so they don't interfere with the
+// versions the update and delete tests expect.
+
+func patch200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	merged := toAppProductPtr(prd)
+	merged.Cost = 12.5
+
+	patched := toAppProductPtr(prd)
+	patched.Cost = 12.5
+	patched.Quantity = 7
+
+	table := []apitest.Table{
+		{
+			Name:   "merge",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(prd.Version),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`{"cost":12.5}`),
+			GotResp:    &productapp.Product{},
+			ExpResp:    merged,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:   "json",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(prd.Version + 1),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`[{"op":"replace","path":"/quantity","value":7}]`),
+			GotResp:    &productapp.Product{},
+			ExpResp:    patched,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch400(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:   "bad-input",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(prd.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`{"name":null,"cost":-1}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"cost\",\"error\":\"cost must be 0 or greater\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "bad-type",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(prd.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`[{"op":"replace","path":"/quantity","value":"lots"}]`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"quantity\",\"error\":\"must be of type int\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "bad-path",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Users[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(prd.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`[{"op":"remove","path":"/color"}]`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "operation[0]: remove: member \"color\" not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -31,7 +31,19 @@ func Test_Product(t *testing.T) {
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update412(sd), "update-412")
+
+	test.Run(t, patch200(sd), "patch-200")
+	test.Run(t, patch400(sd), "patch-400")
 
 	test.Run(t, delete200(sd), "delete-200")
 	test.Run(t, delete401(sd), "delete-401")
+
+	test.Run(t, restore200(sd), "restore-200")
+	test.Run(t, restore401(sd), "restore-401")
+	test.Run(t, restore404(sd), "restore-404")
+
+	test.Run(t, audit200(sd), "audit-200")
+	test.Run(t, audit400(sd), "audit-400")
+	test.Run(t, audit401(sd), "audit-401")
 }
@@ -0,0 +1,157 @@
+package role_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/roleapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func assign200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &roleapp.Assignment{
+				Role: "SUPPORT",
+			},
+			GotResp: &roleapp.Role{},
+			ExpResp: "SUPPORT",
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				return cmp.Diff(gotResp.Name, exp)
+			},
+		},
+		{
+			Name:       "list",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &roleapp.Roles{},
+			ExpResp:    []string{"SUPPORT"},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Roles)
+				if !exists {
+					return "error occurred"
+				}
+
+				var names []string
+				for _, rle := range *gotResp {
+					names = append(names, rle.Name)
+				}
+
+				return cmp.Diff(names, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func assign400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "builtin",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &roleapp.Assignment{
+				Role: "ADMIN",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.FailedPrecondition, "built in roles can't be deleted or assigned"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func assign403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "escalation",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Admins[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusForbidden,
+			Input: &roleapp.Assignment{
+				Role: "PLATFORM",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PermissionDenied, "role \"PLATFORM\" grants permissions the caller doesn't hold"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func assign404(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNotFound,
+			Input: &roleapp.Assignment{
+				Role: "SUPPORT",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.NotFound, "user not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "other-tenant-list",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "user not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func delete400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "builtin",
+			URL:        "/v1/roles/USER",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.FailedPrecondition, "built in roles can't be deleted or assigned"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
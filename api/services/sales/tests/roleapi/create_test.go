@@ -0,0 +1,126 @@
+package role_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/roleapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &roleapp.NewRole{
+				Name:        "SUPPORT",
+				Description: "Support staff",
+				Permissions: []string{"user:read", "home:read"},
+			},
+			GotResp: &roleapp.Role{},
+			ExpResp: &roleapp.Role{
+				Name:        "SUPPORT",
+				Description: "Support staff",
+				Permissions: []string{"user:read", "home:read"},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*roleapp.Role)
+
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &roleapp.NewRole{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"description\",\"error\":\"description is a required field\"},{\"field\":\"permissions\",\"error\":\"permissions is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-permission",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &roleapp.NewRole{
+				Name:        "SUPPORT",
+				Description: "Support staff",
+				Permissions: []string{"user:fly"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid permission \"user:fly\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "wronguser",
+			URL:        "/v1/roles",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &roleapp.NewRole{
+				Name:        "SUPPORT",
+				Description: "Support staff",
+				Permissions: []string{"user:read"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "tenant-admin",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &roleapp.NewRole{
+				Name:        "SUPPORT",
+				Description: "Support staff",
+				Permissions: []string{"user:read"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[ADMIN]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,33 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_Role(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Role")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create401(sd), "create-401")
+	test.Run(t, create400(sd), "create-400")
+
+	test.Run(t, assign200(sd), "assign-200")
+	test.Run(t, assign400(sd), "assign-400")
+	test.Run(t, assign403(sd), "assign-403")
+	test.Run(t, assign404(sd), "assign-404")
+
+	test.Run(t, delete400(sd), "delete-400")
+}
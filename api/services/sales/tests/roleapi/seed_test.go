@@ -0,0 +1,91 @@
+package role_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	rle, err := busDomain.Role.QueryByName(ctx, "PLATFORM")
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("querying platform role : %w", err)
+	}
+
+	if err := busDomain.Role.Assign(ctx, usrs[0].ID, rle); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("assigning platform role : %w", err)
+	}
+
+	tu3 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	org, err := busDomain.Org.Create(ctx, orgbus.NewOrg{Name: name.MustParse("Acme")})
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding org : %w", err)
+	}
+
+	nu := userbus.TestNewUsers(1, role.User)[0]
+	nu.OrgID = org.ID
+
+	usr, err := busDomain.User.Create(ctx, nu)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu4 := apitest.User{
+		User:  usr,
+		Token: apitest.Token(db.BusDomain.User, ath, usr.Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Users:  []apitest.User{tu1, tu4},
+		Admins: []apitest.User{tu2, tu3},
+	}
+
+	return sd, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -16,6 +17,7 @@ func delete200(sd apitest.SeedData) []apitest.Table {
 			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[1].ID),
 			Token:      sd.Users[1].Token,
 			Method:     http.MethodDelete,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Users[1].Version)},
 			StatusCode: http.StatusNoContent,
 		},
 		{
@@ -23,6 +25,7 @@ func delete200(sd apitest.SeedData) []apitest.Table {
 			URL:        fmt.Sprintf("/v1/users/%s", sd.Admins[1].ID),
 			Token:      sd.Admins[1].Token,
 			Method:     http.MethodDelete,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Admins[1].Version)},
 			StatusCode: http.StatusNoContent,
 		},
 	}
@@ -0,0 +1,66 @@
+package user_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func impersonate200(sd apitest.SeedData, token string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "read",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[1].ID),
+			Token:      token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &userapp.User{},
+			ExpResp:    toAppUserPtr(sd.Users[1].User),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func impersonate401(sd apitest.SeedData, token string) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "password",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[1].ID),
+			Token:      token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusUnauthorized,
+			Input: &userapp.UpdateUser{
+				Password:        dbtest.StringPointer("123"),
+				PasswordConfirm: dbtest.StringPointer("123"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: impersonation not allowed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "delete",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[1].ID),
+			Token:      token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: impersonation not allowed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
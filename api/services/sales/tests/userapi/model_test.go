@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/types/role"
 )
@@ -35,3 +36,25 @@ func toAppUserPtr(bus userbus.User) *userapp.User {
 	appUsr := toAppUser(bus)
 	return &appUsr
 }
+
+func toAppSession(bus sessionbus.Session) userapp.Session {
+	return userapp.Session{
+		ID:           bus.ID.String(),
+		UserID:       bus.UserID.String(),
+		Device:       bus.Client.Device,
+		IPAddress:    bus.Client.IPAddress,
+		UserAgent:    bus.Client.UserAgent,
+		DateCreated:  bus.DateCreated.Format(time.RFC3339),
+		DateLastSeen: bus.DateLastSeen.Format(time.RFC3339),
+		DateExpires:  bus.DateExpires.Format(time.RFC3339),
+	}
+}
+
+func toAppSessions(sess []sessionbus.Session) []userapp.Session {
+	items := make([]userapp.Session, len(sess))
+	for i, ses := range sess {
+		items[i] = toAppSession(ses)
+	}
+
+	return items
+}
@@ -0,0 +1,127 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
+	"github.com/ardanlabs/service/app/sdk/patch"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The patch tests use the third user so they don't interfere with the
+// versions the update and delete tests expect.
+
+func patch200(sd apitest.SeedData) []apitest.Table {
+	usr := sd.Users[2]
+
+	merged := toAppUserPtr(usr.User)
+	merged.Name = "Ada Lovelace"
+	merged.Department = "NULL"
+
+	patched := toAppUserPtr(usr.User)
+	patched.Name = "Ada Lovelace"
+	patched.Department = "ITO"
+
+	table := []apitest.Table{
+		{
+			Name:   "merge",
+			URL:    fmt.Sprintf("/v1/users/%s", usr.ID),
+			Token:  usr.Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(usr.Version),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`{"name":"Ada Lovelace","department":null}`),
+			GotResp:    &userapp.User{},
+			ExpResp:    merged,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*userapp.User)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*userapp.User)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:   "json",
+			URL:    fmt.Sprintf("/v1/users/%s", usr.ID),
+			Token:  usr.Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.JSONPatchType,
+				"If-Match":     etag.Format(usr.Version + 1),
+			},
+			StatusCode: http.StatusOK,
+			Input:      json.RawMessage(`[{"op":"add","path":"/department","value":"ITO"}]`),
+			GotResp:    &userapp.User{},
+			ExpResp:    patched,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*userapp.User)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*userapp.User)
+				gotResp.DateUpdated = expResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch400(sd apitest.SeedData) []apitest.Table {
+	usr := sd.Users[2]
+
+	table := []apitest.Table{
+		{
+			Name:   "bad-input",
+			URL:    fmt.Sprintf("/v1/users/%s", usr.ID),
+			Token:  usr.Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(usr.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`{"email":"not-an-email"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"email\",\"error\":\"email must be a valid email address\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "password",
+			URL:    fmt.Sprintf("/v1/users/%s", usr.ID),
+			Token:  usr.Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": patch.MergePatchType,
+				"If-Match":     etag.Format(usr.Version + 2),
+			},
+			StatusCode: http.StatusBadRequest,
+			Input:      json.RawMessage(`{"password":"123"}`),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"password\",\"error\":\"unknown field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/types/role"
@@ -37,9 +38,15 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
 	}
 
+	sess, err := sessionbus.TestGenerateSeedSessions(ctx, 3, busDomain.Session, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding sessions : %w", err)
+	}
+
 	tu3 := apitest.User{
-		User:  usrs[0],
-		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+		User:     usrs[0],
+		Sessions: sess,
+		Token:    apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
 	}
 
 	tu4 := apitest.User{
@@ -0,0 +1,148 @@
+package user_test
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func sessions200(sd apitest.SeedData) []apitest.Table {
+	usr := sd.Users[0]
+
+	sess := make([]sessionbus.Session, len(usr.Sessions))
+	copy(sess, usr.Sessions)
+
+	sort.Slice(sess, func(i, j int) bool {
+		return sess[i].ID.String() <= sess[j].ID.String()
+	})
+
+	url := fmt.Sprintf("/v1/users/%s/sessions", usr.ID)
+	queryURL := url + "?page=1&rows=10&orderBy=session_id,ASC"
+
+	table := []apitest.Table{
+		{
+			Name:       "query",
+			URL:        queryURL,
+			Token:      usr.Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.Session]{},
+			ExpResp: &query.Result[userapp.Session]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(sess),
+				Items:       toAppSessions(sess),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "revoke",
+			URL:        fmt.Sprintf("%s/%s", url, sess[0].ID),
+			Token:      usr.Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+		{
+			Name:       "query-after-revoke",
+			URL:        queryURL,
+			Token:      usr.Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.Session]{},
+			ExpResp: &query.Result[userapp.Session]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(sess) - 1,
+				Items:       toAppSessions(sess[1:]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "signout-everywhere-asadmin",
+			URL:        url,
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+		{
+			Name:       "query-after-signout",
+			URL:        queryURL,
+			Token:      usr.Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.Session]{},
+			ExpResp: &query.Result[userapp.Session]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       0,
+				Items:       []userapp.Session{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func sessions401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "query-wronguser",
+			URL:        fmt.Sprintf("/v1/users/%s/sessions", sd.Users[0].ID),
+			Token:      sd.Users[2].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "signout-wronguser",
+			URL:        fmt.Sprintf("/v1/users/%s/sessions", sd.Users[0].ID),
+			Token:      sd.Users[2].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func sessions404(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "other-users-session",
+			URL:        fmt.Sprintf("/v1/users/%s/sessions/%s", sd.Users[2].ID, sd.Users[0].Sessions[1].ID),
+			Token:      sd.Users[2].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.New(errs.NotFound, sessionbus.ErrNotFound),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
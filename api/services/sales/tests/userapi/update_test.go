@@ -8,6 +8,7 @@ import (
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/google/go-cmp/cmp"
 )
@@ -19,6 +20,7 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
 			Token:      sd.Users[0].Token,
 			Method:     http.MethodPut,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Users[0].Version)},
 			StatusCode: http.StatusOK,
 			Input: &userapp.UpdateUser{
 				Name:            dbtest.StringPointer("Jack Kennedy"),
@@ -55,6 +57,7 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			URL:        fmt.Sprintf("/v1/users/role/%s", sd.Admins[0].ID),
 			Token:      sd.Admins[0].Token,
 			Method:     http.MethodPut,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Admins[0].Version)},
 			StatusCode: http.StatusOK,
 			Input: &userapp.UpdateUserRole{
 				Roles: []string{"USER"},
@@ -189,3 +192,42 @@ func update401(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func update412(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusPreconditionFailed,
+			Input: &userapp.UpdateUser{
+				Name: dbtest.StringPointer("Jill Kennedy"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PreconditionFailed, "if-match header is required"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			// The update tests moved the user past its seeded version.
+			Name:       "stale",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			Headers:    map[string]string{"If-Match": etag.Format(sd.Users[0].Version)},
+			StatusCode: http.StatusPreconditionFailed,
+			Input: &userapp.UpdateUser{
+				Name: dbtest.StringPointer("Jill Kennedy"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PreconditionFailed, "resource has changed"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
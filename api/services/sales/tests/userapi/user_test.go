@@ -1,9 +1,12 @@
 package user_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/types/role"
 )
 
 func Test_User(t *testing.T) {
@@ -20,6 +23,11 @@ func Test_User(t *testing.T) {
 
 	// -------------------------------------------------------------------------
 
+	impToken := apitest.ImpersonationToken(test.DB.BusDomain.User, test.Auth, sd.Admins[0].ID, sd.Users[1].Email.Address)
+
+	test.Run(t, impersonate200(sd, impToken), "impersonate-200")
+	test.Run(t, impersonate401(sd, impToken), "impersonate-401")
+
 	test.Run(t, query200(sd), "query-200")
 	test.Run(t, query400(sd), "query-400")
 	test.Run(t, queryByID200(sd), "querybyid-200")
@@ -31,7 +39,31 @@ func Test_User(t *testing.T) {
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update412(sd), "update-412")
+
+	test.Run(t, patch200(sd), "patch-200")
+	test.Run(t, patch400(sd), "patch-400")
+
+	test.Run(t, sessions200(sd), "sessions-200")
+	test.Run(t, sessions401(sd), "sessions-401")
+	test.Run(t, sessions404(sd), "sessions-404")
 
 	test.Run(t, delete200(sd), "delete-200")
 	test.Run(t, delete401(sd), "delete-401")
+
+	test.Run(t, restore200(sd), "restore-200")
+	test.Run(t, restore401(sd), "restore-401")
+	test.Run(t, restore404(sd), "restore-404")
+
+	// The email of a deleted user can be taken by a new user, after which
+	// the deleted user can't be restored.
+
+	nu := userbus.TestNewUsers(1, role.User)[0]
+	nu.Email = sd.Users[1].Email
+
+	if _, err := test.DB.BusDomain.User.Create(context.Background(), nu); err != nil {
+		t.Fatalf("Should be able to reuse the email of a deleted user : %s", err)
+	}
+
+	test.Run(t, restore409(sd), "restore-409")
 }
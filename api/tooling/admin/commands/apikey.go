@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// APIKeyCreate issues a new api key for the specified user. The key is only
+// displayed once.
+func APIKeyCreate(log *logger.Logger, cfg sqldb.Config, userID string, nme string, roles string, ttl string) error {
+	if userID == "" || nme == "" || roles == "" {
+		fmt.Println("help: apikey create <user_id> <name> <roles,...> [ttl]")
+		return ErrHelp
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("parsing user id: %w", err)
+	}
+
+	rls, err := role.ParseMany(strings.Split(roles, ","))
+	if err != nil {
+		return fmt.Errorf("parsing roles: %w", err)
+	}
+
+	var expires time.Time
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("parsing ttl: %w", err)
+		}
+		expires = time.Now().Add(d)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userBus := userbus.NewBusiness(log, nil, nil, nil, userdb.NewStore(log, db))
+	apiKeyBus := apikeybus.NewBusiness(log, userBus, apikeydb.NewStore(log, db))
+
+	nak := apikeybus.NewAPIKey{
+		UserID:      uid,
+		Name:        nme,
+		Roles:       rls,
+		DateExpires: expires,
+	}
+
+	raw, key, err := apiKeyBus.Create(ctx, nak)
+	if err != nil {
+		return fmt.Errorf("create api key: %w", err)
+	}
+
+	fmt.Println("api key id:", key.ID)
+	fmt.Println("api key   :", raw)
+	fmt.Println("store the key now, it can't be displayed again")
+	return nil
+}
+
+// APIKeyRevoke revokes the specified api key.
+func APIKeyRevoke(log *logger.Logger, cfg sqldb.Config, keyID string) error {
+	if keyID == "" {
+		fmt.Println("help: apikey revoke <apikey_id>")
+		return ErrHelp
+	}
+
+	kid, err := uuid.Parse(keyID)
+	if err != nil {
+		return fmt.Errorf("parsing api key id: %w", err)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userBus := userbus.NewBusiness(log, nil, nil, nil, userdb.NewStore(log, db))
+	apiKeyBus := apikeybus.NewBusiness(log, userBus, apikeydb.NewStore(log, db))
+
+	key, err := apiKeyBus.QueryByID(ctx, kid)
+	if err != nil {
+		return fmt.Errorf("query api key: %w", err)
+	}
+
+	if _, err := apiKeyBus.Revoke(ctx, key); err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	fmt.Println("api key revoked:", key.ID)
+	return nil
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+
+	// The domains are imported so the events they define are registered.
+	_ "github.com/ardanlabs/service/business/domain/homebus"
+	_ "github.com/ardanlabs/service/business/domain/productbus"
+	_ "github.com/ardanlabs/service/business/domain/userbus"
+)
+
+// Events lists the events defined by the domains with their current params
+// version and type.
+func Events() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "DOMAIN\tACTION\tVERSION\tPARAMS\tUPCASTS FROM")
+	for _, ei := range delegate.Events() {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%v\n", ei.Domain, ei.Action, ei.Version, ei.Params, ei.UpcastsFrom)
+	}
+
+	return w.Flush()
+}
@@ -1,21 +1,58 @@
 package commands
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"os"
+	"strings"
 )
 
-// GenKey creates an x509 private/public key for auth tokens.
-func GenKey() error {
+// GenKey creates an x509 private/public key for auth tokens. The algorithm
+// can be rs256 (the default), es256 or eddsa.
+func GenKey(alg string) error {
 
-	// Generate a new private key.
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return fmt.Errorf("generating key: %w", err)
+	// Generate a new private key and marshal it for the specified algorithm.
+	var public crypto.PublicKey
+	var privateBytes []byte
+
+	switch strings.ToLower(alg) {
+	case "", "rs256":
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return fmt.Errorf("generating key: %w", err)
+		}
+		public = &privateKey.PublicKey
+		privateBytes = x509.MarshalPKCS1PrivateKey(privateKey)
+
+	case "es256":
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return fmt.Errorf("generating key: %w", err)
+		}
+		public = &privateKey.PublicKey
+		if privateBytes, err = x509.MarshalPKCS8PrivateKey(privateKey); err != nil {
+			return fmt.Errorf("marshaling private key: %w", err)
+		}
+
+	case "eddsa":
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("generating key: %w", err)
+		}
+		public = publicKey
+		if privateBytes, err = x509.MarshalPKCS8PrivateKey(privateKey); err != nil {
+			return fmt.Errorf("marshaling private key: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported algorithm %q: must be rs256, es256 or eddsa", alg)
 	}
 
 	// Create a file for the private key information in PEM form.
@@ -28,7 +65,7 @@ func GenKey() error {
 	// Construct a PEM block for the private key.
 	privateBlock := pem.Block{
 		Type:  "PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		Bytes: privateBytes,
 	}
 
 	// Write the private key to the private key file.
@@ -44,7 +81,7 @@ func GenKey() error {
 	defer publicFile.Close()
 
 	// Marshal the public key from the private key to PKIX.
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(public)
 	if err != nil {
 		return fmt.Errorf("marshaling public key: %w", err)
 	}
@@ -33,7 +33,7 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	userBus := userbus.NewBusiness(log, nil, userdb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, nil, nil, nil, userdb.NewStore(log, db))
 
 	usr, err := userBus.QueryByID(ctx, userID)
 	if err != nil {
@@ -68,6 +68,13 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	roles := role.ParseToString(usr.Roles)
+
+	perms, err := ath.Permissions(ctx, usr.ID, roles)
+	if err != nil {
+		return fmt.Errorf("retrieve permissions: %w", err)
+	}
+
 	// Generating a token requires defining a set of claims. In this applications
 	// case, we only care about defining the subject and the user in question and
 	// the roles they have on the database. This token will expire in a year.
@@ -86,7 +93,9 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
-		Roles: role.ParseToString(usr.Roles),
+		Roles:       roles,
+		Permissions: perms,
+		Tenant:      usr.OrgID.String(),
 	}
 
 	// This will generate a JWT with the claims embedded in them. The database
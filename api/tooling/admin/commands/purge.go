@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus/stores/tokendb"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// DefaultRetention is how long deleted rows are kept when no retention is
+// provided to the purge command.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// Purge permanently removes the users, products and homes that were deleted
+// longer ago than the retention window. Refresh tokens and revoked access
+// tokens are removed as soon as they expire since they serve no purpose
+// after that.
+func Purge(log *logger.Logger, cfg sqldb.Config, retention string) error {
+	keep := DefaultRetention
+	if retention != "" {
+		d, err := time.ParseDuration(retention)
+		if err != nil {
+			fmt.Println("help: purge [retention]")
+			return fmt.Errorf("parsing retention: %w", err)
+		}
+		keep = d
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	userBus := userbus.NewBusiness(log, nil, nil, nil, userdb.NewStore(log, db))
+	productBus := productbus.NewBusiness(log, userBus, nil, nil, productdb.NewStore(log, db))
+	homeBus := homebus.NewBusiness(log, userBus, nil, nil, homedb.NewStore(log, db))
+	tokenBus := tokenbus.NewBusiness(log, tokendb.NewStore(log, db))
+
+	before := time.Now().Add(-keep)
+
+	// Products and homes go first. Purging a user removes whatever is left
+	// of their products and homes through the foreign keys.
+	prds, err := productBus.Purge(ctx, before)
+	if err != nil {
+		return fmt.Errorf("purge products: %w", err)
+	}
+
+	hmes, err := homeBus.Purge(ctx, before)
+	if err != nil {
+		return fmt.Errorf("purge homes: %w", err)
+	}
+
+	usrs, err := userBus.Purge(ctx, before)
+	if err != nil {
+		return fmt.Errorf("purge users: %w", err)
+	}
+
+	now := time.Now()
+
+	rfts, err := tokenBus.PurgeRefresh(ctx, now)
+	if err != nil {
+		return fmt.Errorf("purge refresh tokens: %w", err)
+	}
+
+	rvts, err := tokenBus.PurgeRevoked(ctx, now)
+	if err != nil {
+		return fmt.Errorf("purge revoked tokens: %w", err)
+	}
+
+	fmt.Printf("purged rows deleted before %s\n", before.Format(time.RFC3339))
+	fmt.Printf("products: %d\n", prds)
+	fmt.Printf("homes   : %d\n", hmes)
+	fmt.Printf("users   : %d\n", usrs)
+	fmt.Printf("purged tokens expired before %s\n", now.Format(time.RFC3339))
+	fmt.Printf("refresh : %d\n", rfts)
+	fmt.Printf("revoked : %d\n", rvts)
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
@@ -30,7 +32,8 @@ func UserAdd(log *logger.Logger, cfg sqldb.Config, nme string, email string, pas
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	userBus := userbus.NewBusiness(log, nil, userdb.NewStore(log, db))
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, nil, nil, auditBus, userdb.NewStore(log, db))
 
 	addr, err := mail.ParseAddress(email)
 	if err != nil {
@@ -121,8 +121,27 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 			return fmt.Errorf("getting users: %w", err)
 		}
 
+	case "apikey":
+		switch args.Num(1) {
+		case "create":
+			if err := commands.APIKeyCreate(log, dbConfig, args.Num(2), args.Num(3), args.Num(4), args.Num(5)); err != nil {
+				return fmt.Errorf("creating api key: %w", err)
+			}
+
+		case "revoke":
+			if err := commands.APIKeyRevoke(log, dbConfig, args.Num(2)); err != nil {
+				return fmt.Errorf("revoking api key: %w", err)
+			}
+
+		default:
+			fmt.Println("help: apikey create <user_id> <name> <roles,...> [ttl]")
+			fmt.Println("help: apikey revoke <apikey_id>")
+			return commands.ErrHelp
+		}
+
 	case "genkey":
-		if err := commands.GenKey(); err != nil {
+		alg := args.Num(1)
+		if err := commands.GenKey(alg); err != nil {
 			return fmt.Errorf("key generation: %w", err)
 		}
 
@@ -139,13 +158,27 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 			return fmt.Errorf("generating token: %w", err)
 		}
 
+	case "events":
+		if err := commands.Events(); err != nil {
+			return fmt.Errorf("listing events: %w", err)
+		}
+
+	case "purge":
+		retention := args.Num(1)
+		if err := commands.Purge(log, dbConfig, retention); err != nil {
+			return fmt.Errorf("purging deleted data: %w", err)
+		}
+
 	default:
 		fmt.Println("migrate:    create the schema in the database")
 		fmt.Println("seed:       add data to the database")
 		fmt.Println("useradd:    add a new user to the database")
 		fmt.Println("users:      get a list of users from the database")
-		fmt.Println("genkey:     generate a set of private/public key files")
+		fmt.Println("apikey:     issue or revoke an api key for a user")
+		fmt.Println("genkey:     generate a set of private/public key files [rs256|es256|eddsa]")
 		fmt.Println("gentoken:   generate a JWT for a user with claims")
+		fmt.Println("events:     list the events the domains define")
+		fmt.Println("purge:      remove deleted data older than the retention [720h] and expired tokens")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
 	}
@@ -0,0 +1,161 @@
+// Package apikeyapp maintains the app layer api for the api key domain.
+package apikeyapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type app struct {
+	apiKeyBus *apikeybus.Business
+	userBus   *userbus.Business
+}
+
+func newApp(apiKeyBus *apikeybus.Business, userBus *userbus.Business) *app {
+	return &app{
+		apiKeyBus: apiKeyBus,
+		userBus:   userBus,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewAPIKey
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nak, err := toBusNewAPIKey(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	// A key carries the tenant of its user, so a user in another tenant is
+	// reported as not found so its existence isn't revealed.
+	usr, err := a.userBus.QueryByID(ctx, nak.UserID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", nak.UserID, err)
+	}
+
+	if usr.OrgID != tenant {
+		return errs.New(errs.NotFound, userbus.ErrNotFound)
+	}
+
+	raw, key, err := a.apiKeyBus.Create(ctx, nak)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound):
+			return errs.New(errs.NotFound, err)
+		case errors.Is(err, apikeybus.ErrInvalidRoles), errors.Is(err, apikeybus.ErrUserDisabled):
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "create: nak[%+v]: %s", nak, err)
+	}
+
+	return toAppIssuedAPIKey(raw, key)
+}
+
+func (a *app) revoke(ctx context.Context, r *http.Request) web.Encoder {
+	key, err := a.queryKey(ctx, r)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if _, err := a.apiKeyBus.Revoke(ctx, key); err != nil {
+		return errs.Newf(errs.Internal, "revoke: apikeyID[%s]: %s", key.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	filter.OrgID = &tenant
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, apikeybus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	keys, err := a.apiKeyBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.apiKeyBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppAPIKeys(keys), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	key, err := a.queryKey(ctx, r)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	return toAppAPIKey(key)
+}
+
+func (a *app) queryKey(ctx context.Context, r *http.Request) (apikeybus.APIKey, error) {
+	keyID, err := uuid.Parse(web.Param(r, "apikey_id"))
+	if err != nil {
+		return apikeybus.APIKey{}, errs.NewFieldErrors("apikey_id", err)
+	}
+
+	key, err := a.apiKeyBus.QueryByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, apikeybus.ErrNotFound) {
+			return apikeybus.APIKey{}, errs.New(errs.NotFound, err)
+		}
+		return apikeybus.APIKey{}, errs.Newf(errs.Internal, "querybyid: apikeyID[%s]: %s", keyID, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return apikeybus.APIKey{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	// A key in another tenant is reported as not found so its existence
+	// isn't revealed.
+	if key.OrgID != tenant {
+		return apikeybus.APIKey{}, errs.New(errs.NotFound, apikeybus.ErrNotFound)
+	}
+
+	return key, nil
+}
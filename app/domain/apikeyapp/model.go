@@ -0,0 +1,138 @@
+package apikeyapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+// APIKey represents information about an api key. The key itself is never
+// returned, only its prefix.
+type APIKey struct {
+	ID           string   `json:"id"`
+	UserID       string   `json:"userID"`
+	Name         string   `json:"name"`
+	Prefix       string   `json:"prefix"`
+	Roles        []string `json:"roles"`
+	DateExpires  string   `json:"dateExpires,omitempty"`
+	DateLastUsed string   `json:"dateLastUsed,omitempty"`
+	DateCreated  string   `json:"dateCreated"`
+	DateRevoked  string   `json:"dateRevoked,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app APIKey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppAPIKey(key apikeybus.APIKey) APIKey {
+	return APIKey{
+		ID:           key.ID.String(),
+		UserID:       key.UserID.String(),
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		Roles:        role.ParseToString(key.Roles),
+		DateExpires:  formatTime(key.DateExpires),
+		DateLastUsed: formatTime(key.DateLastUsed),
+		DateCreated:  key.DateCreated.Format(time.RFC3339),
+		DateRevoked:  formatTime(key.DateRevoked),
+	}
+}
+
+func toAppAPIKeys(keys []apikeybus.APIKey) []APIKey {
+	app := make([]APIKey, len(keys))
+	for i, key := range keys {
+		app[i] = toAppAPIKey(key)
+	}
+
+	return app
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// =============================================================================
+
+// IssuedAPIKey is returned when a key is created. This is the only time the
+// key is available.
+type IssuedAPIKey struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
+
+// Encode implements the encoder interface.
+func (app IssuedAPIKey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppIssuedAPIKey(raw string, key apikeybus.APIKey) IssuedAPIKey {
+	return IssuedAPIKey{
+		Key:    raw,
+		APIKey: toAppAPIKey(key),
+	}
+}
+
+// =============================================================================
+
+// NewAPIKey defines the data needed to issue a new api key.
+type NewAPIKey struct {
+	UserID      string   `json:"userID" validate:"required,uuid"`
+	Name        string   `json:"name" validate:"required,max=100"`
+	Roles       []string `json:"roles" validate:"required,min=1"`
+	DateExpires string   `json:"dateExpires"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewAPIKey) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewAPIKey) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewAPIKey(app NewAPIKey) (apikeybus.NewAPIKey, error) {
+	userID, err := uuid.Parse(app.UserID)
+	if err != nil {
+		return apikeybus.NewAPIKey{}, fmt.Errorf("parse: %w", err)
+	}
+
+	roles, err := role.ParseMany(app.Roles)
+	if err != nil {
+		return apikeybus.NewAPIKey{}, fmt.Errorf("parse: %w", err)
+	}
+
+	var expires time.Time
+	if app.DateExpires != "" {
+		expires, err = time.Parse(time.RFC3339, app.DateExpires)
+		if err != nil {
+			return apikeybus.NewAPIKey{}, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	bus := apikeybus.NewAPIKey{
+		UserID:      userID,
+		Name:        app.Name,
+		Roles:       roles,
+		DateExpires: expires,
+	}
+
+	return bus, nil
+}
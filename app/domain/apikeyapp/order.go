@@ -0,0 +1,12 @@
+package apikeyapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+)
+
+var orderByFields = map[string]string{
+	"apikey_id":    apikeybus.OrderByID,
+	"user_id":      apikeybus.OrderByUserID,
+	"name":         apikeybus.OrderByName,
+	"date_created": apikeybus.OrderByDateCreated,
+}
@@ -0,0 +1,36 @@
+package apikeyapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	APIKeyBus  *apikeybus.Business
+	UserBus    *userbus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+
+	api := newApp(cfg.APIKeyBus, cfg.UserBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/apikeys", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/apikeys/{apikey_id}", api.queryByID, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/apikeys", api.create, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodDelete, version, "/apikeys/{apikey_id}", api.revoke, authen, ruleAdmin)
+}
@@ -0,0 +1,84 @@
+package auditapp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// entities lists the entities that record an audit trail.
+var entities = map[string]bool{
+	userbus.DomainName:    true,
+	productbus.DomainName: true,
+	homebus.DomainName:    true,
+}
+
+type queryParams struct {
+	Page     string
+	Rows     string
+	OrderBy  string
+	Entity   string
+	EntityID string
+	ActorID  string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:     values.Get("page"),
+		Rows:     values.Get("rows"),
+		OrderBy:  values.Get("orderBy"),
+		Entity:   values.Get("entity"),
+		EntityID: values.Get("id"),
+		ActorID:  values.Get("actor_id"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (auditbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter auditbus.QueryFilter
+
+	if qp.Entity != "" {
+		switch entities[qp.Entity] {
+		case true:
+			filter.Entity = &qp.Entity
+		default:
+			fieldErrors.Add("entity", fmt.Errorf("unknown entity %q", qp.Entity))
+		}
+	}
+
+	if qp.EntityID != "" {
+		id, err := uuid.Parse(qp.EntityID)
+		switch err {
+		case nil:
+			filter.EntityID = &id
+		default:
+			fieldErrors.Add("id", err)
+		}
+	}
+
+	if qp.ActorID != "" {
+		id, err := uuid.Parse(qp.ActorID)
+		switch err {
+		case nil:
+			filter.ActorID = &id
+		default:
+			fieldErrors.Add("actor_id", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return auditbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
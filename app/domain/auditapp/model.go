@@ -0,0 +1,56 @@
+package auditapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/google/uuid"
+)
+
+// Audit represents a single recorded change to an entity.
+type Audit struct {
+	ID          string          `json:"id"`
+	Entity      string          `json:"entity"`
+	EntityID    string          `json:"entityID"`
+	Action      string          `json:"action"`
+	ActorID     string          `json:"actorID,omitempty"`
+	TraceID     string          `json:"traceID,omitempty"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	DateCreated string          `json:"dateCreated"`
+}
+
+// Encode implements the encoder interface.
+func (app Audit) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppAudit(aud auditbus.Audit) Audit {
+	var actorID string
+	if aud.ActorID != uuid.Nil {
+		actorID = aud.ActorID.String()
+	}
+
+	return Audit{
+		ID:          aud.ID.String(),
+		Entity:      aud.Entity,
+		EntityID:    aud.EntityID.String(),
+		Action:      aud.Action,
+		ActorID:     actorID,
+		TraceID:     aud.TraceID,
+		Before:      aud.Before,
+		After:       aud.After,
+		DateCreated: aud.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppAudits(auds []auditbus.Audit) []Audit {
+	app := make([]Audit, len(auds))
+	for i, aud := range auds {
+		app[i] = toAppAudit(aud)
+	}
+
+	return app
+}
@@ -0,0 +1,11 @@
+package auditapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/auditbus"
+)
+
+var orderByFields = map[string]string{
+	"audit_id":     auditbus.OrderByID,
+	"action":       auditbus.OrderByAction,
+	"date_created": auditbus.OrderByDateCreated,
+}
@@ -4,22 +4,44 @@ package authapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/types/role"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
+// defaultImpersonationTTL is used when the configuration doesn't provide the
+// lifetime of impersonation tokens.
+const defaultImpersonationTTL = 15 * time.Minute
+
 type app struct {
-	auth *auth.Auth
+	auth             *auth.Auth
+	userBus          *userbus.Business
+	sessionBus       *sessionbus.Business
+	impersonationTTL time.Duration
 }
 
-func newApp(ath *auth.Auth) *app {
+func newApp(ath *auth.Auth, userBus *userbus.Business, sessionBus *sessionbus.Business, impersonationTTL time.Duration) *app {
+	if impersonationTTL <= 0 {
+		impersonationTTL = defaultImpersonationTTL
+	}
+
 	return &app{
-		auth: ath,
+		auth:             ath,
+		userBus:          userBus,
+		sessionBus:       sessionBus,
+		impersonationTTL: impersonationTTL,
 	}
 }
 
@@ -31,12 +53,19 @@ func (a *app) token(ctx context.Context, r *http.Request) web.Encoder {
 
 	// The BearerBasic middleware function generates the claims.
 	claims := mid.GetClaims(ctx)
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
 
 	tkn, err := a.auth.GenerateToken(kid, claims)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
+	if err := a.startSession(ctx, r, claims); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	return token{Token: tkn}
 }
 
@@ -63,9 +92,134 @@ func (a *app) authorize(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	if err := a.auth.Authorize(ctx, auth.Claims, auth.UserID, auth.Rule); err != nil {
+	if err := a.auth.AuthorizeResource(ctx, auth.Claims, auth.UserID, auth.Rule, auth.Resource); err != nil {
 		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", auth.Claims.Roles, auth.Rule, err)
 	}
 
 	return nil
 }
+
+// impersonate issues a short lived token for an admin to act as the specified
+// user. The admin is carried as the actor so every request made with the
+// token can be traced back to them and the policy can reject sensitive
+// actions.
+func (a *app) impersonate(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return errs.NewFieldErrors("user_id", err)
+	}
+
+	claims := mid.GetClaims(ctx)
+
+	resource := auth.Resource{
+		Type:   "user",
+		Action: auth.ActionImpersonate,
+	}
+
+	if err := a.auth.AuthorizeResource(ctx, claims, userID, auth.RuleAdminOnly, resource); err != nil {
+		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, auth.RuleAdminOnly, err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	// A user in another tenant is reported as not found so its existence
+	// isn't revealed.
+	if usr.OrgID.String() != claims.Tenant {
+		return errs.New(errs.NotFound, userbus.ErrNotFound)
+	}
+
+	if !usr.Enabled {
+		return errs.New(errs.FailedPrecondition, errors.New("user disabled"))
+	}
+
+	if slices.Contains(usr.Roles, role.Admin) {
+		return errs.New(errs.FailedPrecondition, errors.New("admins can't be impersonated"))
+	}
+
+	roles := role.ParseToString(usr.Roles)
+
+	perms, err := a.auth.Permissions(ctx, usr.ID, roles)
+	if err != nil {
+		return errs.Newf(errs.Internal, "permissions: userID[%s]: %s", usr.ID, err)
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(a.impersonationTTL)
+
+	impClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   usr.ID.String(),
+			Issuer:    a.auth.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles:       roles,
+		Permissions: perms,
+		Tenant:      usr.OrgID.String(),
+		Actor: &auth.Actor{
+			Subject: claims.Subject,
+		},
+	}
+
+	tkn, err := a.auth.GenerateToken(a.auth.ActiveKID(), impClaims)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// The session is recorded against the user so they can see that
+	// someone acted as them and end it.
+	if err := a.startSession(ctx, r, impClaims); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	resp := impersonation{
+		Token:     tkn,
+		ExpiresAt: expires.Format(time.RFC3339),
+	}
+
+	return resp
+}
+
+func (a *app) jwks(ctx context.Context, r *http.Request) web.Encoder {
+	set, err := a.auth.JWKS()
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// Keys change rarely, let verifiers cache the set for a short time so
+	// newly rotated keys are still picked up quickly.
+	web.GetWriter(ctx).Header().Set("Cache-Control", "public, max-age=300")
+
+	return jwks(set)
+}
+
+// =============================================================================
+
+// startSession records the session for a token issued to the subject of the
+// claims.
+func (a *app) startSession(ctx context.Context, r *http.Request, claims auth.Claims) error {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parse subject: %w", err)
+	}
+
+	ns := sessionbus.NewSession{
+		UserID:      userID,
+		TokenID:     claims.ID,
+		Client:      mid.SessionClient(ctx, r),
+		DateExpires: claims.ExpiresAt.Time,
+	}
+
+	if _, err := a.sessionBus.Create(ctx, ns); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+
+	return nil
+}
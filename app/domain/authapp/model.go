@@ -1,6 +1,10 @@
 package authapp
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/ardanlabs/service/foundation/keystore"
+)
 
 type token struct {
 	Token string `json:"token"`
@@ -11,3 +15,22 @@ func (t token) Encode() ([]byte, string, error) {
 	data, err := json.Marshal(t)
 	return data, "application/json", err
 }
+
+type impersonation struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// Encode implements the encoder interface.
+func (i impersonation) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(i)
+	return data, "application/json", err
+}
+
+type jwks keystore.JWKS
+
+// Encode implements the encoder interface.
+func (j jwks) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(j)
+	return data, "application/json", err
+}
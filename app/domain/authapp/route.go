@@ -2,17 +2,25 @@ package authapp
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	UserBus *userbus.Business
-	Auth    *auth.Auth
+	UserBus          *userbus.Business
+	APIKeyBus        *apikeybus.Business
+	LockoutBus       *lockoutbus.Business
+	SessionBus       *sessionbus.Business
+	Auth             *auth.Auth
+	ImpersonationTTL time.Duration
 }
 
 // Routes adds specific routes for this group.
@@ -20,11 +28,14 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	bearer := mid.Bearer(cfg.Auth)
-	basic := mid.Basic(cfg.Auth, cfg.UserBus)
+	basic := mid.Basic(cfg.Auth, cfg.UserBus, cfg.LockoutBus)
+	apiKey := mid.APIKey(cfg.Auth, cfg.APIKeyBus, bearer)
 
-	api := newApp(cfg.Auth)
+	api := newApp(cfg.Auth, cfg.UserBus, cfg.SessionBus, cfg.ImpersonationTTL)
 
 	app.HandlerFunc(http.MethodGet, version, "/auth/token/{kid}", api.token, basic)
-	app.HandlerFunc(http.MethodGet, version, "/auth/authenticate", api.authenticate, bearer)
+	app.HandlerFunc(http.MethodGet, version, "/auth/authenticate", api.authenticate, apiKey)
 	app.HandlerFunc(http.MethodPost, version, "/auth/authorize", api.authorize)
+	app.HandlerFunc(http.MethodPost, version, "/auth/impersonate/{user_id}", api.impersonate, bearer)
+	app.HandlerFunc(http.MethodGet, "", "/.well-known/jwks.json", api.jwks)
 }
@@ -0,0 +1,62 @@
+// Package decisionapp maintains the app layer api for the auth decision log.
+package decisionapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type app struct {
+	decisionBus *decisionbus.Business
+}
+
+func newApp(decisionBus *decisionbus.Business) *app {
+	return &app{
+		decisionBus: decisionBus,
+	}
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	filter.OrgID = &tenant
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, decisionbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	decs, err := a.decisionBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.decisionBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppDecisions(decs), total, page)
+}
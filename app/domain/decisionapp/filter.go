@@ -0,0 +1,97 @@
+package decisionapp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/google/uuid"
+)
+
+type queryParams struct {
+	Page             string
+	Rows             string
+	OrderBy          string
+	UserID           string
+	Rule             string
+	Allowed          string
+	StartCreatedDate string
+	EndCreatedDate   string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:             values.Get("page"),
+		Rows:             values.Get("rows"),
+		OrderBy:          values.Get("orderBy"),
+		UserID:           values.Get("user_id"),
+		Rule:             values.Get("rule"),
+		Allowed:          values.Get("allowed"),
+		StartCreatedDate: values.Get("start_created_date"),
+		EndCreatedDate:   values.Get("end_created_date"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (decisionbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter decisionbus.QueryFilter
+
+	// Decisions are recorded against the subject of the claims, which is the
+	// user id for user tokens.
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		switch err {
+		case nil:
+			subject := id.String()
+			filter.Subject = &subject
+		default:
+			fieldErrors.Add("user_id", err)
+		}
+	}
+
+	if qp.Rule != "" {
+		filter.Rule = &qp.Rule
+	}
+
+	if qp.Allowed != "" {
+		allowed, err := strconv.ParseBool(qp.Allowed)
+		switch err {
+		case nil:
+			filter.Allowed = &allowed
+		default:
+			fieldErrors.Add("allowed", err)
+		}
+	}
+
+	if qp.StartCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
+		switch err {
+		case nil:
+			filter.StartCreatedDate = &t
+		default:
+			fieldErrors.Add("start_created_date", err)
+		}
+	}
+
+	if qp.EndCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
+		switch err {
+		case nil:
+			filter.EndCreatedDate = &t
+		default:
+			fieldErrors.Add("end_created_date", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return decisionbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,48 @@
+package decisionapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+)
+
+// Decision represents the outcome of evaluating an auth rule.
+type Decision struct {
+	ID          string          `json:"id"`
+	Subject     string          `json:"subject"`
+	Rule        string          `json:"rule"`
+	Input       json.RawMessage `json:"input"`
+	Allowed     bool            `json:"allowed"`
+	Reason      string          `json:"reason,omitempty"`
+	Latency     string          `json:"latency"`
+	DateCreated string          `json:"dateCreated"`
+}
+
+// Encode implements the encoder interface.
+func (app Decision) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppDecision(dec decisionbus.Decision) Decision {
+	return Decision{
+		ID:          dec.ID.String(),
+		Subject:     dec.Subject,
+		Rule:        dec.Rule,
+		Input:       dec.Input,
+		Allowed:     dec.Allowed,
+		Reason:      dec.Reason,
+		Latency:     dec.Latency.String(),
+		DateCreated: dec.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppDecisions(decs []decisionbus.Decision) []Decision {
+	app := make([]Decision, len(decs))
+	for i, dec := range decs {
+		app[i] = toAppDecision(dec)
+	}
+
+	return app
+}
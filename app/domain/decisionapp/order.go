@@ -0,0 +1,12 @@
+package decisionapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+)
+
+var orderByFields = map[string]string{
+	"subject":      decisionbus.OrderBySubject,
+	"rule":         decisionbus.OrderByRule,
+	"latency":      decisionbus.OrderByLatency,
+	"date_created": decisionbus.OrderByDateCreated,
+}
@@ -2,6 +2,7 @@ package homeapp
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
@@ -19,6 +20,7 @@ type queryParams struct {
 	Type             string
 	StartCreatedDate string
 	EndCreatedDate   string
+	Archived         string
 }
 
 func parseQueryParams(r *http.Request) queryParams {
@@ -33,6 +35,7 @@ func parseQueryParams(r *http.Request) queryParams {
 		Type:             values.Get("type"),
 		StartCreatedDate: values.Get("start_created_date"),
 		EndCreatedDate:   values.Get("end_created_date"),
+		Archived:         values.Get("archived"),
 	}
 
 	return filter
@@ -92,6 +95,16 @@ func parseFilter(qp queryParams) (homebus.QueryFilter, error) {
 		}
 	}
 
+	if qp.Archived != "" {
+		archived, err := strconv.ParseBool(qp.Archived)
+		switch err {
+		case nil:
+			filter.Archived = &archived
+		default:
+			fieldErrors.Add("archived", err)
+		}
+	}
+
 	if fieldErrors != nil {
 		return homebus.QueryFilter{}, fieldErrors.ToError()
 	}
@@ -3,15 +3,20 @@ package homeapp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/patch"
 	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
 )
 
 type app struct {
@@ -24,6 +29,26 @@ func newApp(homeBus *homebus.Business) *app {
 	}
 }
 
+// newWithTx constructs a new app value with the domain api
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	homeBus, err := a.homeBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		homeBus: homeBus,
+	}
+
+	return &app, nil
+}
+
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 	var app NewHome
 	if err := web.Decode(r, &app); err != nil {
@@ -35,11 +60,18 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	hme, err := a.homeBus.Create(ctx, nh)
 	if err != nil {
 		return errs.Newf(errs.Internal, "create: hme[%+v]: %s", app, err)
 	}
 
+	etag.Set(ctx, hme.Version)
+
 	return toAppHome(hme)
 }
 
@@ -59,21 +91,101 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "home missing in context: %s", err)
 	}
 
-	updUsr, err := a.homeBus.Update(ctx, hme, uh)
+	if err := etag.Match(r, hme.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	updHme, err := a.homeBus.Update(ctx, hme, uh)
 	if err != nil {
+		if errors.Is(err, homebus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, homebus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "update: homeID[%s] uh[%+v]: %s", hme.ID, uh, err)
 	}
 
-	return toAppHome(updUsr)
+	etag.Set(ctx, updHme.Version)
+
+	return toAppHome(updHme)
 }
 
-func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
+// patch applies a merge patch or a JSON patch to the home. The patch is
+// applied to the current state of the home and the result is validated like
+// a full document.
+func (a *app) patch(ctx context.Context, r *http.Request) web.Encoder {
+	hme, err := mid.GetHome(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "home missing in context: %s", err)
+	}
+
+	if err := etag.Match(r, hme.Version); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(toPatchHome(hme))
+	if err != nil {
+		return errs.Newf(errs.Internal, "marshal: homeID[%s]: %s", hme.ID, err)
+	}
+
+	doc, err = patch.Apply(r, doc)
+	if err != nil {
+		switch {
+		case errors.Is(err, patch.ErrTestFailed):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, patch.ErrUnsupportedType):
+			return errs.New(errs.UnsupportedMediaType, err)
+		case errors.Is(err, patch.ErrTooLarge):
+			return errs.New(errs.PayloadTooLarge, err)
+		}
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	uh, err := toBusPatchHome(doc)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	updHme, err := a.homeBus.Update(ctx, hme, uh)
+	if err != nil {
+		if errors.Is(err, homebus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, homebus.ErrVersionConflict)
+		}
+		return errs.Newf(errs.Internal, "patch: homeID[%s]: %s", hme.ID, err)
+	}
+
+	etag.Set(ctx, updHme.Version)
+
+	return toAppHome(updHme)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
 	hme, err := mid.GetHome(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "homeID missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, hme.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	if err := a.homeBus.Delete(ctx, hme); err != nil {
+		if errors.Is(err, homebus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, homebus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "delete: homeID[%s]: %s", hme.ID, err)
 	}
 
@@ -93,6 +205,12 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return err.(*errs.Error)
 	}
 
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	filter.OrgID = &tenant
+
 	orderBy, err := order.Parse(orderByFields, qp.OrderBy, homebus.DefaultOrderBy)
 	if err != nil {
 		return errs.NewFieldErrors("order", err)
@@ -117,5 +235,43 @@ func (a *app) queryByID(ctx context.Context, _ *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
+	etag.Set(ctx, hme.Version)
+
+	return toAppHome(hme)
+}
+
+// restore brings back a deleted home. It runs inside a transaction scoped
+// to the caller's tenant so a home from another organisation can't be
+// restored.
+func (a *app) restore(ctx context.Context, r *http.Request) web.Encoder {
+	homeID, err := uuid.Parse(web.Param(r, "home_id"))
+	if err != nil {
+		return errs.NewFieldErrors("home_id", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	hme, err := a.homeBus.Restore(ctx, homeID)
+	if err != nil {
+		if errors.Is(err, homebus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "restore: homeID[%s]: %s", homeID, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if hme.OrgID != tenant {
+		return errs.New(errs.NotFound, homebus.ErrNotFound)
+	}
+
+	etag.Set(ctx, hme.Version)
+
 	return toAppHome(hme)
 }
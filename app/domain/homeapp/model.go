@@ -8,6 +8,7 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/patch"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/types/hometype"
 )
@@ -24,12 +25,13 @@ type Address struct {
 
 // Home represents information about an individual home.
 type Home struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Type        string  `json:"type"`
-	Address     Address `json:"address"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID           string  `json:"id"`
+	UserID       string  `json:"userID"`
+	Type         string  `json:"type"`
+	Address      Address `json:"address"`
+	DateCreated  string  `json:"dateCreated"`
+	DateUpdated  string  `json:"dateUpdated"`
+	DateArchived string  `json:"dateArchived,omitempty"`
 }
 
 // Encode implements the encoder interface.
@@ -51,8 +53,9 @@ func toAppHome(hme homebus.Home) Home {
 			State:    hme.Address.State,
 			Country:  hme.Address.Country,
 		},
-		DateCreated: hme.DateCreated.Format(time.RFC3339),
-		DateUpdated: hme.DateUpdated.Format(time.RFC3339),
+		DateCreated:  hme.DateCreated.Format(time.RFC3339),
+		DateUpdated:  hme.DateUpdated.Format(time.RFC3339),
+		DateArchived: formatTime(hme.DateArchived),
 	}
 }
 
@@ -65,6 +68,14 @@ func toAppHomes(homes []homebus.Home) []Home {
 	return app
 }
 
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
 // =============================================================================
 
 // NewAddress defines the data needed to add a new address.
@@ -183,3 +194,71 @@ func toBusUpdateHome(app UpdateHome) (homebus.UpdateHome, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// patchAddress is the address held by the document a patch is applied to.
+// Removing address2 or setting it to null clears it.
+type patchAddress struct {
+	Address1 *string `json:"address1" validate:"required,min=1,max=70"`
+	Address2 *string `json:"address2" validate:"omitempty,max=70"`
+	ZipCode  *string `json:"zipCode" validate:"required,numeric"`
+	City     *string `json:"city" validate:"required"`
+	State    *string `json:"state" validate:"required,min=1,max=48"`
+	Country  *string `json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+// patchHome is the document a patch is applied to. It holds the current
+// state of the home, so the patched document has to keep every required
+// field.
+type patchHome struct {
+	Type    *string       `json:"type" validate:"required"`
+	Address *patchAddress `json:"address" validate:"required"`
+}
+
+func toPatchHome(hme homebus.Home) patchHome {
+	typ := hme.Type.String()
+	addr := hme.Address
+
+	return patchHome{
+		Type: &typ,
+		Address: &patchAddress{
+			Address1: &addr.Address1,
+			Address2: &addr.Address2,
+			ZipCode:  &addr.ZipCode,
+			City:     &addr.City,
+			State:    &addr.State,
+			Country:  &addr.Country,
+		},
+	}
+}
+
+func toBusPatchHome(data []byte) (homebus.UpdateHome, error) {
+	var app patchHome
+	if err := patch.Decode(data, &app); err != nil {
+		return homebus.UpdateHome{}, fmt.Errorf("validate: %w", err)
+	}
+
+	if err := errs.Check(app); err != nil {
+		return homebus.UpdateHome{}, fmt.Errorf("validate: %w", err)
+	}
+
+	address2 := app.Address.Address2
+	if address2 == nil {
+		address2 = new(string)
+	}
+
+	uh := UpdateHome{
+		Type: app.Type,
+		Address: &UpdateAddress{
+			Address1: app.Address.Address1,
+			Address2: address2,
+			ZipCode:  app.Address.ZipCode,
+			City:     app.Address.City,
+			State:    app.Address.State,
+			Country:  app.Address.Country,
+		},
+	}
+
+	return toBusUpdateHome(uh)
+}
@@ -7,13 +7,16 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log        *logger.Logger
+	DB         *sqlx.DB
 	HomeBus    *homebus.Business
 	AuthClient *authclient.Client
 }
@@ -23,15 +26,19 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
-	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+	rulePermission := mid.AuthorizePermission(cfg.AuthClient, "home")
 	ruleAuthorizeHome := mid.AuthorizeHome(cfg.AuthClient, cfg.HomeBus)
 
 	api := newApp(cfg.HomeBus)
 
 	app.HandlerFunc(http.MethodGet, version, "/homes", api.query, authen, ruleAny)
 	app.HandlerFunc(http.MethodGet, version, "/homes/{home_id}", api.queryByID, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, rulePermission, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, rulePermission, ruleAuthorizeHome, transaction)
+	app.HandlerFunc(http.MethodPatch, version, "/homes/{home_id}", api.patch, authen, rulePermission, ruleAuthorizeHome, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, rulePermission, ruleAuthorizeHome, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/homes/restore/{home_id}", api.restore, authen, ruleAdmin, transaction)
 }
@@ -0,0 +1,57 @@
+package oauthapp
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/oauth"
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/types/name"
+)
+
+func toBusNewIdentity(user oauth.User) (identitybus.NewIdentity, error) {
+	if user.ID == "" {
+		return identitybus.NewIdentity{}, errors.New("provider did not return a user id")
+	}
+
+	addr, err := mail.ParseAddress(user.Email)
+	if err != nil {
+		return identitybus.NewIdentity{}, fmt.Errorf("parse email: %w", err)
+	}
+
+	ni := identitybus.NewIdentity{
+		Provider:       user.Provider,
+		ProviderUserID: user.ID,
+		Email:          *addr,
+		EmailVerified:  user.EmailVerified,
+		Name:           parseName(user, *addr),
+	}
+
+	return ni, nil
+}
+
+// parseName picks the first value provided by the identity provider that is
+// a valid name in this system.
+func parseName(user oauth.User, addr mail.Address) name.Name {
+	local, _, _ := strings.Cut(addr.Address, "@")
+
+	candidates := []string{
+		user.Name,
+		user.NickName,
+		local,
+	}
+
+	for _, candidate := range candidates {
+		if len(candidate) > 20 {
+			candidate = strings.TrimSpace(candidate[:20])
+		}
+
+		if nme, err := name.Parse(candidate); err == nil {
+			return nme
+		}
+	}
+
+	return name.MustParse("OAuth User")
+}
@@ -3,48 +3,72 @@ package oauthapp
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/oauth"
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/business/types/role"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/markbates/goth"
-	"github.com/markbates/goth/gothic"
-	"github.com/markbates/goth/providers/google"
+	"github.com/google/uuid"
 )
 
+// mfaChallengeTTL is how long the user has to provide the second factor after
+// signing in with a provider.
+const mfaChallengeTTL = 5 * time.Minute
+
 type app struct {
-	log      *logger.Logger
-	auth     *auth.Auth
-	tokenKey string
-	uiURL    string
-	apiHost  string
+	log         *logger.Logger
+	auth        *auth.Auth
+	beginner    sqldb.Beginner
+	identityBus *identitybus.Business
+	sessionBus  *sessionbus.Business
+	mfaBus      *mfabus.Business
+	verifyBus   *verifybus.Business
+	registry    *oauth.Registry
+	uiURL       string
 }
 
 func newApp(cfg Config) *app {
-	goth.UseProviders(
-		google.New(cfg.GoogleKey, cfg.GoogleSecret, fmt.Sprintf("%s/api/auth/google/callback", cfg.GoogleCallBackURL)),
-	)
-
-	gothic.GetProviderName = func(r *http.Request) (string, error) {
-		return web.Param(r, "provider"), nil
-	}
-
 	return &app{
-		auth:    cfg.Auth,
-		log:     cfg.Log,
-		uiURL:   cfg.GoogleUIURL,
-		apiHost: cfg.APIHost,
+		auth:        cfg.Auth,
+		log:         cfg.Log,
+		beginner:    sqldb.NewBeginner(cfg.DB),
+		identityBus: cfg.IdentityBus,
+		sessionBus:  cfg.SessionBus,
+		mfaBus:      cfg.MFABus,
+		verifyBus:   cfg.VerifyBus,
+		registry:    cfg.Registry,
+		uiURL:       cfg.UIURL,
 	}
 }
 
 func (a *app) authenticate(ctx context.Context, r *http.Request) web.Encoder {
-	gothic.BeginAuthHandler(web.GetWriter(ctx), r)
+	w := web.GetWriter(ctx)
+
+	authURL, err := a.registry.Begin(w, web.Param(r, "provider"))
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
 
 	return web.NewNoResponse()
 }
@@ -52,40 +76,142 @@ func (a *app) authenticate(ctx context.Context, r *http.Request) web.Encoder {
 func (a *app) authCallback(ctx context.Context, r *http.Request) web.Encoder {
 	w := web.GetWriter(ctx)
 
-	user, err := gothic.CompleteUserAuth(w, r)
+	user, err := a.registry.Complete(w, r, web.Param(r, "provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, oauth.ErrUnknownProvider):
+			return errs.New(errs.NotFound, err)
+		case errors.Is(err, oauth.ErrInvalidState):
+			return errs.New(errs.Unauthenticated, err)
+		}
+		return errs.New(errs.Internal, err)
+	}
+
+	ni, err := toBusNewIdentity(user)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.findOrCreateUser(ctx, ni)
+	if err != nil {
+		switch {
+		case errors.Is(err, identitybus.ErrEmailUnverified), errors.Is(err, identitybus.ErrLinkNotAllowed):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, userbus.ErrUniqueEmail):
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.Newf(errs.Internal, "findorcreateuser: %s", err)
+	}
+
+	if !usr.Enabled {
+		return errs.Newf(errs.Unauthenticated, "user disabled")
+	}
+
+	// The provider only stands in for the password. A user with multi-factor
+	// authentication enabled gets the same challenge token a password login
+	// returns, which the UI exchanges along with a code at /v1/login/mfa.
+	mfaEnabled, err := a.mfaBus.Enabled(ctx, usr.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "mfa enabled: %s", err)
+	}
+
+	if mfaEnabled {
+		raw, _, err := a.verifyBus.Create(ctx, usr.ID, verifybus.PurposeMFAChallenge, mfaChallengeTTL)
+		if err != nil {
+			return errs.Newf(errs.Internal, "create: %s", err)
+		}
+
+		redirect := fmt.Sprintf("%s/app/login/mfa?mfaToken=%s", a.uiURL, url.QueryEscape(raw))
+		a.log.Info(ctx, "oauth login", "provider", user.Provider, "user_id", usr.ID, "status", "mfa required")
+
+		http.Redirect(w, r, redirect, http.StatusFound)
+
+		return web.NewNoResponse()
+	}
+
+	roles := role.ParseToString(usr.Roles)
+
+	perms, err := a.auth.Permissions(ctx, usr.ID, roles)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
 	clms := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   user.UserID,
+			ID:        uuid.NewString(),
+			Subject:   usr.ID.String(),
 			Issuer:    a.auth.Issuer(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(2 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
-		Roles: []string{role.Admin.String()},
+		Roles:       roles,
+		Permissions: perms,
+		Tenant:      usr.OrgID.String(),
 	}
 
-	token, err := a.auth.GenerateToken(a.tokenKey, clms)
+	token, err := a.auth.GenerateToken(a.auth.ActiveKID(), clms)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
+	ns := sessionbus.NewSession{
+		UserID:      usr.ID,
+		TokenID:     clms.ID,
+		Client:      mid.SessionClient(ctx, r),
+		DateExpires: clms.ExpiresAt.Time,
+	}
+
+	if _, err := a.sessionBus.Create(ctx, ns); err != nil {
+		return errs.Newf(errs.Internal, "create session: %s", err)
+	}
+
 	redirect := fmt.Sprintf("%s/app/admin?token=%s", a.uiURL, token)
-	a.log.Info(r.Context(), "REDIRECT", "redirect", redirect)
+	a.log.Info(ctx, "oauth login", "provider", user.Provider, "user_id", usr.ID)
 
 	http.Redirect(w, r, redirect, http.StatusFound)
 
 	return web.NewNoResponse()
 }
 
+// findOrCreateUser runs the identity lookup in a transaction so a user is
+// never left behind without the identity that links to it. The callback
+// writes its redirect before returning, so the transaction is committed here
+// rather than by the BeginCommitRollback middleware.
+func (a *app) findOrCreateUser(ctx context.Context, ni identitybus.NewIdentity) (userbus.User, error) {
+	a.log.Info(ctx, "BEGIN TRANSACTION")
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			a.log.Info(ctx, "ROLLBACK TRANSACTION", "ERROR", err)
+		}
+	}()
+
+	identityBus, err := a.identityBus.NewWithTx(tx)
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("newwithtx: %w", err)
+	}
+
+	usr, err := identityBus.FindOrCreateUser(ctx, ni)
+	if err != nil {
+		return userbus.User{}, err
+	}
+
+	a.log.Info(ctx, "COMMIT TRANSACTION")
+	if err := tx.Commit(); err != nil {
+		return userbus.User{}, fmt.Errorf("commit: %w", err)
+	}
+
+	return usr, nil
+}
+
 func (a *app) logout(ctx context.Context, r *http.Request) web.Encoder {
 	w := web.GetWriter(ctx)
 
-	if err := gothic.Logout(w, r); err != nil {
-		return errs.New(errs.Internal, err)
-	}
+	a.registry.Clear(w, web.Param(r, "provider"))
 
 	redirect := "/app/login"
 	http.Redirect(w, r, redirect, http.StatusTemporaryRedirect)
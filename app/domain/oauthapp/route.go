@@ -4,20 +4,27 @@ import (
 	"net/http"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/oauth"
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the configuration for the auth app.
 type Config struct {
-	Auth              *auth.Auth
-	Log               *logger.Logger
-	TokenKey          string
-	GoogleKey         string
-	GoogleSecret      string
-	GoogleUIURL       string
-	GoogleCallBackURL string
-	APIHost           string
+	Auth        *auth.Auth
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	IdentityBus *identitybus.Business
+	SessionBus  *sessionbus.Business
+	MFABus      *mfabus.Business
+	VerifyBus   *verifybus.Business
+	Registry    *oauth.Registry
+	UIURL       string
 }
 
 // Routes adds the routes for the auth app.
@@ -0,0 +1,77 @@
+package orgapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/types/name"
+)
+
+// Org represents information about an individual organisation.
+type Org struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DateCreated string `json:"dateCreated"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Org) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppOrg(org orgbus.Org) Org {
+	return Org{
+		ID:          org.ID.String(),
+		Name:        org.Name.String(),
+		DateCreated: org.DateCreated.Format(time.RFC3339),
+		DateUpdated: org.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppOrgs(orgs []orgbus.Org) []Org {
+	app := make([]Org, len(orgs))
+	for i, org := range orgs {
+		app[i] = toAppOrg(org)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// NewOrg defines the data needed to add a new organisation.
+type NewOrg struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewOrg) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewOrg) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewOrg(app NewOrg) (orgbus.NewOrg, error) {
+	nme, err := name.Parse(app.Name)
+	if err != nil {
+		return orgbus.NewOrg{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := orgbus.NewOrg{
+		Name: nme,
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,11 @@
+package orgapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/orgbus"
+)
+
+var orderByFields = map[string]string{
+	"org_id":       orgbus.OrderByID,
+	"name":         orgbus.OrderByName,
+	"date_created": orgbus.OrderByDateCreated,
+}
@@ -0,0 +1,96 @@
+// Package orgapp maintains the app layer api for the org domain.
+package orgapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type app struct {
+	orgBus *orgbus.Business
+}
+
+func newApp(orgBus *orgbus.Business) *app {
+	return &app{
+		orgBus: orgBus,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewOrg
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	no, err := toBusNewOrg(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	org, err := a.orgBus.Create(ctx, no)
+	if err != nil {
+		if errors.Is(err, orgbus.ErrUniqueName) {
+			return errs.New(errs.Aborted, orgbus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "create: org[%+v]: %s", no, err)
+	}
+
+	return toAppOrg(org)
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, orgbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	orgs, err := a.orgBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.orgBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppOrgs(orgs), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	orgID, err := uuid.Parse(web.Param(r, "org_id"))
+	if err != nil {
+		return errs.NewFieldErrors("org_id", err)
+	}
+
+	org, err := a.orgBus.QueryByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, orgbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: orgID[%s]: %s", orgID, err)
+	}
+
+	return toAppOrg(org)
+}
@@ -0,0 +1,32 @@
+package orgapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	OrgBus     *orgbus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	rulePermission := mid.AuthorizePermission(cfg.AuthClient, "org")
+
+	api := newApp(cfg.OrgBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/orgs", api.query, authen, rulePermission)
+	app.HandlerFunc(http.MethodGet, version, "/orgs/{org_id}", api.queryByID, authen, rulePermission)
+	app.HandlerFunc(http.MethodPost, version, "/orgs", api.create, authen, rulePermission)
+}
@@ -18,6 +18,7 @@ type queryParams struct {
 	Name     string
 	Cost     string
 	Quantity string
+	Archived string
 }
 
 func parseQueryParams(r *http.Request) queryParams {
@@ -31,6 +32,7 @@ func parseQueryParams(r *http.Request) queryParams {
 		Name:     values.Get("name"),
 		Cost:     values.Get("cost"),
 		Quantity: values.Get("quantity"),
+		Archived: values.Get("archived"),
 	}
 
 	return filter
@@ -81,6 +83,16 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 		}
 	}
 
+	if qp.Archived != "" {
+		archived, err := strconv.ParseBool(qp.Archived)
+		switch err {
+		case nil:
+			filter.Archived = &archived
+		default:
+			fieldErrors.Add("archived", err)
+		}
+	}
+
 	if fieldErrors != nil {
 		return productbus.QueryFilter{}, fieldErrors.ToError()
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/patch"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
@@ -16,13 +17,14 @@ import (
 
 // Product represents information about an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID           string  `json:"id"`
+	UserID       string  `json:"userID"`
+	Name         string  `json:"name"`
+	Cost         float64 `json:"cost"`
+	Quantity     int     `json:"quantity"`
+	DateCreated  string  `json:"dateCreated"`
+	DateUpdated  string  `json:"dateUpdated"`
+	DateArchived string  `json:"dateArchived,omitempty"`
 }
 
 // Encode implements the encoder interface.
@@ -33,13 +35,14 @@ func (app Product) Encode() ([]byte, string, error) {
 
 func toAppProduct(prd productbus.Product) Product {
 	return Product{
-		ID:          prd.ID.String(),
-		UserID:      prd.UserID.String(),
-		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
-		Quantity:    prd.Quantity.Value(),
-		DateCreated: prd.DateCreated.Format(time.RFC3339),
-		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+		ID:           prd.ID.String(),
+		UserID:       prd.UserID.String(),
+		Name:         prd.Name.String(),
+		Cost:         prd.Cost.Value(),
+		Quantity:     prd.Quantity.Value(),
+		DateCreated:  prd.DateCreated.Format(time.RFC3339),
+		DateUpdated:  prd.DateUpdated.Format(time.RFC3339),
+		DateArchived: formatTime(prd.DateArchived),
 	}
 }
 
@@ -52,6 +55,14 @@ func toAppProducts(prds []productbus.Product) []Product {
 	return app
 }
 
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
 // =============================================================================
 
 // NewProduct defines the data needed to add a new product.
@@ -165,3 +176,44 @@ func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// patchProduct is the document a patch is applied to. It holds the current
+// state of the product, so the patched document has to keep every field.
+type patchProduct struct {
+	Name     *string  `json:"name" validate:"required"`
+	Cost     *float64 `json:"cost" validate:"required,gte=0"`
+	Quantity *int     `json:"quantity" validate:"required,gte=1"`
+}
+
+func toPatchProduct(prd productbus.Product) patchProduct {
+	name := prd.Name.String()
+	cost := prd.Cost.Value()
+	quantity := prd.Quantity.Value()
+
+	return patchProduct{
+		Name:     &name,
+		Cost:     &cost,
+		Quantity: &quantity,
+	}
+}
+
+func toBusPatchProduct(data []byte) (productbus.UpdateProduct, error) {
+	var app patchProduct
+	if err := patch.Decode(data, &app); err != nil {
+		return productbus.UpdateProduct{}, fmt.Errorf("validate: %w", err)
+	}
+
+	if err := errs.Check(app); err != nil {
+		return productbus.UpdateProduct{}, fmt.Errorf("validate: %w", err)
+	}
+
+	up := UpdateProduct{
+		Name:     app.Name,
+		Cost:     app.Cost,
+		Quantity: app.Quantity,
+	}
+
+	return toBusUpdateProduct(up)
+}
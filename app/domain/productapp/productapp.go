@@ -3,15 +3,20 @@ package productapp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/patch"
 	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
 )
 
 type app struct {
@@ -24,6 +29,26 @@ func newApp(productBus *productbus.Business) *app {
 	}
 }
 
+// newWithTx constructs a new app value with the domain api
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	productBus, err := a.productBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		productBus: productBus,
+	}
+
+	return &app, nil
+}
+
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 	var app NewProduct
 	if err := web.Decode(r, &app); err != nil {
@@ -35,11 +60,18 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	prd, err := a.productBus.Create(ctx, np)
 	if err != nil {
 		return errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
 	}
 
+	etag.Set(ctx, prd.Version)
+
 	return toAppProduct(prd)
 }
 
@@ -59,21 +91,101 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, prd.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	updPrd, err := a.productBus.Update(ctx, prd, up)
 	if err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, productbus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, app, err)
 	}
 
+	etag.Set(ctx, updPrd.Version)
+
 	return toAppProduct(updPrd)
 }
 
-func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
+// patch applies a merge patch or a JSON patch to the product. The patch is
+// applied to the current state of the product and the result is validated
+// like a full document.
+func (a *app) patch(ctx context.Context, r *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	if err := etag.Match(r, prd.Version); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(toPatchProduct(prd))
+	if err != nil {
+		return errs.Newf(errs.Internal, "marshal: productID[%s]: %s", prd.ID, err)
+	}
+
+	doc, err = patch.Apply(r, doc)
+	if err != nil {
+		switch {
+		case errors.Is(err, patch.ErrTestFailed):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, patch.ErrUnsupportedType):
+			return errs.New(errs.UnsupportedMediaType, err)
+		case errors.Is(err, patch.ErrTooLarge):
+			return errs.New(errs.PayloadTooLarge, err)
+		}
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	up, err := toBusPatchProduct(doc)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	updPrd, err := a.productBus.Update(ctx, prd, up)
+	if err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, productbus.ErrVersionConflict)
+		}
+		return errs.Newf(errs.Internal, "patch: productID[%s]: %s", prd.ID, err)
+	}
+
+	etag.Set(ctx, updPrd.Version)
+
+	return toAppProduct(updPrd)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
 	prd, err := mid.GetProduct(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "productID missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, prd.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	if err := a.productBus.Delete(ctx, prd); err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, productbus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
 	}
 
@@ -93,6 +205,12 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return err.(*errs.Error)
 	}
 
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	filter.OrgID = &tenant
+
 	orderBy, err := order.Parse(orderByFields, qp.OrderBy, productbus.DefaultOrderBy)
 	if err != nil {
 		return errs.NewFieldErrors("order", err)
@@ -117,5 +235,43 @@ func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
+	etag.Set(ctx, prd.Version)
+
+	return toAppProduct(prd)
+}
+
+// restore brings back a deleted product. It runs inside a transaction scoped
+// to the caller's tenant so a product from another organisation can't be
+// restored.
+func (a *app) restore(ctx context.Context, r *http.Request) web.Encoder {
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return errs.NewFieldErrors("product_id", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	prd, err := a.productBus.Restore(ctx, productID)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "restore: productID[%s]: %s", productID, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if prd.OrgID != tenant {
+		return errs.New(errs.NotFound, productbus.ErrNotFound)
+	}
+
+	etag.Set(ctx, prd.Version)
+
 	return toAppProduct(prd)
 }
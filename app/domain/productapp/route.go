@@ -7,13 +7,16 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log        *logger.Logger
+	DB         *sqlx.DB
 	ProductBus *productbus.Business
 	AuthClient *authclient.Client
 }
@@ -23,15 +26,19 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
-	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+	rulePermission := mid.AuthorizePermission(cfg.AuthClient, "product")
 	ruleAuthorizeProduct := mid.AuthorizeProduct(cfg.AuthClient, cfg.ProductBus)
 
 	api := newApp(cfg.ProductBus)
 
 	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, ruleAny)
 	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, rulePermission, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, rulePermission, ruleAuthorizeProduct, transaction)
+	app.HandlerFunc(http.MethodPatch, version, "/products/{product_id}", api.patch, authen, rulePermission, ruleAuthorizeProduct, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, rulePermission, ruleAuthorizeProduct, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/products/restore/{product_id}", api.restore, authen, ruleAdmin, transaction)
 }
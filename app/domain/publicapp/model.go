@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/mfabus"
 	"github.com/ardanlabs/service/business/domain/publicuesrbus"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/role"
@@ -14,15 +15,16 @@ import (
 
 // User represents information about an individual user.
 type User struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Email        string   `json:"email"`
-	Roles        []string `json:"roles"`
-	PasswordHash []byte   `json:"-"`
-	Department   string   `json:"department"`
-	Enabled      bool     `json:"enabled"`
-	DateCreated  string   `json:"dateCreated"`
-	DateUpdated  string   `json:"dateUpdated"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	Roles         []string `json:"roles"`
+	PasswordHash  []byte   `json:"-"`
+	Department    string   `json:"department"`
+	Enabled       bool     `json:"enabled"`
+	EmailVerified bool     `json:"emailVerified"`
+	DateCreated   string   `json:"dateCreated"`
+	DateUpdated   string   `json:"dateUpdated"`
 }
 
 // Encode implements the encoder interface.
@@ -33,15 +35,16 @@ func (app User) Encode() ([]byte, string, error) {
 
 func toAppUser(bus publicuesrbus.PublicUser) User {
 	return User{
-		ID:           bus.ID.String(),
-		Name:         bus.Name.String(),
-		Email:        bus.Email.Address,
-		Roles:        role.ParseToString(bus.Roles),
-		PasswordHash: bus.PasswordHash,
-		Department:   bus.Department.String(),
-		Enabled:      bus.Enabled,
-		DateCreated:  bus.DateCreated.Format(time.RFC3339),
-		DateUpdated:  bus.DateUpdated.Format(time.RFC3339),
+		ID:            bus.ID.String(),
+		Name:          bus.Name.String(),
+		Email:         bus.Email.Address,
+		Roles:         role.ParseToString(bus.Roles),
+		PasswordHash:  bus.PasswordHash,
+		Department:    bus.Department.String(),
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		DateCreated:   bus.DateCreated.Format(time.RFC3339),
+		DateUpdated:   bus.DateUpdated.Format(time.RFC3339),
 	}
 }
 
@@ -116,7 +119,9 @@ func (log *LoginRequest) Decode(data []byte) error {
 
 // LoginResponse contains the response data for login.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresAt    string `json:"expiresAt"`
 }
 
 // Encode implements the encoder interface.
@@ -124,3 +129,180 @@ func (res LoginResponse) Encode() ([]byte, string, error) {
 	data, err := json.Marshal(res)
 	return data, "application/json", err
 }
+
+// =============================================================================
+
+// RefreshRequest contains information needed to refresh an access token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (req *RefreshRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req RefreshRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// LogoutRequest contains the optional refresh token to revoke on logout.
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Decode implements the decoder interface.
+func (req *LogoutRequest) Decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, req)
+}
+
+// =============================================================================
+
+// VerifyEmailRequest contains the token needed to verify an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (req *VerifyEmailRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req VerifyEmailRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// ForgotPasswordRequest contains the email of the account to recover.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Decode implements the decoder interface.
+func (req *ForgotPasswordRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req ForgotPasswordRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPasswordRequest contains information needed to reset a password.
+type ResetPasswordRequest struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,min=8"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"required,eqfield=Password"`
+}
+
+// Decode implements the decoder interface.
+func (req *ResetPasswordRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req ResetPasswordRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// MFAChallenge is returned by login in place of the tokens when the user has
+// multi-factor authentication enabled. The token is exchanged along with a
+// code for the access and refresh tokens.
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+// Encode implements the encoder interface.
+func (res MFAChallenge) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(res)
+	return data, "application/json", err
+}
+
+// MFALoginRequest contains the information needed to complete a login with
+// a second factor. The code can be a TOTP code or a recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (req *MFALoginRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req MFALoginRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// MFACodeRequest contains a code used to confirm or disable the second
+// factor.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (req *MFACodeRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, req)
+}
+
+// Validate checks the data in the model is considered clean.
+func (req MFACodeRequest) Validate() error {
+	if err := errs.Check(req); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// MFAEnrollment contains what the user needs to set up their authenticator
+// application. The URI is meant to be rendered as a QR code. The secret and
+// recovery codes are only ever shown once.
+type MFAEnrollment struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Encode implements the encoder interface.
+func (res MFAEnrollment) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(res)
+	return data, "application/json", err
+}
+
+func toAppMFAEnrollment(bus mfabus.Enrollment) MFAEnrollment {
+	return MFAEnrollment{
+		Secret:        bus.Secret,
+		URI:           bus.URI,
+		RecoveryCodes: bus.RecoveryCodes,
+	}
+}
@@ -3,34 +3,90 @@ package publicapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
 	"github.com/ardanlabs/service/business/domain/publicuesrbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/mailer"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Default lifetimes used when the configuration doesn't provide them.
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// Lifetimes of the single use tokens sent by email or handed out during a
+// login that requires a second factor.
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	passwordResetTTL = time.Hour
+	mfaChallengeTTL  = 5 * time.Minute
 )
 
 type app struct {
-	auth     *auth.Auth
-	pbusrbus *publicuesrbus.Business
-	kid      string
+	auth       *auth.Auth
+	beginner   sqldb.Beginner
+	pbusrbus   *publicuesrbus.Business
+	userBus    *userbus.Business
+	tokenBus   *tokenbus.Business
+	verifyBus  *verifybus.Business
+	lockoutBus *lockoutbus.Business
+	mfaBus     *mfabus.Business
+	sessionBus *sessionbus.Business
+	mailer     mailer.Mailer
+	appURL     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
-func newApp(auth *auth.Auth, pbusrbus *publicuesrbus.Business, kid string) *app {
+func newApp(cfg Config) *app {
+	accessTTL := cfg.AccessTTL
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTTL
+	}
+
+	refreshTTL := cfg.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTTL
+	}
+
 	return &app{
-		auth:     auth,
-		pbusrbus: pbusrbus,
-		kid:      kid,
+		auth:       cfg.Auth,
+		beginner:   sqldb.NewBeginner(cfg.DB),
+		pbusrbus:   cfg.PublicNewUserBus,
+		userBus:    cfg.UserBus,
+		tokenBus:   cfg.TokenBus,
+		verifyBus:  cfg.VerifyBus,
+		lockoutBus: cfg.LockoutBus,
+		mfaBus:     cfg.MFABus,
+		sessionBus: cfg.SessionBus,
+		mailer:     cfg.Mailer,
+		appURL:     strings.TrimSuffix(cfg.AppURL, "/"),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
 	}
 }
 
-// register handles creating a new user in the system.
+// register handles creating a new user in the system. An email is sent so
+// they can verify their address, which they must do before they can log in.
 func (a *app) register(ctx context.Context, r *http.Request) web.Encoder {
 	var req RegisterRequest
 	if err := web.Decode(r, &req); err != nil {
@@ -42,7 +98,7 @@ func (a *app) register(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	usr, err := a.pbusrbus.Create(ctx, nu)
+	usr, err := a.create(ctx, nu)
 	if err != nil {
 		if errors.Is(err, userbus.ErrUniqueEmail) {
 			return errs.NewFieldErrors("email", errors.New("email already exists"))
@@ -50,29 +106,287 @@ func (a *app) register(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.Internal, err)
 	}
 
-	claims := auth.Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
-			Issuer:    a.auth.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
-		Roles: role.ParseToString(usr.Roles),
+	return toAppUser(usr)
+}
+
+// login authenticates a user and provides a JWT token. When the user has
+// multi-factor authentication enabled a short lived challenge token is
+// returned instead, which must be exchanged along with a code at loginMFA.
+func (a *app) login(ctx context.Context, r *http.Request) web.Encoder {
+	var req LoginRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return errs.NewFieldErrors("email", errors.New("invalid email format"))
+	}
+
+	// Reject the attempt if the email or client are locked out.
+	emailKey := lockoutbus.EmailKey(*addr)
+	ipKey := lockoutbus.IPKey(mid.ClientIP(ctx, r))
+
+	if err := a.lockoutBus.Check(ctx, emailKey, ipKey); err != nil {
+		if errors.Is(err, lockoutbus.ErrLocked) {
+			return mid.LockoutError(ctx, err)
+		}
+		return errs.Newf(errs.Internal, "lockout check: %s", err)
+	}
+
+	// Authenticate the user
+	usr, err := a.pbusrbus.Authenticate(ctx, *addr, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, publicuesrbus.ErrUserDisabled), errors.Is(err, publicuesrbus.ErrEmailNotVerified):
+			return errs.New(errs.Unauthenticated, errors.New("email not verified or user disabled"))
+
+		case errors.Is(err, publicuesrbus.ErrNotFound), errors.Is(err, publicuesrbus.ErrAuthenticationFailure):
+			if err := a.lockoutBus.Fail(ctx, emailKey, ipKey); err != nil {
+				return errs.Newf(errs.Internal, "lockout fail: %s", err)
+			}
+		}
+		return errs.New(errs.Unauthenticated, errors.New("invalid email or password"))
+	}
+
+	mfaEnabled, err := a.mfaBus.Enabled(ctx, usr.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "mfa enabled: %s", err)
+	}
+
+	// The lockout isn't reset until the second factor is verified so the
+	// password can't be used to keep guessing codes.
+	if mfaEnabled {
+		raw, tkn, err := a.verifyBus.Create(ctx, usr.ID, verifybus.PurposeMFAChallenge, mfaChallengeTTL)
+		if err != nil {
+			return errs.Newf(errs.Internal, "create: %s", err)
+		}
+
+		resp := MFAChallenge{
+			MFARequired: true,
+			MFAToken:    raw,
+			ExpiresAt:   tkn.DateExpires.Format(time.RFC3339),
+		}
+
+		return resp
 	}
 
-	token, err := a.auth.GenerateToken(a.kid, claims)
+	if err := a.lockoutBus.Reset(ctx, emailKey); err != nil {
+		return errs.Newf(errs.Internal, "lockout reset: %s", err)
+	}
+
+	resp, err := a.generateTokens(ctx, mid.SessionClient(ctx, r), usr.ID, usr.OrgID, role.ParseToString(usr.Roles), false)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
-	_ = toAppUser(usr)
+	return resp
+}
+
+// loginMFA completes a login for a user with multi-factor authentication
+// enabled. The challenge token is single use, so a wrong code requires the
+// user to sign in with their password again.
+func (a *app) loginMFA(ctx context.Context, r *http.Request) web.Encoder {
+	var req MFALoginRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tkn, err := a.verifyBus.Consume(ctx, req.MFAToken, verifybus.PurposeMFAChallenge)
+	if err != nil {
+		if isInvalidToken(err) {
+			return errs.New(errs.Unauthenticated, errors.New("invalid or expired mfa token"))
+		}
+		return errs.Newf(errs.Internal, "consume: %s", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", tkn.UserID, err)
+	}
 
-	return LoginResponse{Token: token}
+	if !usr.Enabled || !usr.EmailVerified {
+		return errs.New(errs.Unauthenticated, errors.New("email not verified or user disabled"))
+	}
+
+	emailKey := lockoutbus.EmailKey(usr.Email)
+	ipKey := lockoutbus.IPKey(mid.ClientIP(ctx, r))
+
+	if err := a.lockoutBus.Check(ctx, emailKey, ipKey); err != nil {
+		if errors.Is(err, lockoutbus.ErrLocked) {
+			return mid.LockoutError(ctx, err)
+		}
+		return errs.Newf(errs.Internal, "lockout check: %s", err)
+	}
+
+	if err := a.mfaBus.Verify(ctx, usr.ID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, mfabus.ErrInvalidCode):
+			if err := a.lockoutBus.Fail(ctx, emailKey, ipKey); err != nil {
+				return errs.Newf(errs.Internal, "lockout fail: %s", err)
+			}
+			return errs.New(errs.Unauthenticated, errors.New("invalid mfa code"))
+
+		case errors.Is(err, mfabus.ErrNotEnabled):
+			return errs.New(errs.Unauthenticated, errors.New("mfa not enabled"))
+		}
+		return errs.Newf(errs.Internal, "verify: %s", err)
+	}
+
+	if err := a.lockoutBus.Reset(ctx, emailKey); err != nil {
+		return errs.Newf(errs.Internal, "lockout reset: %s", err)
+	}
+
+	resp, err := a.generateTokens(ctx, mid.SessionClient(ctx, r), usr.ID, usr.OrgID, role.ParseToString(usr.Roles), true)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return resp
 }
 
-// login authenticates a user and provides a JWT token.
-func (a *app) login(ctx context.Context, r *http.Request) web.Encoder {
-	var req LoginRequest
+// refresh exchanges a refresh token for a new access and refresh token.
+func (a *app) refresh(ctx context.Context, r *http.Request) web.Encoder {
+	var req RefreshRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	refreshToken, rt, err := a.rotate(ctx, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, tokenbus.ErrNotFound),
+			errors.Is(err, tokenbus.ErrExpired),
+			errors.Is(err, tokenbus.ErrRevoked):
+			return errs.New(errs.Unauthenticated, errors.New("invalid refresh token"))
+		}
+		return errs.Newf(errs.Internal, "rotate: %s", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, rt.UserID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.New(errs.Unauthenticated, errors.New("invalid refresh token"))
+		}
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", rt.UserID, err)
+	}
+
+	if !usr.Enabled {
+		if err := a.tokenBus.RevokeUser(ctx, usr.ID); err != nil {
+			return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", usr.ID, err)
+		}
+		return errs.New(errs.Unauthenticated, errors.New("user disabled"))
+	}
+
+	token, claims, err := a.generateAccessToken(ctx, usr.ID, usr.OrgID, role.ParseToString(usr.Roles), rt.MFA)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// The session started at login moves on to the new access token. Token
+	// families started before sessions were tracked get a new session.
+	client := mid.SessionClient(ctx, r)
+
+	if _, err := a.sessionBus.Refresh(ctx, rt.FamilyID, claims.ID, rt.DateExpires, client); err != nil {
+		if !errors.Is(err, sessionbus.ErrNotFound) {
+			return errs.Newf(errs.Internal, "refresh session: %s", err)
+		}
+
+		if err := a.startSession(ctx, client, usr.ID, claims.ID, rt); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	resp := LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    claims.ExpiresAt.Time.Format(time.RFC3339),
+	}
+
+	return resp
+}
+
+// logout revokes the access token used to make the call and, when provided,
+// the refresh token family issued alongside it. A refresh token that isn't
+// known or wasn't issued to the caller is ignored.
+func (a *app) logout(ctx context.Context, r *http.Request) web.Encoder {
+	var req LogoutRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	claims := mid.GetClaims(ctx)
+
+	if claims.ID != "" {
+		ses, err := a.sessionBus.QueryByTokenID(ctx, claims.ID)
+		switch {
+		case err == nil:
+			if err := a.sessionBus.Revoke(ctx, ses); err != nil {
+				return errs.Newf(errs.Internal, "revoke session: %s", err)
+			}
+
+		case errors.Is(err, sessionbus.ErrNotFound):
+			expires := time.Now().Add(a.accessTTL)
+			if claims.ExpiresAt != nil {
+				expires = claims.ExpiresAt.Time
+			}
+
+			if err := a.tokenBus.RevokeAccess(ctx, claims.ID, expires); err != nil {
+				return errs.Newf(errs.Internal, "revokeaccess: %s", err)
+			}
+
+		default:
+			return errs.Newf(errs.Internal, "querybytokenid: %s", err)
+		}
+	}
+
+	if req.RefreshToken != "" {
+		userID, err := mid.GetUserID(ctx)
+		if err != nil {
+			return errs.New(errs.Unauthenticated, err)
+		}
+
+		if err := a.tokenBus.RevokeRefresh(ctx, userID, req.RefreshToken); err != nil {
+			return errs.Newf(errs.Internal, "revokerefresh: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// verifyEmail consumes an email verification token and marks the email of
+// the user as verified. It doesn't enable a user that has been disabled.
+func (a *app) verifyEmail(ctx context.Context, r *http.Request) web.Encoder {
+	var req VerifyEmailRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	tkn, err := a.verifyBus.Consume(ctx, req.Token, verifybus.PurposeEmailVerify)
+	if err != nil {
+		if isInvalidToken(err) {
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired token"))
+		}
+		return errs.Newf(errs.Internal, "consume: %s", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", tkn.UserID, err)
+	}
+
+	verified := true
+	if _, err := a.userBus.Update(ctx, usr, userbus.UpdateUser{EmailVerified: &verified}); err != nil {
+		return errs.Newf(errs.Internal, "update: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
+}
+
+// forgotPassword emails a password reset token to the user. The response is
+// the same whether the email exists or not so accounts can't be enumerated.
+func (a *app) forgotPassword(ctx context.Context, r *http.Request) web.Encoder {
+	var req ForgotPasswordRequest
 	if err := web.Decode(r, &req); err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
@@ -82,26 +396,388 @@ func (a *app) login(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.NewFieldErrors("email", errors.New("invalid email format"))
 	}
 
-	// Authenticate the user
-	usr, err := a.pbusrbus.Authenticate(ctx, *addr, req.Password)
+	usr, err := a.pbusrbus.QueryByEmail(ctx, *addr)
 	if err != nil {
-		return errs.New(errs.Unauthenticated, errors.New("invalid email or password"))
+		if errors.Is(err, publicuesrbus.ErrNotFound) || errors.Is(err, userbus.ErrNotFound) {
+			return nil
+		}
+		return errs.Newf(errs.Internal, "querybyemail: %s", err)
+	}
+
+	raw, _, err := a.verifyBus.Create(ctx, usr.ID, verifybus.PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return errs.Newf(errs.Internal, "create: %s", err)
+	}
+
+	msg := mailer.Message{
+		To:      usr.Email.Address,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use the link below to reset your password. The link expires in %s.\n\n%s/password/reset?token=%s\n", passwordResetTTL, a.appURL, raw),
 	}
 
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return errs.Newf(errs.Internal, "send: %s", err)
+	}
+
+	return nil
+}
+
+// resetPassword consumes a password reset token and sets the new password.
+// Every session of the user is revoked so existing access and refresh tokens
+// stop working, and the lockout on the email is cleared.
+func (a *app) resetPassword(ctx context.Context, r *http.Request) web.Encoder {
+	var req ResetPasswordRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.reset(ctx, req); err != nil {
+		if isInvalidToken(err) {
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired token"))
+		}
+		return errs.Newf(errs.Internal, "reset: %s", err)
+	}
+
+	return nil
+}
+
+// enrollMFA starts multi-factor enrolment for the calling user. The second
+// factor isn't required at login until the enrolment is confirmed.
+func (a *app) enrollMFA(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.authorizeMFA(ctx, userID, auth.ActionCreate); err != nil {
+		return err
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	enr, err := a.mfaBus.Enroll(ctx, usr.ID, usr.Email.Address)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfabus.ErrAlreadyEnabled):
+			return errs.New(errs.FailedPrecondition, mfabus.ErrAlreadyEnabled)
+
+		case errors.Is(err, mfabus.ErrUnavailable):
+			return errs.New(errs.Unimplemented, mfabus.ErrUnavailable)
+		}
+		return errs.Newf(errs.Internal, "enroll: %s", err)
+	}
+
+	return toAppMFAEnrollment(enr)
+}
+
+// confirmMFA enables the second factor for the calling user once they
+// provide a valid code from their authenticator application.
+func (a *app) confirmMFA(ctx context.Context, r *http.Request) web.Encoder {
+	var req MFACodeRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.authorizeMFA(ctx, userID, auth.ActionUpdate); err != nil {
+		return err
+	}
+
+	if err := a.mfaBus.Confirm(ctx, userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, mfabus.ErrNotFound):
+			return errs.New(errs.FailedPrecondition, errors.New("mfa enrolment not started"))
+
+		case errors.Is(err, mfabus.ErrAlreadyEnabled):
+			return errs.New(errs.FailedPrecondition, mfabus.ErrAlreadyEnabled)
+
+		case errors.Is(err, mfabus.ErrInvalidCode):
+			return errs.New(errs.InvalidArgument, mfabus.ErrInvalidCode)
+		}
+		return errs.Newf(errs.Internal, "confirm: %s", err)
+	}
+
+	return nil
+}
+
+// disableMFA removes the second factor for the calling user. A valid code
+// or recovery code is required.
+func (a *app) disableMFA(ctx context.Context, r *http.Request) web.Encoder {
+	var req MFACodeRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.authorizeMFA(ctx, userID, auth.ActionDelete); err != nil {
+		return err
+	}
+
+	if err := a.mfaBus.Disable(ctx, userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, mfabus.ErrNotEnabled):
+			return errs.New(errs.FailedPrecondition, mfabus.ErrNotEnabled)
+
+		case errors.Is(err, mfabus.ErrInvalidCode):
+			return errs.New(errs.InvalidArgument, mfabus.ErrInvalidCode)
+		}
+		return errs.Newf(errs.Internal, "disable: %s", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// authorizeMFA asks the policy if the caller can change their own second
+// factor, which isn't allowed with an impersonation token.
+func (a *app) authorizeMFA(ctx context.Context, userID uuid.UUID, action string) *errs.Error {
+	claims := mid.GetClaims(ctx)
+
+	resource := auth.Resource{
+		Type:   "mfa",
+		Action: action,
+	}
+
+	if err := a.auth.AuthorizeResource(ctx, claims, userID, auth.RuleAdminOrSubject, resource); err != nil {
+		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, auth.RuleAdminOrSubject, err)
+	}
+
+	return nil
+}
+
+func (a *app) sendVerifyEmail(ctx context.Context, verifyBus *verifybus.Business, userID uuid.UUID, addr mail.Address) error {
+	raw, _, err := verifyBus.Create(ctx, userID, verifybus.PurposeEmailVerify, verifyEmailTTL)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      addr.Address,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Use the link below to verify your email address. The link expires in %s.\n\n%s/verify-email?token=%s\n", verifyEmailTTL, a.appURL, raw),
+	}
+
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	return nil
+}
+
+// create adds the user in a transaction with the verification token, which
+// is only committed once the email is sent. If the email can't be sent the
+// user isn't left behind, so registering again doesn't fail on the email.
+func (a *app) create(ctx context.Context, nu publicuesrbus.NewPublicUser) (publicuesrbus.PublicUser, error) {
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return publicuesrbus.PublicUser{}, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	pbusrbus, err := a.pbusrbus.NewWithTx(tx)
+	if err != nil {
+		return publicuesrbus.PublicUser{}, fmt.Errorf("newwithtx: %w", err)
+	}
+
+	verifyBus, err := a.verifyBus.NewWithTx(tx)
+	if err != nil {
+		return publicuesrbus.PublicUser{}, fmt.Errorf("newwithtx: %w", err)
+	}
+
+	usr, err := pbusrbus.Create(ctx, nu)
+	if err != nil {
+		return publicuesrbus.PublicUser{}, err
+	}
+
+	if err := a.sendVerifyEmail(ctx, verifyBus, usr.ID, usr.Email); err != nil {
+		return publicuesrbus.PublicUser{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return publicuesrbus.PublicUser{}, fmt.Errorf("commit: %w", err)
+	}
+
+	return usr, nil
+}
+
+// rotate exchanges the refresh token in a transaction so the token is never
+// revoked without its replacement being stored. The transaction is committed
+// when the token was reused as well, so the family stays revoked.
+func (a *app) rotate(ctx context.Context, token string) (string, tokenbus.RefreshToken, error) {
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return "", tokenbus.RefreshToken{}, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	tokenBus, err := a.tokenBus.NewWithTx(tx)
+	if err != nil {
+		return "", tokenbus.RefreshToken{}, fmt.Errorf("newwithtx: %w", err)
+	}
+
+	raw, rt, err := tokenBus.Rotate(ctx, token, a.refreshTTL)
+	if err != nil && !errors.Is(err, tokenbus.ErrRevoked) {
+		return "", tokenbus.RefreshToken{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", tokenbus.RefreshToken{}, fmt.Errorf("commit: %w", err)
+	}
+
+	return raw, rt, err
+}
+
+// reset changes the password in a transaction with consuming the token,
+// signing the user out everywhere and clearing the lockout on the email, so
+// the token can't be used again unless every step happened.
+func (a *app) reset(ctx context.Context, req ResetPasswordRequest) error {
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	verifyBus, err := a.verifyBus.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("newwithtx: %w", err)
+	}
+
+	userBus, err := a.userBus.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("newwithtx: %w", err)
+	}
+
+	sessionBus, err := a.sessionBus.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("newwithtx: %w", err)
+	}
+
+	lockoutBus, err := a.lockoutBus.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("newwithtx: %w", err)
+	}
+
+	tkn, err := verifyBus.Consume(ctx, req.Token, verifybus.PurposePasswordReset)
+	if err != nil {
+		return fmt.Errorf("consume: %w", err)
+	}
+
+	usr, err := userBus.QueryByID(ctx, tkn.UserID)
+	if err != nil {
+		return fmt.Errorf("querybyid: userID[%s]: %w", tkn.UserID, err)
+	}
+
+	if _, err := userBus.Update(ctx, usr, userbus.UpdateUser{Password: &req.Password}); err != nil {
+		return fmt.Errorf("update: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := sessionBus.RevokeUser(ctx, usr.ID); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := lockoutBus.Reset(ctx, lockoutbus.EmailKey(usr.Email)); err != nil {
+		return fmt.Errorf("reset: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func isInvalidToken(err error) bool {
+	return errors.Is(err, verifybus.ErrNotFound) ||
+		errors.Is(err, verifybus.ErrExpired) ||
+		errors.Is(err, verifybus.ErrUsed)
+}
+
+func (a *app) generateTokens(ctx context.Context, client sessionbus.Client, userID uuid.UUID, orgID uuid.UUID, roles []string, mfa bool) (LoginResponse, error) {
+	token, claims, err := a.generateAccessToken(ctx, userID, orgID, roles, mfa)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	createRefresh := a.tokenBus.CreateRefresh
+	if mfa {
+		createRefresh = a.tokenBus.CreateMFARefresh
+	}
+
+	refreshToken, rt, err := createRefresh(ctx, userID, a.refreshTTL)
+	if err != nil {
+		return LoginResponse{}, fmt.Errorf("createrefresh: %w", err)
+	}
+
+	if err := a.startSession(ctx, client, userID, claims.ID, rt); err != nil {
+		return LoginResponse{}, err
+	}
+
+	resp := LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    claims.ExpiresAt.Time.Format(time.RFC3339),
+	}
+
+	return resp, nil
+}
+
+// startSession records the session for an access token and the refresh token
+// family issued alongside it. The session lasts as long as the family.
+func (a *app) startSession(ctx context.Context, client sessionbus.Client, userID uuid.UUID, tokenID string, rt tokenbus.RefreshToken) error {
+	ns := sessionbus.NewSession{
+		UserID:      userID,
+		TokenID:     tokenID,
+		FamilyID:    rt.FamilyID,
+		Client:      client,
+		DateExpires: rt.DateExpires,
+	}
+
+	if _, err := a.sessionBus.Create(ctx, ns); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+
+	return nil
+}
+
+func (a *app) generateAccessToken(ctx context.Context, userID uuid.UUID, orgID uuid.UUID, roles []string, mfa bool) (string, auth.Claims, error) {
+	perms, err := a.auth.Permissions(ctx, userID, roles)
+	if err != nil {
+		return "", auth.Claims{}, err
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(a.accessTTL)
+
 	claims := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
 			Issuer:    a.auth.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		Roles: role.ParseToString(usr.Roles),
+		Roles:       roles,
+		Permissions: perms,
+		Tenant:      orgID.String(),
+		MFA:         mfa,
 	}
 
-	token, err := a.auth.GenerateToken(a.kid, claims)
+	token, err := a.auth.GenerateToken(a.auth.ActiveKID(), claims)
 	if err != nil {
-		return errs.New(errs.Internal, err)
+		return "", auth.Claims{}, fmt.Errorf("generatetoken: %w", err)
 	}
 
-	return LoginResponse{Token: token}
+	return token, claims, nil
 }
@@ -2,25 +2,56 @@ package publicapp
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
 	"github.com/ardanlabs/service/business/domain/publicuesrbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/foundation/mailer"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Auth             *auth.Auth
+	DB               *sqlx.DB
 	PublicNewUserBus *publicuesrbus.Business
-	Kid              string
+	UserBus          *userbus.Business
+	TokenBus         *tokenbus.Business
+	VerifyBus        *verifybus.Business
+	LockoutBus       *lockoutbus.Business
+	MFABus           *mfabus.Business
+	SessionBus       *sessionbus.Business
+	Mailer           mailer.Mailer
+	AppURL           string
+	AccessTTL        time.Duration
+	RefreshTTL       time.Duration
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
-	api := newApp(cfg.Auth, cfg.PublicNewUserBus, cfg.Kid)
+	bearer := mid.Bearer(cfg.Auth)
+
+	api := newApp(cfg)
 
 	app.HandlerFunc(http.MethodPost, version, "/register", api.register)
 	app.HandlerFunc(http.MethodPost, version, "/login", api.login)
+	app.HandlerFunc(http.MethodPost, version, "/login/mfa", api.loginMFA)
+	app.HandlerFunc(http.MethodPost, version, "/token/refresh", api.refresh)
+	app.HandlerFunc(http.MethodPost, version, "/logout", api.logout, bearer)
+	app.HandlerFunc(http.MethodPost, version, "/verify-email", api.verifyEmail)
+	app.HandlerFunc(http.MethodPost, version, "/password/forgot", api.forgotPassword)
+	app.HandlerFunc(http.MethodPost, version, "/password/reset", api.resetPassword)
+	app.HandlerFunc(http.MethodPost, version, "/mfa/enroll", api.enrollMFA, bearer)
+	app.HandlerFunc(http.MethodPost, version, "/mfa/confirm", api.confirmMFA, bearer)
+	app.HandlerFunc(http.MethodPost, version, "/mfa/disable", api.disableMFA, bearer)
 }
@@ -0,0 +1,57 @@
+package roleapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/types/permission"
+)
+
+type queryParams struct {
+	Page       string
+	Rows       string
+	OrderBy    string
+	Name       string
+	Permission string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:       values.Get("page"),
+		Rows:       values.Get("rows"),
+		OrderBy:    values.Get("orderBy"),
+		Name:       values.Get("name"),
+		Permission: values.Get("permission"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (rolebus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter rolebus.QueryFilter
+
+	if qp.Name != "" {
+		filter.Name = &qp.Name
+	}
+
+	if qp.Permission != "" {
+		p, err := permission.Parse(qp.Permission)
+		switch err {
+		case nil:
+			perm := p.String()
+			filter.Permission = &perm
+		default:
+			fieldErrors.Add("permission", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return rolebus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
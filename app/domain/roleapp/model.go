@@ -0,0 +1,164 @@
+package roleapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/types/permission"
+)
+
+// Role represents information about an individual role.
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"builtIn"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Role) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppRole(rle rolebus.Role) Role {
+	return Role{
+		Name:        rle.Name,
+		Description: rle.Description,
+		Permissions: permission.ParseToString(rle.Permissions),
+		BuiltIn:     rle.BuiltIn(),
+		DateCreated: rle.DateCreated.Format(time.RFC3339),
+		DateUpdated: rle.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppRoles(rles []rolebus.Role) []Role {
+	app := make([]Role, len(rles))
+	for i, rle := range rles {
+		app[i] = toAppRole(rle)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// Roles represents a set of roles.
+type Roles []Role
+
+// Encode implements the encoder interface.
+func (app Roles) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+var nameRegEx = regexp.MustCompile("^[A-Z][A-Z0-9_]{1,31}$")
+
+// NewRole defines the data needed to add a new role.
+type NewRole struct {
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description" validate:"required,max=200"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewRole) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewRole) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewRole(app NewRole) (rolebus.NewRole, error) {
+	if !nameRegEx.MatchString(app.Name) {
+		return rolebus.NewRole{}, fmt.Errorf("parse: invalid role name %q", app.Name)
+	}
+
+	perms, err := permission.ParseMany(app.Permissions)
+	if err != nil {
+		return rolebus.NewRole{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := rolebus.NewRole{
+		Name:        app.Name,
+		Description: app.Description,
+		Permissions: perms,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// UpdateRole defines the data needed to update a role.
+type UpdateRole struct {
+	Description *string  `json:"description" validate:"omitempty,max=200"`
+	Permissions []string `json:"permissions" validate:"omitempty,min=1"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateRole) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateRole) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateRole(app UpdateRole) (rolebus.UpdateRole, error) {
+	var perms []permission.Permission
+	if app.Permissions != nil {
+		var err error
+		perms, err = permission.ParseMany(app.Permissions)
+		if err != nil {
+			return rolebus.UpdateRole{}, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	bus := rolebus.UpdateRole{
+		Description: app.Description,
+		Permissions: perms,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Assignment defines the data needed to assign a role to a user.
+type Assignment struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *Assignment) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Assignment) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
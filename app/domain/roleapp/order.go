@@ -0,0 +1,10 @@
+package roleapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/rolebus"
+)
+
+var orderByFields = map[string]string{
+	"name":         rolebus.OrderByName,
+	"date_created": rolebus.OrderByDateCreated,
+}
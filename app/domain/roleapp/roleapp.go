@@ -0,0 +1,244 @@
+// Package roleapp maintains the app layer api for the role domain.
+package roleapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/permission"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type app struct {
+	roleBus *rolebus.Business
+	userBus *userbus.Business
+}
+
+func newApp(roleBus *rolebus.Business, userBus *userbus.Business) *app {
+	return &app{
+		roleBus: roleBus,
+		userBus: userBus,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewRole
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nr, err := toBusNewRole(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	rle, err := a.roleBus.Create(ctx, nr)
+	if err != nil {
+		if errors.Is(err, rolebus.ErrUniqueName) {
+			return errs.New(errs.Aborted, rolebus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "create: rle[%+v]: %s", nr, err)
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateRole
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ur, err := toBusUpdateRole(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	rle, err := a.queryRole(ctx, web.Param(r, "role_name"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	updRle, err := a.roleBus.Update(ctx, rle, ur)
+	if err != nil {
+		return errs.Newf(errs.Internal, "update: name[%s] ur[%+v]: %s", rle.Name, ur, err)
+	}
+
+	return toAppRole(updRle)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	rle, err := a.queryRole(ctx, web.Param(r, "role_name"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if err := a.roleBus.Delete(ctx, rle); err != nil {
+		if errors.Is(err, rolebus.ErrBuiltIn) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "delete: name[%s]: %s", rle.Name, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, rolebus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	rles, err := a.roleBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.roleBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppRoles(rles), total, page)
+}
+
+func (a *app) queryByName(ctx context.Context, r *http.Request) web.Encoder {
+	rle, err := a.queryRole(ctx, web.Param(r, "role_name"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) queryByUserID(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := a.queryUserID(ctx, r)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	rles, err := a.roleBus.QueryByUserID(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyuserid: userID[%s]: %s", userID, err)
+	}
+
+	return Roles(toAppRoles(rles))
+}
+
+func (a *app) assign(ctx context.Context, r *http.Request) web.Encoder {
+	var app Assignment
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := a.queryUserID(ctx, r)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	rle, err := a.queryRole(ctx, app.Role)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	// A caller can only hand out permissions they already hold, otherwise a
+	// tenant admin could assign themselves a platform role.
+	held, err := permission.ParseMany(mid.GetClaims(ctx).Permissions)
+	if err != nil {
+		return errs.Newf(errs.Internal, "parse claims permissions: %s", err)
+	}
+
+	if !permission.GrantsAll(held, rle.Permissions) {
+		return errs.Newf(errs.PermissionDenied, "role %q grants permissions the caller doesn't hold", rle.Name)
+	}
+
+	if err := a.roleBus.Assign(ctx, userID, rle); err != nil {
+		if errors.Is(err, rolebus.ErrBuiltIn) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "assign: userID[%s] name[%s]: %s", userID, rle.Name, err)
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) unassign(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := a.queryUserID(ctx, r)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	rle, err := a.queryRole(ctx, web.Param(r, "role_name"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if err := a.roleBus.Unassign(ctx, userID, rle); err != nil {
+		return errs.Newf(errs.Internal, "unassign: userID[%s] name[%s]: %s", userID, rle.Name, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+func (a *app) queryRole(ctx context.Context, name string) (rolebus.Role, error) {
+	rle, err := a.roleBus.QueryByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, rolebus.ErrNotFound) {
+			return rolebus.Role{}, errs.New(errs.NotFound, err)
+		}
+		return rolebus.Role{}, errs.Newf(errs.Internal, "querybyname: name[%s]: %s", name, err)
+	}
+
+	return rle, nil
+}
+
+func (a *app) queryUserID(ctx context.Context, r *http.Request) (uuid.UUID, error) {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return uuid.Nil, errs.NewFieldErrors("user_id", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return uuid.Nil, errs.New(errs.NotFound, err)
+		}
+		return uuid.Nil, errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return uuid.Nil, errs.New(errs.Unauthenticated, err)
+	}
+
+	// Users of another tenant are reported as not found so their existence
+	// isn't revealed.
+	if usr.OrgID != tenant {
+		return uuid.Nil, errs.New(errs.NotFound, userbus.ErrNotFound)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,40 @@
+package roleapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	RoleBus    *rolebus.Business
+	UserBus    *userbus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	rulePermission := mid.AuthorizePermission(cfg.AuthClient, "role")
+	ruleUserRole := mid.AuthorizePermission(cfg.AuthClient, "user_role")
+
+	api := newApp(cfg.RoleBus, cfg.UserBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/roles", api.query, authen, rulePermission)
+	app.HandlerFunc(http.MethodGet, version, "/roles/{role_name}", api.queryByName, authen, rulePermission)
+	app.HandlerFunc(http.MethodPost, version, "/roles", api.create, authen, rulePermission)
+	app.HandlerFunc(http.MethodPut, version, "/roles/{role_name}", api.update, authen, rulePermission)
+	app.HandlerFunc(http.MethodDelete, version, "/roles/{role_name}", api.delete, authen, rulePermission)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}/roles", api.queryByUserID, authen, ruleUserRole)
+	app.HandlerFunc(http.MethodPost, version, "/users/{user_id}/roles", api.assign, authen, ruleUserRole)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}/roles/{role_name}", api.unassign, authen, ruleUserRole)
+}
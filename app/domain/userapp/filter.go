@@ -99,3 +99,19 @@ func parseFilter(qp queryParams) (userbus.QueryFilter, error) {
 
 	return filter, nil
 }
+
+type sessionQueryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+}
+
+func parseSessionQueryParams(r *http.Request) sessionQueryParams {
+	values := r.URL.Query()
+
+	return sessionQueryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("orderBy"),
+	}
+}
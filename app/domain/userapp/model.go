@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/patch"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/role"
@@ -214,3 +216,102 @@ func toBusUpdateUser(app UpdateUser) (userbus.UpdateUser, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// Session represents a device the user is signed in on.
+type Session struct {
+	ID           string `json:"id"`
+	UserID       string `json:"userID"`
+	Device       string `json:"device"`
+	IPAddress    string `json:"ipAddress"`
+	UserAgent    string `json:"userAgent"`
+	DateCreated  string `json:"dateCreated"`
+	DateLastSeen string `json:"dateLastSeen"`
+	DateExpires  string `json:"dateExpires"`
+}
+
+// Encode implements the encoder interface.
+func (app Session) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSession(bus sessionbus.Session) Session {
+	return Session{
+		ID:           bus.ID.String(),
+		UserID:       bus.UserID.String(),
+		Device:       bus.Client.Device,
+		IPAddress:    bus.Client.IPAddress,
+		UserAgent:    bus.Client.UserAgent,
+		DateCreated:  bus.DateCreated.Format(time.RFC3339),
+		DateLastSeen: bus.DateLastSeen.Format(time.RFC3339),
+		DateExpires:  bus.DateExpires.Format(time.RFC3339),
+	}
+}
+
+func toAppSessions(sess []sessionbus.Session) []Session {
+	app := make([]Session, len(sess))
+	for i, ses := range sess {
+		app[i] = toAppSession(ses)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// patchUser is the document a patch is applied to. It holds the current
+// state of the user, so the patched document has to keep every required
+// field. Removing the department or setting it to null clears it. Passwords
+// and roles have their own endpoints and can't be patched.
+type patchUser struct {
+	Name       *string `json:"name" validate:"required"`
+	Email      *string `json:"email" validate:"required,email"`
+	Department *string `json:"department"`
+	Enabled    *bool   `json:"enabled" validate:"required"`
+}
+
+func toPatchUser(usr userbus.User) patchUser {
+	name := usr.Name.String()
+	email := usr.Email.Address
+	enabled := usr.Enabled
+
+	var department *string
+	if usr.Department.Valid() {
+		dep := usr.Department.String()
+		department = &dep
+	}
+
+	return patchUser{
+		Name:       &name,
+		Email:      &email,
+		Department: department,
+		Enabled:    &enabled,
+	}
+}
+
+func toBusPatchUser(data []byte) (userbus.UpdateUser, error) {
+	var app patchUser
+	if err := patch.Decode(data, &app); err != nil {
+		return userbus.UpdateUser{}, fmt.Errorf("validate: %w", err)
+	}
+
+	if err := errs.Check(app); err != nil {
+		return userbus.UpdateUser{}, fmt.Errorf("validate: %w", err)
+	}
+
+	department := app.Department
+	if department == nil {
+		department = new(string)
+	}
+
+	uu := UpdateUser{
+		Name:       app.Name,
+		Email:      app.Email,
+		Department: department,
+		Enabled:    app.Enabled,
+	}
+
+	return toBusUpdateUser(uu)
+}
@@ -1,6 +1,7 @@
 package userapp
 
 import (
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
 
@@ -11,3 +12,9 @@ var orderByFields = map[string]string{
 	"roles":   userbus.OrderByRoles,
 	"enabled": userbus.OrderByEnabled,
 }
+
+var sessionOrderByFields = map[string]string{
+	"session_id":     sessionbus.OrderByID,
+	"date_created":   sessionbus.OrderByDateCreated,
+	"date_last_seen": sessionbus.OrderByDateLastSeen,
+}
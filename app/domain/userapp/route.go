@@ -6,15 +6,22 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log        *logger.Logger
+	DB         *sqlx.DB
 	UserBus    *userbus.Business
+	LockoutBus *lockoutbus.Business
+	SessionBus *sessionbus.Business
 	AuthClient *authclient.Client
 }
 
@@ -23,16 +30,23 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
 	ruleAuthorizeUser := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOrSubject)
 	ruleAuthorizeAdmin := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOnly)
 
-	api := newApp(cfg.UserBus)
+	api := newApp(cfg.UserBus, cfg.LockoutBus, cfg.SessionBus)
 
 	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, ruleAdmin)
 	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/users/unlock/{user_id}", api.unlock, authen, ruleAuthorizeAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, ruleAuthorizeUser, transaction)
+	app.HandlerFunc(http.MethodPatch, version, "/users/{user_id}", api.patch, authen, ruleAuthorizeUser, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, ruleAuthorizeUser, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}/sessions", api.querySessions, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}/sessions", api.revokeSessions, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}/sessions/{session_id}", api.revokeSession, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPost, version, "/users/restore/{user_id}", api.restore, authen, ruleAdmin, transaction)
 }
@@ -3,28 +3,61 @@ package userapp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/patch"
 	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
 )
 
 type app struct {
-	userBus *userbus.Business
+	userBus    *userbus.Business
+	lockoutBus *lockoutbus.Business
+	sessionBus *sessionbus.Business
 }
 
-func newApp(userBus *userbus.Business) *app {
+func newApp(userBus *userbus.Business, lockoutBus *lockoutbus.Business, sessionBus *sessionbus.Business) *app {
 	return &app{
-		userBus: userBus,
+		userBus:    userBus,
+		lockoutBus: lockoutBus,
+		sessionBus: sessionBus,
 	}
 }
 
+// newWithTx constructs a new app value with the user api using a
+// store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := a.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		userBus:    userBus,
+		lockoutBus: a.lockoutBus,
+		sessionBus: a.sessionBus,
+	}
+
+	return &app, nil
+}
+
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 	var app NewUser
 	if err := web.Decode(r, &app); err != nil {
@@ -36,6 +69,18 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	// New users belong to the organisation of the admin creating them.
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	nc.OrgID = tenant
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	usr, err := a.userBus.Create(ctx, nc)
 	if err != nil {
 		if errors.Is(err, userbus.ErrUniqueEmail) {
@@ -44,6 +89,8 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
 	}
 
+	etag.Set(ctx, usr.Version)
+
 	return toAppUser(usr)
 }
 
@@ -63,11 +110,79 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "user missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, usr.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
+		if errors.Is(err, userbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, userbus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
 	}
 
+	etag.Set(ctx, updUsr.Version)
+
+	return toAppUser(updUsr)
+}
+
+// patch applies a merge patch or a JSON patch to the user. The patch is
+// applied to the current state of the user and the result is validated like
+// a full document.
+func (a *app) patch(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	if err := etag.Match(r, usr.Version); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(toPatchUser(usr))
+	if err != nil {
+		return errs.Newf(errs.Internal, "marshal: userID[%s]: %s", usr.ID, err)
+	}
+
+	doc, err = patch.Apply(r, doc)
+	if err != nil {
+		switch {
+		case errors.Is(err, patch.ErrTestFailed):
+			return errs.New(errs.Aborted, err)
+		case errors.Is(err, patch.ErrUnsupportedType):
+			return errs.New(errs.UnsupportedMediaType, err)
+		case errors.Is(err, patch.ErrTooLarge):
+			return errs.New(errs.PayloadTooLarge, err)
+		}
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	uu, err := toBusPatchUser(doc)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	updUsr, err := a.userBus.Update(ctx, usr, uu)
+	if err != nil {
+		if errors.Is(err, userbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, userbus.ErrVersionConflict)
+		}
+		return errs.Newf(errs.Internal, "patch: userID[%s]: %s", usr.ID, err)
+	}
+
+	etag.Set(ctx, updUsr.Version)
+
 	return toAppUser(updUsr)
 }
 
@@ -87,21 +202,62 @@ func (a *app) updateRole(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "user missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, usr.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
+		if errors.Is(err, userbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, userbus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "updaterole: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
 	}
 
+	etag.Set(ctx, updUsr.Version)
+
 	return toAppUser(updUsr)
 }
 
-func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
+// unlock clears the failed login attempts recorded against the user's email
+// so they can log in again before the lockout expires.
+func (a *app) unlock(ctx context.Context, _ *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	if err := a.lockoutBus.Reset(ctx, lockoutbus.EmailKey(usr.Email)); err != nil {
+		return errs.Newf(errs.Internal, "unlock: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
 	usr, err := mid.GetUser(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "userID missing in context: %s", err)
 	}
 
+	if err := etag.Match(r, usr.Version); err != nil {
+		return err
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	if err := a.userBus.Delete(ctx, usr); err != nil {
+		if errors.Is(err, userbus.ErrVersionConflict) {
+			return errs.New(errs.PreconditionFailed, userbus.ErrVersionConflict)
+		}
 		return errs.Newf(errs.Internal, "delete: userID[%s]: %s", usr.ID, err)
 	}
 
@@ -124,6 +280,12 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return err.(*errs.Error)
 	}
 
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+	filter.OrgID = &tenant
+
 	orderBy, err := order.Parse(orderByFields, qp.OrderBy, userbus.DefaultOrderBy)
 	if err != nil {
 		return errs.NewFieldErrors("order", err)
@@ -148,5 +310,133 @@ func (a *app) queryByID(ctx context.Context, _ *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
+	etag.Set(ctx, usr.Version)
+
+	return toAppUser(usr)
+}
+
+// querySessions returns the sessions of the user that are still active.
+func (a *app) querySessions(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	qp := parseSessionQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	orderBy, err := order.Parse(sessionOrderByFields, qp.OrderBy, sessionbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	now := time.Now()
+
+	filter := sessionbus.QueryFilter{
+		UserID:   &usr.ID,
+		ActiveAt: &now,
+	}
+
+	sess, err := a.sessionBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: userID[%s]: %s", usr.ID, err)
+	}
+
+	total, err := a.sessionBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: userID[%s]: %s", usr.ID, err)
+	}
+
+	return query.NewResult(toAppSessions(sess), total, page)
+}
+
+// revokeSession signs the user out of a single session.
+func (a *app) revokeSession(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	sessionID, err := uuid.Parse(web.Param(r, "session_id"))
+	if err != nil {
+		return errs.NewFieldErrors("session_id", err)
+	}
+
+	ses, err := a.sessionBus.QueryByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sessionbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: sessionID[%s]: %s", sessionID, err)
+	}
+
+	// Sessions of other users are reported as not found so their ids can't
+	// be probed.
+	if ses.UserID != usr.ID {
+		return errs.New(errs.NotFound, sessionbus.ErrNotFound)
+	}
+
+	if err := a.sessionBus.Revoke(ctx, ses); err != nil {
+		return errs.Newf(errs.Internal, "revoke: sessionID[%s]: %s", sessionID, err)
+	}
+
+	return nil
+}
+
+// revokeSessions signs the user out everywhere by ending every session and
+// revoking every refresh token of the user.
+func (a *app) revokeSessions(ctx context.Context, _ *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	if err := a.sessionBus.RevokeUser(ctx, usr.ID); err != nil {
+		return errs.Newf(errs.Internal, "revokeuser: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
+}
+
+// restore brings back a deleted user. It runs inside a transaction scoped
+// to the caller's tenant so a user from another organisation can't be
+// restored.
+func (a *app) restore(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return errs.NewFieldErrors("user_id", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	usr, err := a.userBus.Restore(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound):
+			return errs.New(errs.NotFound, err)
+		case errors.Is(err, userbus.ErrUniqueEmail):
+			return errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		return errs.Newf(errs.Internal, "restore: userID[%s]: %s", userID, err)
+	}
+
+	tenant, err := mid.GetTenant(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if usr.OrgID != tenant {
+		return errs.New(errs.NotFound, userbus.ErrNotFound)
+	}
+
+	etag.Set(ctx, usr.Version)
+
 	return toAppUser(usr)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/mail"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,18 +16,33 @@ import (
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/mailer"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 // Test contains functions for executing an api test.
 type Test struct {
-	DB   *dbtest.Database
-	Auth *auth.Auth
-	mux  http.Handler
+	DB      *dbtest.Database
+	Auth    *auth.Auth
+	Mailer  *mailer.Memory
+	mux     http.Handler
+	authMux http.Handler
 }
 
-// Run performs the actual test logic based on the table data.
+// Run performs the actual test logic based on the table data against the
+// sales service.
 func (at *Test) Run(t *testing.T, table []Table, testName string) {
+	run(t, at.mux, table, testName)
+}
+
+// RunAuth performs the actual test logic based on the table data against the
+// auth service.
+func (at *Test) RunAuth(t *testing.T, table []Table, testName string) {
+	run(t, at.authMux, table, testName)
+}
+
+func run(t *testing.T, mux http.Handler, table []Table, testName string) {
 	for _, tt := range table {
 		f := func(t *testing.T) {
 			r := httptest.NewRequest(tt.Method, tt.URL, nil)
@@ -41,8 +57,18 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 				r = httptest.NewRequest(tt.Method, tt.URL, bytes.NewBuffer(d))
 			}
 
-			r.Header.Set("Authorization", "Bearer "+tt.Token)
-			at.mux.ServeHTTP(w, r)
+			switch {
+			case strings.HasPrefix(tt.Token, "ApiKey "):
+				r.Header.Set("Authorization", tt.Token)
+			default:
+				r.Header.Set("Authorization", "Bearer "+tt.Token)
+			}
+
+			for k, v := range tt.Headers {
+				r.Header.Set(k, v)
+			}
+
+			mux.ServeHTTP(w, r)
 
 			if w.Code != tt.StatusCode {
 				t.Fatalf("%s: Should receive a status code of %d for the response : %d", tt.Name, tt.StatusCode, w.Code)
@@ -76,6 +102,16 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 
 // Token generates an authenticated token for a user.
 func Token(userBus *userbus.Business, ath *auth.Auth, email string) string {
+	return token(userBus, ath, email, nil)
+}
+
+// ImpersonationToken generates an authenticated token for the actor to act
+// as the user.
+func ImpersonationToken(userBus *userbus.Business, ath *auth.Auth, actorID uuid.UUID, email string) string {
+	return token(userBus, ath, email, &auth.Actor{Subject: actorID.String()})
+}
+
+func token(userBus *userbus.Business, ath *auth.Auth, email string, actor *auth.Actor) string {
 	addr, _ := mail.ParseAddress(email)
 
 	dbUsr, err := userBus.QueryByEmail(context.Background(), *addr)
@@ -83,6 +119,13 @@ func Token(userBus *userbus.Business, ath *auth.Auth, email string) string {
 		return ""
 	}
 
+	roles := role.ParseToString(dbUsr.Roles)
+
+	perms, err := ath.Permissions(context.Background(), dbUsr.ID, roles)
+	if err != nil {
+		return ""
+	}
+
 	claims := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   dbUsr.ID.String(),
@@ -90,7 +133,10 @@ func Token(userBus *userbus.Business, ath *auth.Auth, email string) string {
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
-		Roles: role.ParseToString(dbUsr.Roles),
+		Roles:       roles,
+		Permissions: perms,
+		Tenant:      dbUsr.OrgID.String(),
+		Actor:       actor,
 	}
 
 	token, err := ath.GenerateToken(kid, claims)
@@ -3,6 +3,7 @@ package apitest
 import (
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
 
@@ -11,6 +12,7 @@ type User struct {
 	userbus.User
 	Products []productbus.Product
 	Homes    []homebus.Home
+	Sessions []sessionbus.Session
 	Token    string
 }
 
@@ -24,8 +26,9 @@ type SeedData struct {
 type Table struct {
 	Name       string
 	URL        string
-	Token      string
+	Token      string // Tokens starting with "ApiKey " are sent as is.
 	Method     string
+	Headers    map[string]string
 	StatusCode int
 	Input      any
 	GotResp    any
@@ -10,6 +10,8 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mux"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/mailer"
 )
 
 // New initialized the system to run a test.
@@ -22,20 +24,26 @@ func New(t *testing.T, testName string) *Test {
 		Log:       db.Log,
 		DB:        db.DB,
 		KeyLookup: &KeyStore{},
+		ActiveKID: kid,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	mailer := mailer.NewMemory()
+
 	// -------------------------------------------------------------------------
 
-	server := httptest.NewServer(mux.WebAPI(mux.Config{
+	authMux := mux.WebAPI(mux.Config{
 		Log: db.Log,
 		DB:  db.DB,
 		AuthConfig: mux.AuthConfig{
-			Auth: auth,
+			Auth:   auth,
+			Mailer: mailer,
 		},
-	}, authbuild.Routes()))
+	}, authbuild.Routes())
+
+	server := httptest.NewServer(authMux)
 
 	authClient := authclient.New(db.Log, server.URL)
 
@@ -46,12 +54,15 @@ func New(t *testing.T, testName string) *Test {
 		DB:  db.DB,
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
+			Delegate:   delegate.New(db.Log),
 		},
 	}, salesbuild.Routes())
 
 	return &Test{
-		DB:   db,
-		Auth: auth,
-		mux:  mux,
+		DB:      db,
+		Auth:    auth,
+		Mailer:  mailer,
+		mux:     mux,
+		authMux: authMux,
 	}
 }
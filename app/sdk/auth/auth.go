@@ -8,25 +8,58 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/ardanlabs/service/business/domain/decisionbus/stores/decisiondb"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/rolebus/stores/roledb"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus/stores/sessiondb"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/foundation/keystore"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"github.com/open-policy-agent/opa/v1/rego"
 )
 
 // ErrForbidden is returned when a auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
-// Claims represents the authorization claims transmitted via a JWT.
+// Claims represents the authorization claims transmitted via a JWT. MFA is
+// set when the token was issued after a second factor was verified. The
+// Permissions are those granted by the roles at the time the token was issued.
+// The Tenant is the id of the organisation the subject belongs to. The Actor
+// is set when the token was issued for someone acting as the subject.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
+	Tenant      string   `json:"tenant,omitempty"`
+	MFA         bool     `json:"mfa,omitempty"`
+	Actor       *Actor   `json:"act,omitempty"`
+}
+
+// Actor identifies the party acting as the subject of a token, as described
+// by the act claim of RFC 8693.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// ActorSubject returns the subject of the actor or an empty string when the
+// token isn't being used to act as someone else.
+func (c Claims) ActorSubject() string {
+	if c.Actor == nil {
+		return ""
+	}
+
+	return c.Actor.Subject
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -37,67 +70,182 @@ type KeyLookup interface {
 	PublicKey(kid string) (key string, err error)
 }
 
-// Config represents information required to initialize auth.
+// activeKIDLookup is implemented by key lookups that track which key is
+// currently used for signing tokens.
+type activeKIDLookup interface {
+	ActiveKID() string
+}
+
+// jwksLookup is implemented by key lookups that can publish their public
+// keys as a JSON Web Key Set.
+type jwksLookup interface {
+	JWKS() (keystore.JWKS, error)
+}
+
+// Config represents information required to initialize auth. The ActiveKID
+// is used for signing when the KeyLookup doesn't track an active key itself.
+// The PolicyPath is a directory of rego files or an OPA bundle file that
+// replaces the embedded policies.
 type Config struct {
-	Log       *logger.Logger
-	DB        *sqlx.DB
-	KeyLookup KeyLookup
-	Issuer    string
+	Log        *logger.Logger
+	DB         *sqlx.DB
+	KeyLookup  KeyLookup
+	Issuer     string
+	ActiveKID  string
+	PolicyPath string
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
-	log       *logger.Logger
-	keyLookup KeyLookup
-	userBus   *userbus.Business
-	method    jwt.SigningMethod
-	parser    *jwt.Parser
-	issuer    string
+	log         *logger.Logger
+	keyLookup   KeyLookup
+	userBus     *userbus.Business
+	tokenBus    *tokenbus.Business
+	decisionBus *decisionbus.Business
+	roleBus     *rolebus.Business
+	sessionBus  *sessionbus.Business
+	parser      *jwt.Parser
+	issuer      string
+	activeKID   string
+	policyPath  string
+	policy      atomic.Pointer[policy]
 }
 
 // New creates an Auth to support authentication/authorization.
 func New(cfg Config) (*Auth, error) {
 
 	// If a database connection is not provided, we won't perform the
-	// user enabled check.
+	// user enabled and token revoked checks, decisions are only logged,
+	// tokens carry no permissions and sessions aren't tracked.
 	var userBus *userbus.Business
+	var tokenBus *tokenbus.Business
+	var decisionBus *decisionbus.Business
+	var roleBus *rolebus.Business
+	var sessionBus *sessionbus.Business
 	if cfg.DB != nil {
-		userBus = userbus.NewBusiness(cfg.Log, nil, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), 10*time.Minute))
+		userBus = userbus.NewBusiness(cfg.Log, nil, nil, nil, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), 10*time.Minute))
+		tokenBus = tokenbus.NewBusiness(cfg.Log, tokendb.NewStore(cfg.Log, cfg.DB))
+		decisionBus = decisionbus.NewBusiness(cfg.Log, decisiondb.NewStore(cfg.Log, cfg.DB))
+		roleBus = rolebus.NewBusiness(cfg.Log, roledb.NewStore(cfg.Log, cfg.DB))
+		sessionBus = sessionbus.NewBusiness(cfg.Log, tokenBus, sessiondb.NewStore(cfg.Log, cfg.DB))
+	}
+
+	p, err := loadPolicy(context.Background(), cfg.PolicyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading policy: %w", err)
 	}
 
 	a := Auth{
-		log:       cfg.Log,
-		keyLookup: cfg.KeyLookup,
-		userBus:   userBus,
-		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
-		issuer:    cfg.Issuer,
+		log:         cfg.Log,
+		keyLookup:   cfg.KeyLookup,
+		userBus:     userBus,
+		tokenBus:    tokenBus,
+		decisionBus: decisionBus,
+		roleBus:     roleBus,
+		sessionBus:  sessionBus,
+		parser:      jwt.NewParser(jwt.WithValidMethods(validMethods)),
+		issuer:      cfg.Issuer,
+		activeKID:   cfg.ActiveKID,
+		policyPath:  cfg.PolicyPath,
 	}
 
+	a.policy.Store(p)
+
 	return &a, nil
 }
 
+// ReloadPolicy reloads the policy from the configured path when its content
+// has changed. A policy that fails to compile or doesn't define every rule is
+// rejected and the current policy remains in use. It reports if a new policy
+// was put in place.
+func (a *Auth) ReloadPolicy(ctx context.Context) (bool, error) {
+	if a.policyPath == "" {
+		return false, nil
+	}
+
+	fingerprint, err := policyFingerprint(a.policyPath)
+	if err != nil {
+		return false, fmt.Errorf("fingerprint: %w", err)
+	}
+
+	if fingerprint == a.policy.Load().fingerprint {
+		return false, nil
+	}
+
+	p, err := loadPolicy(ctx, a.policyPath)
+	if err != nil {
+		return false, fmt.Errorf("loading policy: %w", err)
+	}
+
+	a.policy.Store(p)
+
+	return true, nil
+}
+
 // Issuer provides the configured issuer used to authenticate tokens.
 func (a *Auth) Issuer() string {
 	return a.issuer
 }
 
-// GenerateToken generates a signed JWT token string representing the user Claims.
+// ActiveKID provides the kid of the key that should be used to sign tokens.
+func (a *Auth) ActiveKID() string {
+	if akl, ok := a.keyLookup.(activeKIDLookup); ok {
+		if kid := akl.ActiveKID(); kid != "" {
+			return kid
+		}
+	}
+
+	return a.activeKID
+}
+
+// JWKS provides the set of public keys that can be used to verify tokens.
+func (a *Auth) JWKS() (keystore.JWKS, error) {
+	jl, ok := a.keyLookup.(jwksLookup)
+	if !ok {
+		return keystore.JWKS{}, errors.New("key lookup does not support jwks")
+	}
+
+	return jl.JWKS()
+}
+
+// Permissions returns the permissions to carry in the claims of a token for
+// the specified roles. Unless the user id is uuid.Nil, the permissions of the
+// custom roles assigned to the user are included.
+func (a *Auth) Permissions(ctx context.Context, userID uuid.UUID, roles []string) ([]string, error) {
+	if a.roleBus == nil {
+		return nil, nil
+	}
+
+	perms, err := a.roleBus.Permissions(ctx, userID, roles)
+	if err != nil {
+		return nil, fmt.Errorf("permissions: %w", err)
+	}
+
+	return perms, nil
+}
+
+// GenerateToken generates a signed JWT token string representing the user
+// Claims. A unique id (jti) is assigned when the claims don't provide one so
+// every token can be revoked and tracked as a session.
 func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
-	token := jwt.NewWithClaims(a.method, claims)
-	token.Header["kid"] = kid
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
 
 	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
 	if err != nil {
 		return "", fmt.Errorf("private key: %w", err)
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	privateKey, method, err := parsePrivateKey(privateKeyPEM)
 	if err != nil {
 		return "", fmt.Errorf("parsing private pem: %w", err)
 	}
 
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
 	str, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("signing token: %w", err)
@@ -135,23 +283,53 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, fmt.Errorf("failed to fetch public key: %w", err)
 	}
 
+	publicKey, method, err := parsePublicKey(pem)
+	if err != nil {
+		return Claims{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	// The algorithm is dictated by the key, never by the token.
+	if token.Method.Alg() != method.Alg() {
+		return Claims{}, fmt.Errorf("token alg[%s] does not match key alg[%s]", token.Method.Alg(), method.Alg())
+	}
+
 	input := map[string]any{
 		"Key":   pem,
 		"Token": jwt,
 		"ISS":   a.issuer,
+		"Alg":   method.Alg(),
+	}
+
+	// OPA can't verify EdDSA signatures so the signature is verified here
+	// and the policy checks the remaining claims.
+	if method.Alg() == "EdDSA" {
+		input["Verified"] = verifySignature(jwt, method, publicKey) == nil
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, regoAuthentication, RuleAuthenticate, input); err != nil {
+	if err := a.evaluate(ctx, claims, RuleAuthenticate, input); err != nil {
 		a.log.Info(ctx, "**Authenticate-FAILED**", "token", jwt)
 		return Claims{}, fmt.Errorf("authentication failed : %w", err)
 	}
 
+	// Check the database for this token to verify it was not revoked.
+
+	if err := a.isTokenRevoked(ctx, claims); err != nil {
+		return Claims{}, fmt.Errorf("token not valid : %w", err)
+	}
+
 	// Check the database for this user to verify they are still enabled.
 
 	if err := a.isUserEnabled(ctx, claims); err != nil {
 		return Claims{}, fmt.Errorf("user not enabled : %w", err)
 	}
 
+	// Record the session for this token as being used. A failure here
+	// shouldn't stop the request.
+
+	if err := a.touchSession(ctx, claims); err != nil {
+		a.log.Error(ctx, "authenticate", "msg", "touch session", "err", err)
+	}
+
 	return claims, nil
 }
 
@@ -159,49 +337,52 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 // none of the input roles are within the user's claims, we return an error
 // otherwise the user is authorized.
 func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
-	input := map[string]any{
-		"Roles":   claims.Roles,
-		"Subject": claims.Subject,
-		"UserID":  userID,
-	}
-
-	if err := a.opaPolicyEvaluation(ctx, regoAuthorization, rule, input); err != nil {
-		return fmt.Errorf("rego evaluation failed : %w", err)
-	}
-
-	return nil
+	return a.AuthorizeResource(ctx, claims, userID, rule, Resource{})
 }
 
-// opaPolicyEvaluation asks opa to evaluate the token against the specified token
-// policy and public key.
-func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule string, input any) error {
-	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
-
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", regoScript),
-	).PrepareForEval(ctx)
-	if err != nil {
-		return err
+// AuthorizeResource works like Authorize and also provides the resource being
+// accessed to the rule. When the claims carry an actor, the impersonation rule
+// must also allow the request.
+func (a *Auth) AuthorizeResource(ctx context.Context, claims Claims, userID uuid.UUID, rule string, resource Resource) error {
+	attributes := resource.Attributes
+	if attributes == nil {
+		attributes = map[string]any{}
 	}
 
-	results, err := q.Eval(ctx, rego.EvalInput(input))
-	if err != nil {
-		return fmt.Errorf("query: %w", err)
+	input := map[string]any{
+		"Roles":       claims.Roles,
+		"Permissions": claims.Permissions,
+		"Subject":     claims.Subject,
+		"Tenant":      claims.Tenant,
+		"Actor":       claims.ActorSubject(),
+		"UserID":      userID,
+		"MFA":         claims.MFA,
+		"Resource": map[string]any{
+			"Type":       resource.Type,
+			"Action":     resource.Action,
+			"Attributes": attributes,
+		},
 	}
 
-	if len(results) == 0 {
-		return errors.New("no results")
+	if claims.Actor != nil {
+		if err := a.evaluate(ctx, claims, RuleImpersonation, input); err != nil {
+			return fmt.Errorf("impersonation not allowed : %w", err)
+		}
 	}
 
-	result, ok := results[0].Bindings["x"].(bool)
-	if !ok || !result {
-		return fmt.Errorf("bindings results[%v] ok[%v]", results, ok)
+	if err := a.evaluate(ctx, claims, rule, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
 	}
 
 	return nil
 }
 
+// opaPolicyEvaluation asks opa to evaluate the input against the specified
+// rule of the current policy.
+func (a *Auth) opaPolicyEvaluation(ctx context.Context, rule string, input any) error {
+	return a.policy.Load().eval(ctx, rule, input)
+}
+
 // isUserEnabled hits the database and checks the user is not disabled. If the
 // no database connection was provided, this check is skipped.
 func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
@@ -225,3 +406,33 @@ func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
 
 	return nil
 }
+
+// isTokenRevoked hits the database and checks the token has not been revoked.
+// If no database connection was provided or the token has no id, this check
+// is skipped.
+func (a *Auth) isTokenRevoked(ctx context.Context, claims Claims) error {
+	if a.tokenBus == nil || claims.ID == "" {
+		return nil
+	}
+
+	revoked, err := a.tokenBus.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return fmt.Errorf("query token: %w", err)
+	}
+
+	if revoked {
+		return fmt.Errorf("token revoked")
+	}
+
+	return nil
+}
+
+// touchSession records the session of the token as being used. If no
+// database connection was provided or the token has no id, this is skipped.
+func (a *Auth) touchSession(ctx context.Context, claims Claims) error {
+	if a.sessionBus == nil || claims.ID == "" {
+		return nil
+	}
+
+	return a.sessionBus.Touch(ctx, claims.ID)
+}
@@ -33,6 +33,9 @@ func Test_Auth(t *testing.T) {
 	t.Run("test4", test4(ath))
 	t.Run("test5", test5(ath))
 	t.Run("test6", test6(ath))
+	t.Run("test7", test7(ath))
+	t.Run("test8", test8(ath))
+	t.Run("test9", test9(ath))
 }
 
 func test1(ath *auth.Auth) func(t *testing.T) {
@@ -255,6 +258,160 @@ func test6(ath *auth.Auth) func(t *testing.T) {
 	return f
 }
 
+func test7(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles: []string{role.Admin.String()},
+		}
+		userID := uuid.MustParse(claims.Subject)
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAdminMFA)
+		if err == nil {
+			t.Error("Should NOT be able to authorize the RuleAdminMFA claim without MFA")
+		}
+
+		claims.MFA = true
+
+		token, err = ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err = ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		if !parsedClaims.MFA {
+			t.Error("Should have the MFA claim after authenticating")
+		}
+
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAdminMFA)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleAdminMFA claim with MFA : %s", err)
+		}
+	}
+
+	return f
+}
+
+func test8(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles:       []string{role.User.String()},
+			Permissions: []string{"product:write", "home:read", "role:*"},
+		}
+		userID := uuid.MustParse(claims.Subject)
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		tests := []struct {
+			resource auth.Resource
+			allowed  bool
+		}{
+			{auth.Resource{Type: "product", Action: auth.ActionCreate}, true},
+			{auth.Resource{Type: "product", Action: auth.ActionDelete}, true},
+			{auth.Resource{Type: "product", Action: auth.ActionRead}, false},
+			{auth.Resource{Type: "home", Action: auth.ActionRead}, true},
+			{auth.Resource{Type: "home", Action: auth.ActionUpdate}, false},
+			{auth.Resource{Type: "role", Action: auth.ActionRead}, true},
+			{auth.Resource{Type: "role", Action: auth.ActionDelete}, true},
+			{auth.Resource{Type: "user", Action: auth.ActionRead}, false},
+			{auth.Resource{Action: auth.ActionCreate}, false},
+		}
+
+		for _, tt := range tests {
+			err := ath.AuthorizeResource(context.Background(), parsedClaims, userID, auth.RulePermission, tt.resource)
+			if allowed := err == nil; allowed != tt.allowed {
+				t.Errorf("Should get allowed[%v] for %s:%s : got[%v] : %v", tt.allowed, tt.resource.Type, tt.resource.Action, allowed, err)
+			}
+		}
+	}
+
+	return f
+}
+
+func test9(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles: []string{role.User.String()},
+			Actor: &auth.Actor{Subject: "9c8f2a4e-1f0b-4e7b-a7d2-3d5c1f8e6b10"},
+		}
+		userID := uuid.MustParse(claims.Subject)
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		if parsedClaims.ActorSubject() != claims.Actor.Subject {
+			t.Fatalf("Should have the actor after authenticating : %q", parsedClaims.ActorSubject())
+		}
+
+		tests := []struct {
+			resource auth.Resource
+			allowed  bool
+		}{
+			{auth.Resource{Type: "user", Action: auth.ActionRead}, true},
+			{auth.Resource{Type: "home", Action: auth.ActionUpdate}, true},
+			{auth.Resource{Type: "user", Action: auth.ActionUpdate}, false},
+			{auth.Resource{Type: "user", Action: auth.ActionImpersonate}, false},
+			{auth.Resource{Type: "role", Action: auth.ActionRead}, false},
+			{auth.Resource{Type: "mfa", Action: auth.ActionCreate}, false},
+		}
+
+		for _, tt := range tests {
+			err := ath.AuthorizeResource(context.Background(), parsedClaims, userID, auth.RuleAdminOrSubject, tt.resource)
+			if allowed := err == nil; allowed != tt.allowed {
+				t.Errorf("Should get allowed[%v] for %s:%s : got[%v] : %v", tt.allowed, tt.resource.Type, tt.resource.Action, allowed, err)
+			}
+		}
+	}
+
+	return f
+}
+
 // =============================================================================
 
 func newUnit(t *testing.T) *logger.Logger {
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/google/uuid"
+)
+
+// secretInputs are the input fields that are never recorded with a decision.
+var secretInputs = map[string]bool{
+	"Key":   true,
+	"Token": true,
+}
+
+// evaluate asks opa to evaluate the rule and records the decision.
+func (a *Auth) evaluate(ctx context.Context, claims Claims, rule string, input map[string]any) error {
+	start := time.Now()
+	err := a.opaPolicyEvaluation(ctx, rule, input)
+	a.recordDecision(ctx, claims, rule, input, err, time.Since(start))
+
+	return err
+}
+
+// recordDecision writes the decision to the log and, when a database
+// connection was provided, to the decision table. The decision is recorded
+// against the tenant in the claims so it's only visible to that tenant. A
+// failure to record the decision is logged and doesn't change the outcome of
+// the request.
+func (a *Auth) recordDecision(ctx context.Context, claims Claims, rule string, input map[string]any, evalErr error, latency time.Duration) {
+	recorded := make(map[string]any, len(input))
+	for k, v := range input {
+		if !secretInputs[k] {
+			recorded[k] = v
+		}
+	}
+
+	var reason string
+	if evalErr != nil {
+		reason = evalErr.Error()
+	}
+
+	a.log.Info(ctx, "auth decision", "subject", claims.Subject, "rule", rule, "allowed", evalErr == nil, "reason", reason, "latency", latency, "input", recorded)
+
+	if a.decisionBus == nil {
+		return
+	}
+
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		a.log.Error(ctx, "auth decision", "msg", "marshal input", "err", err)
+		return
+	}
+
+	// Claims without a valid tenant are recorded without one.
+	orgID, _ := uuid.Parse(claims.Tenant)
+
+	nd := decisionbus.NewDecision{
+		Subject: claims.Subject,
+		OrgID:   orgID,
+		Rule:    rule,
+		Input:   data,
+		Allowed: evalErr == nil,
+		Reason:  reason,
+		Latency: latency,
+	}
+
+	if _, err := a.decisionBus.Create(ctx, nd); err != nil {
+		a.log.Error(ctx, "auth decision", "msg", "create", "err", err)
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// validMethods lists the signing algorithms accepted for tokens. The
+// algorithm actually used is determined by the type of key behind the kid.
+var validMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// parsePrivateKey parses the PEM encoded private key and returns the signing
+// method to use with it.
+func parsePrivateKey(privatePEM string) (crypto.PrivateKey, jwt.SigningMethod, error) {
+	data := []byte(privatePEM)
+
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		if key.Curve.Params().Name != "P-256" {
+			return nil, nil, errors.New("ecdsa key must use the P-256 curve")
+		}
+		return key, jwt.SigningMethodES256, nil
+	}
+
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, nil, errors.New("unsupported private key: must be RSA, ECDSA P-256 or Ed25519")
+}
+
+// parsePublicKey parses the PEM encoded public key and returns the signing
+// method tokens must be signed with to be verified by it.
+func parsePublicKey(publicPEM string) (crypto.PublicKey, jwt.SigningMethod, error) {
+	data := []byte(publicPEM)
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		if key.Curve.Params().Name != "P-256" {
+			return nil, nil, errors.New("ecdsa key must use the P-256 curve")
+		}
+		return key, jwt.SigningMethodES256, nil
+	}
+
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, nil, errors.New("unsupported public key: must be RSA, ECDSA P-256 or Ed25519")
+}
+
+// verifySignature checks the signature of the token with the public key.
+func verifySignature(token string, method jwt.SigningMethod, key crypto.PublicKey) error {
+	i := strings.LastIndex(token, ".")
+	if i == -1 {
+		return errors.New("token is malformed")
+	}
+
+	if err := method.Verify(token[:i], token[i+1:], key); err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/keystore"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func Test_Algorithms(t *testing.T) {
+	log := newUnit(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ecdsa key : %s", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ed25519 key : %s", err)
+	}
+
+	ks := keystore.New()
+	loadKey(t, ks, "es256", ecKey)
+	loadKey(t, ks, "eddsa", edKey)
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator : %s", err)
+	}
+
+	tests := []struct {
+		kid string
+		alg string
+	}{
+		{kid: "es256", alg: "ES256"},
+		{kid: "eddsa", alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			claims := auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    ath.Issuer(),
+					Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+					ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+					IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+				},
+				Roles: []string{role.Admin.String()},
+			}
+
+			token, err := ath.GenerateToken(tt.kid, claims)
+			if err != nil {
+				t.Fatalf("Should be able to generate a JWT : %s", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.Claims{})
+			if err != nil {
+				t.Fatalf("Should be able to parse the JWT : %s", err)
+			}
+
+			if parsed.Method.Alg() != tt.alg {
+				t.Fatalf("Should sign with %s, got %s", tt.alg, parsed.Method.Alg())
+			}
+
+			if _, err := ath.Authenticate(context.Background(), "Bearer "+token); err != nil {
+				t.Fatalf("Should be able to authenticate the claims : %s", err)
+			}
+
+			if _, err := ath.Authenticate(context.Background(), "Bearer "+token+"A"); err == nil {
+				t.Fatal("Should NOT be able to authenticate a token with a bad signature")
+			}
+
+			claims.Issuer = "bad issuer"
+			token, err = ath.GenerateToken(tt.kid, claims)
+			if err != nil {
+				t.Fatalf("Should be able to generate a JWT : %s", err)
+			}
+
+			if _, err := ath.Authenticate(context.Background(), "Bearer "+token); err == nil {
+				t.Fatal("Should NOT be able to authenticate a token with the wrong issuer")
+			}
+		})
+	}
+
+	jwks, err := ks.JWKS()
+	if err != nil {
+		t.Fatalf("Should be able to produce the jwks : %s", err)
+	}
+
+	for _, jwk := range jwks.Keys {
+		exp, err := ks.PublicKey(jwk.KeyID)
+		if err != nil {
+			t.Fatalf("Should be able to find the public key : %s", err)
+		}
+
+		got, err := jwk.PublicKeyPEM()
+		if err != nil {
+			t.Fatalf("Should be able to convert the %s jwk to PEM : %s", jwk.Algorithm, err)
+		}
+
+		if got != exp {
+			t.Errorf("Got: %s", got)
+			t.Errorf("Exp: %s", exp)
+			t.Errorf("Should get back the same %s public key from the jwk", jwk.Algorithm)
+		}
+	}
+}
+
+func loadKey(t *testing.T, ks *keystore.KeyStore, kid string, key any) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the %s key : %s", kid, err)
+	}
+
+	doc, err := json.Marshal(struct {
+		Key string `json:"key"`
+		PEM string `json:"pem"`
+	}{
+		Key: kid,
+		PEM: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to marshal the document : %s", err)
+	}
+
+	if _, err := ks.LoadByJSON(string(doc)); err != nil {
+		t.Fatalf("Should be able to load the %s key : %s", kid, err)
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/loader"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+)
+
+// embeddedPolicy is the fingerprint used for the policies compiled into the
+// binary, which never change.
+const embeddedPolicy = "embedded"
+
+// policy holds a prepared query for every rule so evaluating a request
+// doesn't pay for parsing and compiling the rego modules.
+type policy struct {
+	fingerprint string
+	queries     map[string]rego.PreparedEvalQuery
+}
+
+// eval evaluates the rule against the input and reports an error unless the
+// rule evaluated to true.
+func (p *policy) eval(ctx context.Context, rule string, input any) error {
+	q, exists := p.queries[rule]
+	if !exists {
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+
+	results, err := q.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if len(results) == 0 {
+		return errors.New("no results")
+	}
+
+	result, ok := results[0].Bindings["x"].(bool)
+	if !ok || !result {
+		return fmt.Errorf("bindings results[%v] ok[%v]", results, ok)
+	}
+
+	return nil
+}
+
+// loadPolicy compiles the policy found at the path, which can be a directory
+// of rego files or an OPA bundle file. When no path is provided, the policies
+// embedded in the binary are used. The policy is rejected unless it defines
+// every rule this package evaluates.
+func loadPolicy(ctx context.Context, path string) (*policy, error) {
+	fingerprint := embeddedPolicy
+	data := map[string]any{}
+	modules := map[string]*ast.Module{}
+
+	switch path {
+	case "":
+		for name, src := range map[string]string{
+			"authentication.rego": regoAuthentication,
+			"authorization.rego":  regoAuthorization,
+		} {
+			mod, err := ast.ParseModule(name, src)
+			if err != nil {
+				return nil, fmt.Errorf("parse module[%s]: %w", name, err)
+			}
+			modules[name] = mod
+		}
+
+	default:
+		fp, err := policyFingerprint(path)
+		if err != nil {
+			return nil, fmt.Errorf("fingerprint: %w", err)
+		}
+		fingerprint = fp
+
+		b, err := loader.NewFileLoader().AsBundle(path)
+		if err != nil {
+			return nil, fmt.Errorf("load bundle: %w", err)
+		}
+
+		for _, mf := range b.Modules {
+			modules[mf.Path] = mf.Parsed
+		}
+
+		if b.Data != nil {
+			data = b.Data
+		}
+	}
+
+	compiler := ast.NewCompiler()
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, fmt.Errorf("compile: %w", compiler.Errors)
+	}
+
+	store := inmem.NewFromObject(data)
+
+	queries := make(map[string]rego.PreparedEvalQuery, len(policyRules))
+	for _, rule := range policyRules {
+		ref := fmt.Sprintf("data.%s.%s", opaPackage, rule)
+
+		if len(compiler.GetRulesExact(ast.MustParseRef(ref))) == 0 {
+			return nil, fmt.Errorf("rule %q is not defined", rule)
+		}
+
+		q, err := rego.New(
+			rego.Query("x = "+ref),
+			rego.Compiler(compiler),
+			rego.Store(store),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("prepare rule[%s]: %w", rule, err)
+		}
+
+		queries[rule] = q
+	}
+
+	p := policy{
+		fingerprint: fingerprint,
+		queries:     queries,
+	}
+
+	return &p, nil
+}
+
+// policyFingerprint produces a hash of the name and content of every file
+// found at the path so changes can be detected by polling.
+func policyFingerprint(path string) (string, error) {
+	h := sha256.New()
+
+	walk := func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		io.WriteString(h, filepath.ToSlash(file))
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(path, walk); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func Test_PolicyReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "policy.rego")
+
+	write := func(adminOnly string) {
+		if err := os.WriteFile(file, []byte(testPolicy(adminOnly)), 0600); err != nil {
+			t.Fatalf("Should be able to write the policy : %s", err)
+		}
+	}
+
+	write("true")
+
+	ath, err := auth.New(auth.Config{
+		Log:        newUnit(t),
+		KeyLookup:  &keyStore{},
+		Issuer:     "service project",
+		PolicyPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator with a policy dir : %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+		Roles:            []string{role.User.String()},
+	}
+
+	authorize := func() error {
+		return ath.Authorize(context.Background(), claims, uuid.New(), auth.RuleAdminOnly)
+	}
+
+	if err := authorize(); err != nil {
+		t.Fatalf("Should be authorized by the loaded policy : %s", err)
+	}
+
+	// An unchanged policy is not reloaded.
+
+	reloaded, err := ath.ReloadPolicy(context.Background())
+	if err != nil || reloaded {
+		t.Fatalf("Should not reload an unchanged policy : %v %v", reloaded, err)
+	}
+
+	// A changed policy takes effect.
+
+	write("false")
+
+	reloaded, err = ath.ReloadPolicy(context.Background())
+	if err != nil || !reloaded {
+		t.Fatalf("Should reload a changed policy : %v %v", reloaded, err)
+	}
+
+	if err := authorize(); err == nil {
+		t.Fatalf("Should NOT be authorized by the reloaded policy")
+	}
+
+	// A bad policy is rejected and the last good policy stays in use.
+
+	if err := os.WriteFile(file, []byte("package ardan.rego\n\nrule_any if {"), 0600); err != nil {
+		t.Fatalf("Should be able to write the policy : %s", err)
+	}
+
+	if _, err := ath.ReloadPolicy(context.Background()); err == nil {
+		t.Fatalf("Should NOT reload a policy that doesn't compile")
+	}
+
+	if err := authorize(); err == nil {
+		t.Fatalf("Should still use the last good policy")
+	}
+
+	// A policy missing a rule is rejected.
+
+	if err := os.WriteFile(file, []byte("package ardan.rego\n\nimport rego.v1\n\nauth := true\n"), 0600); err != nil {
+		t.Fatalf("Should be able to write the policy : %s", err)
+	}
+
+	if _, err := ath.ReloadPolicy(context.Background()); err == nil {
+		t.Fatalf("Should NOT reload a policy that is missing rules")
+	}
+
+	write("true")
+
+	if _, err := ath.ReloadPolicy(context.Background()); err != nil {
+		t.Fatalf("Should reload a fixed policy : %s", err)
+	}
+
+	if err := authorize(); err != nil {
+		t.Fatalf("Should be authorized by the fixed policy : %s", err)
+	}
+}
+
+// testPolicy produces a policy that defines every rule with the admin only
+// rule set to the specified value.
+func testPolicy(adminOnly string) string {
+	const policy = `package ardan.rego
+
+import rego.v1
+
+auth := true
+
+rule_any := true
+
+rule_admin_only := %s
+
+rule_user_only := true
+
+rule_admin_or_subject := true
+
+rule_admin_mfa := true
+
+rule_permission := true
+
+rule_impersonation := true
+`
+
+	return fmt.Sprintf(policy, adminOnly)
+}
+
+func Test_ResourceInput(t *testing.T) {
+	const policy = `package ardan.rego
+
+import rego.v1
+
+auth := true
+
+rule_any := true
+
+rule_admin_only := true
+
+rule_user_only := true
+
+rule_admin_mfa := true
+
+rule_permission := true
+
+rule_impersonation := true
+
+default rule_admin_or_subject := false
+
+rule_admin_or_subject if {
+	input.Resource.Type == "home"
+	input.Resource.Action == "delete"
+	input.Resource.Attributes.country == "US"
+}
+`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("Should be able to write the policy : %s", err)
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:        newUnit(t),
+		KeyLookup:  &keyStore{},
+		Issuer:     "service project",
+		PolicyPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator with a policy dir : %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+		Roles:            []string{role.User.String()},
+	}
+
+	resource := auth.Resource{
+		Type:       "home",
+		Action:     auth.ActionDelete,
+		Attributes: map[string]any{"country": "US"},
+	}
+
+	if err := ath.AuthorizeResource(context.Background(), claims, uuid.New(), auth.RuleAdminOrSubject, resource); err != nil {
+		t.Fatalf("Should be authorized for a home in the US : %s", err)
+	}
+
+	resource.Attributes["country"] = "CA"
+
+	if err := ath.AuthorizeResource(context.Background(), claims, uuid.New(), auth.RuleAdminOrSubject, resource); err == nil {
+		t.Fatalf("Should NOT be authorized for a home outside the US")
+	}
+
+	if err := ath.Authorize(context.Background(), claims, uuid.New(), auth.RuleAdminOrSubject); err == nil {
+		t.Fatalf("Should NOT be authorized without a resource")
+	}
+}
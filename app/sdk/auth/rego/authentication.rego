@@ -5,9 +5,24 @@ import rego.v1
 default auth := false
 
 auth if {
+	input.Alg != "EdDSA"
 	[valid, _, _] := io.jwt.decode_verify(input.Token, {
 		"cert": input.Key,
 		"iss": input.ISS,
+		"alg": input.Alg,
 	})
 	valid == true
 }
+
+# OPA can't verify EdDSA signatures, so the signature is verified by the
+# caller and reported in the input. The claims are still validated here.
+auth if {
+	input.Alg == "EdDSA"
+	input.Verified == true
+	[header, payload, _] := io.jwt.decode(input.Token)
+	header.alg == "EdDSA"
+	payload.iss == input.ISS
+	now := time.now_ns() / 1000000000
+	payload.exp > now
+	object.get(payload, "nbf", 0) <= now
+}
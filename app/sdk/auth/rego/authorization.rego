@@ -2,6 +2,11 @@ package ardan.rego
 
 import rego.v1
 
+# The input provides the caller's Roles, Permissions, Subject, Tenant, MFA and
+# Actor claims, the UserID that owns what is being accessed and the Resource
+# with its Type, Action and Attributes, such as input.Resource.Attributes.country
+# for a home.
+
 role_user := "USER"
 
 role_admin := "ADMIN"
@@ -44,3 +49,60 @@ rule_admin_or_subject if {
 	count(input_user) > 0
 	input.UserID == input.Subject
 }
+
+default rule_admin_mfa := false
+
+rule_admin_mfa if {
+	claim_roles := {role | some role in input.Roles}
+	input_admin := {role_admin} & claim_roles
+	count(input_admin) > 0
+	input.MFA == true
+}
+
+# A permission is written as "<type>:<action>". The write action covers every
+# action except read and * covers every action.
+
+default rule_permission := false
+
+rule_permission if {
+	input.Resource.Type != ""
+	some permission in input.Permissions
+	grants(permission)
+}
+
+grants(permission) if {
+	permission == sprintf("%s:%s", [input.Resource.Type, input.Resource.Action])
+}
+
+grants(permission) if {
+	input.Resource.Action != "read"
+	permission == sprintf("%s:write", [input.Resource.Type])
+}
+
+grants(permission) if {
+	permission == sprintf("%s:*", [input.Resource.Type])
+}
+
+# The impersonation rule is evaluated in addition to the requested rule when
+# the token carries an Actor. A support admin acting as a user can see what
+# the user sees but can't change the user's credentials, roles or sessions.
+
+sensitive_types := {"role", "user_role", "apikey", "mfa", "session"}
+
+sensitive_user_actions := {"update", "delete", "impersonate"}
+
+default rule_impersonation := false
+
+rule_impersonation if {
+	input.Actor != ""
+	not sensitive
+}
+
+sensitive if {
+	input.Resource.Type in sensitive_types
+}
+
+sensitive if {
+	input.Resource.Type == "user"
+	input.Resource.Action in sensitive_user_actions
+}
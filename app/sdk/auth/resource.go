@@ -0,0 +1,38 @@
+package auth
+
+import "net/http"
+
+// The set of actions that can be performed on a resource.
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// ActionImpersonate is used when a token is requested to act as a user.
+	ActionImpersonate = "impersonate"
+)
+
+// Resource describes what is being accessed so rules can base a decision on
+// the type of resource, the action being performed and any attributes of the
+// resource, like the cost of a product or the country of a home. It is
+// available to rules as input.Resource.
+type Resource struct {
+	Type       string
+	Action     string
+	Attributes map[string]any
+}
+
+// ActionFromMethod maps an http method to the action it performs.
+func ActionFromMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionRead
+	}
+}
@@ -11,8 +11,24 @@ const (
 	RuleAdminOnly      = "rule_admin_only"
 	RuleUserOnly       = "rule_user_only"
 	RuleAdminOrSubject = "rule_admin_or_subject"
+	RuleAdminMFA       = "rule_admin_mfa"
+	RulePermission     = "rule_permission"
+	RuleImpersonation  = "rule_impersonation"
 )
 
+// policyRules is the set of rules a policy must define. A query is prepared
+// for each of them when the policy is loaded.
+var policyRules = []string{
+	RuleAuthenticate,
+	RuleAny,
+	RuleAdminOnly,
+	RuleUserOnly,
+	RuleAdminOrSubject,
+	RuleAdminMFA,
+	RulePermission,
+	RuleImpersonation,
+}
+
 // Package name of our rego code.
 const (
 	opaPackage string = "ardan.rego"
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -40,9 +41,11 @@ var defaultClient = http.Client{
 
 // Client represents a client that can talk to the auth service.
 type Client struct {
-	log  *logger.Logger
-	url  string
-	http *http.Client
+	log      *logger.Logger
+	url      string
+	http     *http.Client
+	localCfg *LocalConfig
+	local    *local
 }
 
 // New constructs an Auth that can be used to talk with the auth service.
@@ -57,6 +60,14 @@ func New(log *logger.Logger, url string, options ...func(cln *Client)) *Client {
 		option(&cln)
 	}
 
+	if cln.localCfg != nil {
+		l, err := newLocal(log, &cln)
+		if err != nil {
+			log.Error(context.Background(), "authclient: local verification disabled", "err", err)
+		}
+		cln.local = l
+	}
+
 	return &cln
 }
 
@@ -68,8 +79,19 @@ func WithClient(http *http.Client) func(cln *Client) {
 	}
 }
 
-// Authenticate calls the auth service to authenticate the user.
+// Authenticate calls the auth service to authenticate the user. When local
+// verification is enabled, the token is verified in process and the auth
+// service is only called if the signing key is not known.
 func (cln *Client) Authenticate(ctx context.Context, authorization string) (AuthenticateResp, error) {
+	if cln.local != nil {
+		resp, err := cln.local.authenticate(ctx, authorization)
+		if !errors.Is(err, errLocalUnavailable) {
+			return resp, err
+		}
+
+		cln.log.Info(ctx, "authclient: local verification unavailable, calling auth service")
+	}
+
 	endpoint := fmt.Sprintf("%s/v1/auth/authenticate", cln.url)
 
 	headers := map[string]string{
@@ -84,8 +106,13 @@ func (cln *Client) Authenticate(ctx context.Context, authorization string) (Auth
 	return resp, nil
 }
 
-// Authorize calls the auth service to authorize the user.
+// Authorize calls the auth service to authorize the user. When local
+// verification is enabled, the rule is evaluated in process.
 func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
+	if cln.local != nil {
+		return cln.local.authorize(ctx, auth)
+	}
+
 	endpoint := fmt.Sprintf("%s/v1/auth/authorize", cln.url)
 
 	if err := cln.do(ctx, http.MethodPost, endpoint, nil, auth, nil); err != nil {
@@ -95,6 +122,18 @@ func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
 	return nil
 }
 
+// ReloadPolicy reloads the rego policy used for local verification when the
+// content at the configured path has changed. It reports whether a new
+// policy is now active. Without local verification there is nothing to
+// reload since the auth service evaluates the rules.
+func (cln *Client) ReloadPolicy(ctx context.Context) (bool, error) {
+	if cln.local == nil {
+		return false, nil
+	}
+
+	return cln.local.auth.ReloadPolicy(ctx)
+}
+
 func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) error {
 	var statusCode int
 
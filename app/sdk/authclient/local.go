@@ -0,0 +1,266 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/foundation/keystore"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// errLocalUnavailable is returned when a token can't be verified locally
+// because the key it was signed with is not known. The caller should fall
+// back to the auth service.
+var errLocalUnavailable = errors.New("local verification unavailable")
+
+// minKeyRefresh limits how often an unknown kid can trigger a fetch of the
+// key set, so bad tokens can't be used to hammer the auth service.
+const minKeyRefresh = 30 * time.Second
+
+// LocalConfig represents the information required to verify tokens and
+// evaluate rules in process. The DB must be the auth service's database so
+// tokens get the same revocation, enabled and session checks and decisions
+// are recorded in the same decision log. The PolicyPath should match the
+// auth service's so both evaluate the same rules.
+type LocalConfig struct {
+	DB          *sqlx.DB
+	Issuer      string
+	PolicyPath  string
+	JWKSRefresh time.Duration
+}
+
+// WithLocalVerification configures the client to fetch and cache the auth
+// service's public keys (JWKS) and to verify tokens and evaluate rego rules
+// in process. When a token is signed with a key that can't be found, the
+// call falls back to the auth service. The key set is refreshed on the
+// configured interval.
+//
+// Local verification is refused without a database, since tokens would skip
+// the revocation, enabled and session checks and decisions would never reach
+// the decision log. Every call then goes to the auth service.
+func WithLocalVerification(cfg LocalConfig) func(cln *Client) {
+	return func(cln *Client) {
+		cln.localCfg = &cfg
+	}
+}
+
+// local verifies tokens and evaluates rules in process.
+type local struct {
+	auth *auth.Auth
+	keys *keyCache
+}
+
+func newLocal(log *logger.Logger, cln *Client) (*local, error) {
+	cfg := cln.localCfg
+
+	if cfg.DB == nil {
+		return nil, errors.New("a database is required for the revocation, enabled and session checks")
+	}
+
+	keys := keyCache{
+		url:     fmt.Sprintf("%s/.well-known/jwks.json", cln.url),
+		http:    cln.http,
+		refresh: cfg.JWKSRefresh,
+		keys:    make(map[string]string),
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:        log,
+		DB:         cfg.DB,
+		KeyLookup:  &keys,
+		Issuer:     cfg.Issuer,
+		PolicyPath: cfg.PolicyPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("constructing auth: %w", err)
+	}
+
+	l := local{
+		auth: ath,
+		keys: &keys,
+	}
+
+	return &l, nil
+}
+
+func (l *local) authenticate(ctx context.Context, authorization string) (AuthenticateResp, error) {
+
+	// API keys can only be validated by the auth service.
+	if strings.HasPrefix(authorization, "ApiKey ") {
+		return AuthenticateResp{}, errLocalUnavailable
+	}
+
+	kid, err := tokenKID(authorization)
+	if err != nil {
+		return AuthenticateResp{}, err
+	}
+
+	if !l.keys.ensure(ctx, kid) {
+		return AuthenticateResp{}, errLocalUnavailable
+	}
+
+	claims, err := l.auth.Authenticate(ctx, authorization)
+	if err != nil {
+		return AuthenticateResp{}, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return AuthenticateResp{}, fmt.Errorf("parsing subject: %w", err)
+	}
+
+	resp := AuthenticateResp{
+		UserID: userID,
+		Claims: claims,
+	}
+
+	return resp, nil
+}
+
+func (l *local) authorize(ctx context.Context, az Authorize) error {
+	if err := l.auth.AuthorizeResource(ctx, az.Claims, az.UserID, az.Rule, az.Resource); err != nil {
+		return fmt.Errorf("authorize: you are not authorized for that action, claims[%v] rule[%v]: %w", az.Claims.Roles, az.Rule, err)
+	}
+
+	return nil
+}
+
+// tokenKID extracts the kid from the header of the bearer token.
+func tokenKID(authorization string) (string, error) {
+	tkn, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return "", errors.New("expected authorization header format: Bearer <token>")
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(tkn, &jwt.RegisteredClaims{})
+	if err != nil {
+		return "", fmt.Errorf("error parsing token: %w", err)
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return "", errors.New("kid missing from header")
+	}
+
+	return kid, nil
+}
+
+// =============================================================================
+
+// keyCache maintains the set of public keys published by the auth service.
+// It implements the auth.KeyLookup interface for verification only.
+type keyCache struct {
+	url     string
+	http    *http.Client
+	refresh time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]string
+	fetched     time.Time
+	lastAttempt time.Time
+}
+
+// PrivateKey implements the auth.KeyLookup interface. Private keys are never
+// available to a client.
+func (kc *keyCache) PrivateKey(kid string) (string, error) {
+	return "", errors.New("private keys are not available")
+}
+
+// PublicKey implements the auth.KeyLookup interface.
+func (kc *keyCache) PublicKey(kid string) (string, error) {
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+
+	pem, found := kc.keys[kid]
+	if !found {
+		return "", errors.New("kid lookup failed")
+	}
+
+	return pem, nil
+}
+
+// ensure makes sure the key set is fresh and reports if the kid is known.
+// If the auth service can't be reached the keys already cached are used.
+func (kc *keyCache) ensure(ctx context.Context, kid string) bool {
+	kc.mu.RLock()
+	_, found := kc.keys[kid]
+	stale := time.Since(kc.fetched) > kc.refresh
+	recent := time.Since(kc.lastAttempt) < minKeyRefresh
+	kc.mu.RUnlock()
+
+	if (found && !stale) || recent {
+		return found
+	}
+
+	kc.fetch(ctx)
+
+	kc.mu.RLock()
+	defer kc.mu.RUnlock()
+
+	_, found = kc.keys[kid]
+	return found
+}
+
+func (kc *keyCache) fetch(ctx context.Context) {
+	kc.mu.Lock()
+	kc.lastAttempt = time.Now()
+	kc.mu.Unlock()
+
+	keys, err := kc.get(ctx)
+	if err != nil {
+		return
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	kc.keys = keys
+	kc.fetched = time.Now()
+}
+
+func (kc *keyCache) get(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kc.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := kc.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do: error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed: response: %s", string(data))
+	}
+
+	var jwks keystore.JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("decoding error: %w", err)
+	}
+
+	keys := make(map[string]string, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		pem, err := jwk.PublicKeyPEM()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = pem
+	}
+
+	return keys, nil
+}
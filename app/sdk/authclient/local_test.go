@@ -0,0 +1,203 @@
+package authclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/keystore"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func Test_LocalVerification(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_LocalVerification")
+
+	ks := keystore.New()
+	kid, err := ks.Rotate(time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       db.Log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator : %s", err)
+	}
+
+	var remoteCalls atomic.Int32
+	srv := newAuthService(ks, &remoteCalls)
+	defer srv.Close()
+
+	lc := authclient.LocalConfig{
+		DB:          db.DB,
+		Issuer:      ath.Issuer(),
+		JWKSRefresh: time.Minute,
+	}
+
+	cln := authclient.New(db.Log, srv.URL, authclient.WithLocalVerification(lc))
+
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, db.BusDomain.User)
+	if err != nil {
+		t.Fatalf("Should be able to seed a user : %s", err)
+	}
+	userID := usrs[0].ID
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    ath.Issuer(),
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{role.User.String()},
+	}
+
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	resp, err := cln.Authenticate(ctx, "Bearer "+token)
+	if err != nil {
+		t.Fatalf("Should be able to authenticate locally : %s", err)
+	}
+
+	if resp.UserID != userID {
+		t.Errorf("Exp: %s", userID)
+		t.Errorf("Got: %s", resp.UserID)
+		t.Fatal("Should get back the user id from the token")
+	}
+
+	if err := cln.Authorize(ctx, authclient.Authorize{UserID: userID, Claims: resp.Claims, Rule: auth.RuleUserOnly}); err != nil {
+		t.Fatalf("Should be able to authorize the user rule locally : %s", err)
+	}
+
+	if err := cln.Authorize(ctx, authclient.Authorize{UserID: userID, Claims: resp.Claims, Rule: auth.RuleAdminOnly}); err == nil {
+		t.Fatal("Should NOT be able to authorize the admin rule locally")
+	}
+
+	if _, err := cln.Authenticate(ctx, "Bearer "+token+"A"); err == nil {
+		t.Fatal("Should NOT be able to authenticate a token with a bad signature")
+	}
+
+	if err := db.BusDomain.Token.RevokeAccess(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatalf("Should be able to revoke the token : %s", err)
+	}
+
+	if _, err := cln.Authenticate(ctx, "Bearer "+token); err == nil {
+		t.Fatal("Should NOT be able to authenticate a revoked token locally")
+	}
+
+	if n := remoteCalls.Load(); n != 0 {
+		t.Fatalf("Should not call the auth service when keys are known : %d calls", n)
+	}
+}
+
+func Test_LocalVerificationNoDB(t *testing.T) {
+	log := newUnit(t)
+
+	ks := keystore.New()
+	kid, err := ks.Rotate(time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator : %s", err)
+	}
+
+	var remoteCalls atomic.Int32
+	srv := newAuthService(ks, &remoteCalls)
+	defer srv.Close()
+
+	lc := authclient.LocalConfig{
+		Issuer:      ath.Issuer(),
+		JWKSRefresh: time.Minute,
+	}
+
+	cln := authclient.New(log, srv.URL, authclient.WithLocalVerification(lc))
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ath.Issuer(),
+			Subject:   uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{role.User.String()},
+	}
+
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	if _, err := cln.Authenticate(context.Background(), "Bearer "+token); err == nil {
+		t.Fatal("Should NOT be able to authenticate when the auth service rejects the call")
+	}
+
+	if n := remoteCalls.Load(); n != 1 {
+		t.Fatalf("Should call the auth service when local verification is refused : %d calls", n)
+	}
+}
+
+// newAuthService starts a fake auth service that publishes the key set and
+// counts every other call it receives.
+func newAuthService(ks *keystore.KeyStore, remoteCalls *atomic.Int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		jwks, err := ks.JWKS()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(jwks)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		remoteCalls.Add(1)
+		http.Error(w, "remote call not expected", http.StatusInternalServerError)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func newUnit(t *testing.T) *logger.Logger {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "00000000-0000-0000-0000-000000000000" })
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		fmt.Println("******************** LOGS ********************")
+		fmt.Print(buf.String())
+		fmt.Println("******************** LOGS ********************")
+	})
+
+	return log
+}
@@ -8,10 +8,12 @@ import (
 )
 
 // Authorize defines the information required to perform an authorization.
+// The Resource is optional and describes what is being accessed.
 type Authorize struct {
-	UserID uuid.UUID
-	Claims auth.Claims
-	Rule   string
+	UserID   uuid.UUID
+	Claims   auth.Claims
+	Rule     string
+	Resource auth.Resource
 }
 
 // Decode implements the decoder interface.
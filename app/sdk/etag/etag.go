@@ -0,0 +1,53 @@
+// Package etag provides support for optimistic concurrency using the ETag
+// and If-Match headers.
+package etag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Set of errors for requests that fail their precondition.
+var (
+	ErrMissing  = errors.New("if-match header is required")
+	ErrMismatch = errors.New("resource has changed")
+)
+
+// Format returns the entity tag for the specified version of a resource.
+func Format(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// Set adds the entity tag for the specified version to the response.
+func Set(ctx context.Context, version int) {
+	if w := web.GetWriter(ctx); w != nil {
+		w.Header().Set("ETag", Format(version))
+	}
+}
+
+// Match checks the If-Match header of the request against the specified
+// version of the resource. The header is required, so a request without one
+// fails the same way as a request holding a stale entity tag.
+func Match(r *http.Request, version int) *errs.Error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return errs.New(errs.PreconditionFailed, ErrMissing)
+	}
+
+	tag := Format(version)
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || v == tag {
+			return nil
+		}
+	}
+
+	return errs.New(errs.PreconditionFailed, ErrMismatch)
+}
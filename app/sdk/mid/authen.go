@@ -3,6 +3,7 @@ package mid
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/http"
 	"net/mail"
 	"strings"
@@ -11,6 +12,8 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/types/role"
 	"github.com/ardanlabs/service/foundation/web"
@@ -73,8 +76,9 @@ func Bearer(ath *auth.Auth) web.MidFunc {
 	return m
 }
 
-// Basic processes basic authentication logic.
-func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
+// Basic processes basic authentication logic. Failed attempts are tracked
+// per email and remote IP and rejected once they are locked out.
+func Basic(ath *auth.Auth, userBus *userbus.Business, lockoutBus *lockoutbus.Business) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
 			email, pass, ok := parseBasicAuth(r.Header.Get("authorization"))
@@ -87,11 +91,37 @@ func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
+			emailKey := lockoutbus.EmailKey(*addr)
+			ipKey := lockoutbus.IPKey(ClientIP(ctx, r))
+
+			if err := lockoutBus.Check(ctx, emailKey, ipKey); err != nil {
+				if errors.Is(err, lockoutbus.ErrLocked) {
+					return LockoutError(ctx, err)
+				}
+				return errs.Newf(errs.Internal, "lockout check: %s", err)
+			}
+
 			usr, err := userBus.Authenticate(ctx, *addr, pass)
 			if err != nil {
+				if errors.Is(err, userbus.ErrNotFound) || errors.Is(err, userbus.ErrAuthenticationFailure) {
+					if err := lockoutBus.Fail(ctx, emailKey, ipKey); err != nil {
+						return errs.Newf(errs.Internal, "lockout fail: %s", err)
+					}
+				}
 				return errs.New(errs.Unauthenticated, err)
 			}
 
+			if err := lockoutBus.Reset(ctx, emailKey); err != nil {
+				return errs.Newf(errs.Internal, "lockout reset: %s", err)
+			}
+
+			roles := role.ParseToString(usr.Roles)
+
+			perms, err := ath.Permissions(ctx, usr.ID, roles)
+			if err != nil {
+				return errs.Newf(errs.Internal, "permissions: %s", err)
+			}
+
 			claims := auth.Claims{
 				RegisteredClaims: jwt.RegisteredClaims{
 					Subject:   usr.ID.String(),
@@ -99,7 +129,9 @@ func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 					ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
 					IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 				},
-				Roles: role.ParseToString(usr.Roles),
+				Roles:       roles,
+				Permissions: perms,
+				Tenant:      usr.OrgID.String(),
 			}
 
 			subjectID, err := uuid.Parse(claims.Subject)
@@ -119,6 +151,77 @@ func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 	return m
 }
 
+// APIKey processes api key authentication logic. Requests that carry an api
+// key using the ApiKey authorization scheme are authenticated with the key,
+// all other requests are handed to the fallback middleware. If no fallback is
+// provided, requests without an api key are rejected.
+func APIKey(ath *auth.Auth, apiKeyBus *apikeybus.Business, fallback web.MidFunc) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		var fb web.HandlerFunc
+		if fallback != nil {
+			fb = fallback(next)
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			raw, ok := parseAPIKey(r.Header.Get("authorization"))
+			if !ok {
+				if fb != nil {
+					return fb(ctx, r)
+				}
+				return errs.Newf(errs.Unauthenticated, "expected authorization header format: ApiKey <key>")
+			}
+
+			key, err := apiKeyBus.Authenticate(ctx, raw)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			// An api key is scoped to its roles so the custom roles
+			// assigned to the user are not included.
+			roles := role.ParseToString(key.Roles)
+
+			perms, err := ath.Permissions(ctx, uuid.Nil, roles)
+			if err != nil {
+				return errs.Newf(errs.Internal, "permissions: %s", err)
+			}
+
+			claims := auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ID:       key.ID.String(),
+					Subject:  key.UserID.String(),
+					Issuer:   ath.Issuer(),
+					IssuedAt: jwt.NewNumericDate(key.DateCreated.UTC()),
+				},
+				Roles:       roles,
+				Permissions: perms,
+				Tenant:      key.OrgID.String(),
+			}
+
+			if !key.DateExpires.IsZero() {
+				claims.ExpiresAt = jwt.NewNumericDate(key.DateExpires.UTC())
+			}
+
+			ctx = setUserID(ctx, key.UserID)
+			ctx = setClaims(ctx, claims)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func parseAPIKey(auth string) (string, bool) {
+	scheme, key, ok := strings.Cut(auth, " ")
+	if !ok || scheme != "ApiKey" || key == "" {
+		return "", false
+	}
+
+	return key, true
+}
+
 func parseBasicAuth(auth string) (string, string, bool) {
 	parts := strings.Split(auth, " ")
 	if len(parts) != 2 || parts[0] != "Basic" {
@@ -12,6 +12,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/types/role"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
 )
@@ -32,6 +33,45 @@ func Authorize(client *authclient.Client, rule string) web.MidFunc {
 				Claims: GetClaims(ctx),
 				UserID: userID,
 				Rule:   rule,
+				Resource: auth.Resource{
+					Action: auth.ActionFromMethod(r.Method),
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			if err := client.Authorize(ctx, auth); err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizePermission validates via the auth service that the claims grant
+// the permission to perform the request's action on the resource type.
+func AuthorizePermission(client *authclient.Client, resourceType string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			auth := authclient.Authorize{
+				Claims: GetClaims(ctx),
+				UserID: userID,
+				Rule:   auth.RulePermission,
+				Resource: auth.Resource{
+					Type:   resourceType,
+					Action: auth.ActionFromMethod(r.Method),
+				},
 			}
 
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -60,6 +100,10 @@ func AuthorizeUser(client *authclient.Client, userBus *userbus.Business, rule st
 			id := web.Param(r, "user_id")
 
 			var userID uuid.UUID
+			resource := auth.Resource{
+				Type:   "user",
+				Action: auth.ActionFromMethod(r.Method),
+			}
 
 			if id != "" {
 				var err error
@@ -78,6 +122,13 @@ func AuthorizeUser(client *authclient.Client, userBus *userbus.Business, rule st
 					}
 				}
 
+				// A user in another tenant is reported as not found so
+				// its existence isn't revealed.
+				if !inTenant(ctx, usr.OrgID) {
+					return errs.New(errs.Unauthenticated, userbus.ErrNotFound)
+				}
+
+				resource.Attributes = userAttributes(usr)
 				ctx = setUser(ctx, usr)
 			}
 
@@ -85,9 +136,10 @@ func AuthorizeUser(client *authclient.Client, userBus *userbus.Business, rule st
 			defer cancel()
 
 			auth := authclient.Authorize{
-				Claims: GetClaims(ctx),
-				UserID: userID,
-				Rule:   rule,
+				Claims:   GetClaims(ctx),
+				UserID:   userID,
+				Rule:     rule,
+				Resource: resource,
 			}
 
 			if err := client.Authorize(ctx, auth); err != nil {
@@ -113,6 +165,10 @@ func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business
 			id := web.Param(r, "product_id")
 
 			var userID uuid.UUID
+			resource := auth.Resource{
+				Type:   "product",
+				Action: auth.ActionFromMethod(r.Method),
+			}
 
 			if id != "" {
 				var err error
@@ -131,7 +187,12 @@ func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business
 					}
 				}
 
+				if !inTenant(ctx, prd.OrgID) {
+					return errs.New(errs.Unauthenticated, productbus.ErrNotFound)
+				}
+
 				userID = prd.UserID
+				resource.Attributes = productAttributes(prd)
 				ctx = setProduct(ctx, prd)
 			}
 
@@ -139,9 +200,10 @@ func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business
 			defer cancel()
 
 			auth := authclient.Authorize{
-				UserID: userID,
-				Claims: GetClaims(ctx),
-				Rule:   auth.RuleAdminOrSubject,
+				UserID:   userID,
+				Claims:   GetClaims(ctx),
+				Rule:     auth.RuleAdminOrSubject,
+				Resource: resource,
 			}
 
 			if err := client.Authorize(ctx, auth); err != nil {
@@ -167,6 +229,10 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 			id := web.Param(r, "home_id")
 
 			var userID uuid.UUID
+			resource := auth.Resource{
+				Type:   "home",
+				Action: auth.ActionFromMethod(r.Method),
+			}
 
 			if id != "" {
 				var err error
@@ -185,7 +251,12 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 					}
 				}
 
+				if !inTenant(ctx, hme.OrgID) {
+					return errs.New(errs.Unauthenticated, homebus.ErrNotFound)
+				}
+
 				userID = hme.UserID
+				resource.Attributes = homeAttributes(hme)
 				ctx = setHome(ctx, hme)
 			}
 
@@ -193,9 +264,10 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 			defer cancel()
 
 			auth := authclient.Authorize{
-				Claims: GetClaims(ctx),
-				UserID: userID,
-				Rule:   auth.RuleAdminOrSubject,
+				Claims:   GetClaims(ctx),
+				UserID:   userID,
+				Rule:     auth.RuleAdminOrSubject,
+				Resource: resource,
 			}
 
 			if err := client.Authorize(ctx, auth); err != nil {
@@ -210,3 +282,51 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 
 	return m
 }
+
+// inTenant reports if the organisation matches the tenant in the claims.
+func inTenant(ctx context.Context, orgID uuid.UUID) bool {
+	tenant, err := GetTenant(ctx)
+	if err != nil {
+		return false
+	}
+
+	return tenant == orgID
+}
+
+// =============================================================================
+
+// These functions produce the attributes of a resource that are available to
+// rules. Only values that are useful for making decisions are included.
+
+func userAttributes(usr userbus.User) map[string]any {
+	return map[string]any{
+		"id":         usr.ID.String(),
+		"roles":      role.ParseToString(usr.Roles),
+		"department": usr.Department.String(),
+		"enabled":    usr.Enabled,
+		"orgID":      usr.OrgID.String(),
+	}
+}
+
+func productAttributes(prd productbus.Product) map[string]any {
+	return map[string]any{
+		"id":       prd.ID.String(),
+		"userID":   prd.UserID.String(),
+		"name":     prd.Name.String(),
+		"cost":     prd.Cost.Value(),
+		"quantity": prd.Quantity.Value(),
+		"orgID":    prd.OrgID.String(),
+	}
+}
+
+func homeAttributes(hme homebus.Home) map[string]any {
+	return map[string]any{
+		"id":      hme.ID.String(),
+		"userID":  hme.UserID.String(),
+		"type":    hme.Type.String(),
+		"city":    hme.Address.City,
+		"state":   hme.Address.State,
+		"country": hme.Address.Country,
+		"orgID":   hme.OrgID.String(),
+	}
+}
@@ -0,0 +1,98 @@
+package mid
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ClientAddr works out the IP address of the remote client. Requests that
+// come through one of the trusted proxies are attributed to the address the
+// proxies added to the X-Forwarded-For header. The header is ignored for any
+// other request since the client can set it to anything.
+func ClientAddr(trusted []netip.Prefix) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			ctx = setClientIP(ctx, clientIP(r, trusted))
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// ClientIP returns the IP address of the remote client. It falls back to the
+// address of the connection when the ClientAddr middleware didn't run.
+func ClientIP(ctx context.Context, r *http.Request) string {
+	if ip, ok := ctx.Value(clientIPKey).(string); ok {
+		return ip
+	}
+
+	return remoteIP(r)
+}
+
+func setClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// clientIP walks the X-Forwarded-For header from the right, since each proxy
+// appends the address it received the request from, and returns the first
+// address that isn't one of the trusted proxies.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	ip := remoteIP(r)
+
+	if !isTrusted(ip, trusted) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+
+		ip = hop
+
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    []string
+		exp    string
+	}{
+		{name: "direct", remote: "203.0.113.7:5000", exp: "203.0.113.7"},
+		{name: "untrusted-proxy", remote: "203.0.113.7:5000", xff: []string{"198.51.100.1"}, exp: "203.0.113.7"},
+		{name: "trusted-proxy", remote: "10.1.2.3:5000", xff: []string{"198.51.100.1"}, exp: "198.51.100.1"},
+		{name: "trusted-no-header", remote: "10.1.2.3:5000", exp: "10.1.2.3"},
+		{name: "spoofed-left", remote: "10.1.2.3:5000", xff: []string{"1.2.3.4, 198.51.100.1"}, exp: "198.51.100.1"},
+		{name: "proxy-chain", remote: "10.1.2.3:5000", xff: []string{"198.51.100.1, 192.168.1.1"}, exp: "198.51.100.1"},
+		{name: "many-headers", remote: "10.1.2.3:5000", xff: []string{"1.2.3.4", "198.51.100.1"}, exp: "198.51.100.1"},
+		{name: "garbage", remote: "10.1.2.3:5000", xff: []string{"198.51.100.1, bad"}, exp: "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			var got string
+			h := func(ctx context.Context, r *http.Request) web.Encoder {
+				got = mid.ClientIP(ctx, r)
+				return nil
+			}
+
+			mid.ClientAddr(trusted)(h)(context.Background(), r)
+
+			if got != tt.exp {
+				t.Fatalf("Should get the expected client ip : got %s, exp %s", got, tt.exp)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:5000"
+
+	if got := mid.ClientIP(context.Background(), r); got != "203.0.113.7" {
+		t.Fatalf("Should fall back to the remote address without the middleware : got %s", got)
+	}
+}
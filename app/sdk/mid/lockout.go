@@ -0,0 +1,27 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// LockoutError constructs the error response for a login that was rejected
+// because of too many failed attempts. When the lockout duration is known the
+// Retry-After header is set so the client knows when it can try again.
+func LockoutError(ctx context.Context, err error) *errs.Error {
+	var le *lockoutbus.LockedError
+	if errors.As(err, &le) {
+		if w := web.GetWriter(ctx); w != nil {
+			secs := int(math.Ceil(le.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+		}
+	}
+
+	return errs.New(errs.ResourceExhausted, lockoutbus.ErrLocked)
+}
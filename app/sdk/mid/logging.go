@@ -12,7 +12,8 @@ import (
 	"github.com/ardanlabs/service/foundation/web"
 )
 
-// Logger writes information about the request to the logs.
+// Logger writes information about the request to the logs. Requests made
+// with an impersonation token are also logged with the actor and subject.
 func Logger(log *logger.Logger) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
@@ -25,6 +26,9 @@ func Logger(log *logger.Logger) web.MidFunc {
 
 			log.Info(ctx, "request started", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr)
 
+			var act actor
+			ctx = setActor(ctx, &act)
+
 			resp := next(ctx, r)
 			err := isError(resp)
 
@@ -41,6 +45,11 @@ func Logger(log *logger.Logger) web.MidFunc {
 			log.Info(ctx, "request completed", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr,
 				"statuscode", statusCode, "since", time.Since(now).String())
 
+			if act.actor != "" {
+				log.Info(ctx, "impersonated request", "method", r.Method, "path", path, "actor", act.actor,
+					"subject", act.subject, "statuscode", statusCode)
+			}
+
 			return resp
 		}
 
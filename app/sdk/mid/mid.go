@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
@@ -34,9 +35,34 @@ const (
 	productKey
 	homeKey
 	trKey
+	actorKey
+	clientIPKey
 )
 
+// actor records who is acting as the subject of the request when an
+// impersonation token is used. The Logger middleware runs before the claims
+// are known, so it places a value in the context that is filled in once the
+// request is authenticated.
+type actor struct {
+	actor   string
+	subject string
+}
+
+func setActor(ctx context.Context, act *actor) context.Context {
+	return context.WithValue(ctx, actorKey, act)
+}
+
 func setClaims(ctx context.Context, claims auth.Claims) context.Context {
+	if act, ok := ctx.Value(actorKey).(*actor); ok && claims.Actor != nil {
+		act.actor = claims.Actor.Subject
+		act.subject = claims.Subject
+	}
+
+	// Changes made by the business layer are recorded against the subject.
+	if subjectID, err := uuid.Parse(claims.Subject); err == nil {
+		ctx = auditbus.SetActor(ctx, subjectID)
+	}
+
 	return context.WithValue(ctx, claimKey, claims)
 }
 
@@ -63,6 +89,17 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	return v, nil
 }
 
+// GetTenant returns the organisation the caller belongs to from the claims
+// in the context.
+func GetTenant(ctx context.Context) (uuid.UUID, error) {
+	tenant, err := uuid.Parse(GetClaims(ctx).Tenant)
+	if err != nil {
+		return uuid.UUID{}, errors.New("tenant not found in claims")
+	}
+
+	return tenant, nil
+}
+
 func setUser(ctx context.Context, usr userbus.User) context.Context {
 	return context.WithValue(ctx, userKey, usr)
 }
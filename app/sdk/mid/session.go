@@ -0,0 +1,52 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+)
+
+// maxUserAgent limits how much of the user agent is recorded for a session.
+const maxUserAgent = 512
+
+// SessionClient returns the details of the client making the request that
+// are recorded for the session a token is issued to.
+func SessionClient(ctx context.Context, r *http.Request) sessionbus.Client {
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgent {
+		ua = ua[:maxUserAgent]
+	}
+
+	client := sessionbus.Client{
+		Device:    deviceFromUserAgent(ua),
+		IPAddress: ClientIP(ctx, r),
+		UserAgent: ua,
+	}
+
+	return client
+}
+
+// deviceFromUserAgent makes a coarse guess at the kind of device from the
+// user agent, which is good enough for a user to recognize their sessions.
+func deviceFromUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+
+	switch {
+	case lower == "":
+		return "unknown"
+
+	case strings.Contains(lower, "ipad"), strings.Contains(lower, "tablet"):
+		return "tablet"
+
+	case strings.Contains(lower, "mobi"), strings.Contains(lower, "iphone"), strings.Contains(lower, "android"):
+		return "mobile"
+
+	case strings.Contains(lower, "windows"), strings.Contains(lower, "macintosh"), strings.Contains(lower, "x11"), strings.Contains(lower, "cros"):
+		return "desktop"
+
+	default:
+		return "other"
+	}
+}
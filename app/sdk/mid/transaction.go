@@ -37,6 +37,15 @@ func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner) web.MidFunc {
 				}
 			}()
 
+			// Scope the transaction to the caller's tenant so row level
+			// security backs up the tenant filters and checks. Only calls
+			// made inside a transaction get this, the rest rely on them.
+			if tenant, err := GetTenant(ctx); err == nil {
+				if err := sqldb.SetTenant(ctx, log, tx, tenant); err != nil {
+					return errs.Newf(errs.Internal, "SET TENANT: %s", err)
+				}
+			}
+
 			ctx = setTran(ctx, tx)
 
 			resp := next(ctx, r)
@@ -6,11 +6,18 @@ import (
 	"context"
 	"embed"
 	"net/http"
+	"net/netip"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/oauth"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/mailer"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
@@ -26,8 +33,9 @@ type StaticSite struct {
 
 // Options represent optional parameters.
 type Options struct {
-	corsOrigin []string
-	sites      []StaticSite
+	corsOrigin     []string
+	trustedProxies []netip.Prefix
+	sites          []StaticSite
 }
 
 // WithCORS provides configuration options for CORS.
@@ -37,6 +45,15 @@ func WithCORS(origins []string) func(opts *Options) {
 	}
 }
 
+// WithTrustedProxies provides the addresses of the proxies in front of the
+// service that are trusted to report the client address in the
+// X-Forwarded-For header.
+func WithTrustedProxies(proxies []netip.Prefix) func(opts *Options) {
+	return func(opts *Options) {
+		opts.trustedProxies = proxies
+	}
+}
+
 // WithFileServer provides configuration options for file server.
 func WithFileServer(react bool, static embed.FS, dir string, path string) func(opts *Options) {
 	return func(opts *Options) {
@@ -49,14 +66,27 @@ func WithFileServer(react bool, static embed.FS, dir string, path string) func(o
 	}
 }
 
-// SalesConfig contains sales service specific config.
+// SalesConfig contains sales service specific config. The Delegate is shared
+// with the outbox relay so events reach the functions registered by the
+// routes.
 type SalesConfig struct {
 	AuthClient *authclient.Client
+	Delegate   *delegate.Delegate
 }
 
 // AuthConfig contains auth service specific config.
 type AuthConfig struct {
-	Auth *auth.Auth
+	Auth             *auth.Auth
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	ImpersonationTTL time.Duration
+	Mailer           mailer.Mailer
+	AppURL           string
+	OAuth            *oauth.Registry
+	OAuthUIURL       string
+	OAuthTrusted     []string
+	Lockout          lockoutbus.Config
+	MFA              mfabus.Config
 }
 
 // Config contains all the mandatory systems required by handlers.
@@ -81,6 +111,11 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 		cfg.Log.Info(ctx, msg, args...)
 	}
 
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
 	app := web.NewApp(
 		logger,
 		cfg.Tracer,
@@ -89,13 +124,9 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
 		mid.Panics(),
+		mid.ClientAddr(opts.trustedProxies),
 	)
 
-	var opts Options
-	for _, option := range options {
-		option(&opts)
-	}
-
 	if len(opts.corsOrigin) > 0 {
 		app.EnableCORS(opts.corsOrigin)
 	}
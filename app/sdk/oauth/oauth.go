@@ -0,0 +1,266 @@
+// Package oauth provides support for signing users in through external
+// OAuth2 and OpenID Connect identity providers. Each login is protected with
+// a CSRF state value and PKCE. The state and verifier are kept in a signed,
+// short lived cookie so no server side session store is required.
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrUnknownProvider is returned when a provider is not enabled.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// ErrInvalidState is returned when the state returned by the provider
+// doesn't match the one issued at the start of the login.
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// stateTTL is how long a user has to complete the login at the provider.
+const stateTTL = 10 * time.Minute
+
+// Config represents the information required to construct a registry.
+type Config struct {
+	Providers []ProviderConfig
+	StateKey  []byte
+	Secure    bool
+	Client    *http.Client
+}
+
+// Registry maintains the set of enabled providers.
+type Registry struct {
+	providers map[string]*Provider
+	stateKey  []byte
+	secure    bool
+	client    *http.Client
+}
+
+// New constructs a registry with the specified providers enabled. OIDC
+// providers perform discovery so the context should allow for network calls.
+func New(ctx context.Context, cfg Config) (*Registry, error) {
+	if len(cfg.StateKey) < 32 {
+		return nil, errors.New("state key must be at least 32 bytes")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	r := Registry{
+		providers: make(map[string]*Provider),
+		stateKey:  cfg.StateKey,
+		secure:    cfg.Secure,
+		client:    client,
+	}
+
+	for _, pc := range cfg.Providers {
+		p, err := newProvider(ctx, client, pc)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := r.providers[p.name]; exists {
+			return nil, fmt.Errorf("provider[%s]: already registered", p.name)
+		}
+
+		r.providers[p.name] = p
+	}
+
+	return &r, nil
+}
+
+// Providers returns the names of the enabled providers.
+func (r *Registry) Providers() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Begin starts a login with the specified provider. It sets the state cookie
+// on the response and returns the url the user should be redirected to.
+func (r *Registry) Begin(w http.ResponseWriter, provider string) (string, error) {
+	p, exists := r.providers[provider]
+	if !exists {
+		return "", ErrUnknownProvider
+	}
+
+	st, err := newState(provider)
+	if err != nil {
+		return "", fmt.Errorf("newstate: %w", err)
+	}
+
+	value, err := r.signState(st)
+	if err != nil {
+		return "", fmt.Errorf("signstate: %w", err)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName(provider),
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return p.config.AuthCodeURL(st.State, oauth2.S256ChallengeOption(st.Verifier)), nil
+}
+
+// Complete finishes a login with the specified provider. The state returned
+// by the provider is validated against the cookie, the code is exchanged
+// using the PKCE verifier and the user information is fetched.
+func (r *Registry) Complete(w http.ResponseWriter, req *http.Request, provider string) (User, error) {
+	p, exists := r.providers[provider]
+	if !exists {
+		return User{}, ErrUnknownProvider
+	}
+
+	cookie, err := req.Cookie(cookieName(provider))
+	if err != nil {
+		return User{}, fmt.Errorf("cookie: %w", ErrInvalidState)
+	}
+
+	// The cookie is single use regardless of the outcome.
+	r.clearCookie(w, provider)
+
+	st, err := r.verifyState(cookie.Value)
+	if err != nil {
+		return User{}, err
+	}
+
+	q := req.URL.Query()
+
+	if st.Provider != provider || subtle.ConstantTimeCompare([]byte(st.State), []byte(q.Get("state"))) != 1 {
+		return User{}, ErrInvalidState
+	}
+
+	if msg := q.Get("error"); msg != "" {
+		return User{}, fmt.Errorf("provider error: %s: %s", msg, q.Get("error_description"))
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		return User{}, errors.New("missing code")
+	}
+
+	ctx := context.WithValue(req.Context(), oauth2.HTTPClient, r.client)
+
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return User{}, fmt.Errorf("exchange: %w", err)
+	}
+
+	usr, err := p.fetchUser(req.Context(), token)
+	if err != nil {
+		return User{}, fmt.Errorf("fetchuser: %w", err)
+	}
+
+	return usr, nil
+}
+
+// Clear removes any pending login state for the provider.
+func (r *Registry) Clear(w http.ResponseWriter, provider string) {
+	r.clearCookie(w, provider)
+}
+
+// =============================================================================
+
+type state struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	Expires  int64  `json:"e"`
+}
+
+func newState(provider string) (state, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return state{}, err
+	}
+
+	st := state{
+		Provider: provider,
+		State:    base64.RawURLEncoding.EncodeToString(b),
+		Verifier: oauth2.GenerateVerifier(),
+		Expires:  time.Now().Add(stateTTL).Unix(),
+	}
+
+	return st, nil
+}
+
+func (r *Registry) signState(st state) (string, error) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + r.sign(payload), nil
+}
+
+func (r *Registry) verifyState(value string) (state, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return state{}, ErrInvalidState
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(r.sign(payload))) {
+		return state{}, ErrInvalidState
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return state{}, ErrInvalidState
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return state{}, ErrInvalidState
+	}
+
+	if time.Now().Unix() > st.Expires {
+		return state{}, fmt.Errorf("expired: %w", ErrInvalidState)
+	}
+
+	return st, nil
+}
+
+func (r *Registry) sign(payload string) string {
+	mac := hmac.New(sha256.New, r.stateKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (r *Registry) clearCookie(w http.ResponseWriter, provider string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName(provider),
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func cookieName(provider string) string {
+	return "oauth_" + provider
+}
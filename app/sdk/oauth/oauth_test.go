@@ -0,0 +1,172 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/oauth"
+)
+
+const stateKey = "0123456789abcdef0123456789abcdef"
+
+func Test_OIDCLogin(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.Close()
+
+	reg := newRegistry(t, provider.URL)
+
+	// Start the login and capture the redirect and the state cookie.
+	w := httptest.NewRecorder()
+
+	authURL, err := reg.Begin(w, "fake")
+	if err != nil {
+		t.Fatalf("Should be able to begin the login : %s", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Should be able to parse the auth url : %s", err)
+	}
+
+	q := u.Query()
+
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("Should send a PKCE challenge : %s", authURL)
+	}
+
+	if q.Get("state") == "" {
+		t.Fatalf("Should send a state value : %s", authURL)
+	}
+
+	challenge = q.Get("code_challenge")
+
+	// Complete the login as if the provider redirected back.
+	usr, err := reg.Complete(httptest.NewRecorder(), callback(w, q.Get("state")), "fake")
+	if err != nil {
+		t.Fatalf("Should be able to complete the login : %s", err)
+	}
+
+	if usr.ID != "user-1" || usr.Email != "bill@ardanlabs.com" || !usr.EmailVerified || usr.Provider != "fake" {
+		t.Errorf("Got: %+v", usr)
+		t.Error("Should get the user from the provider")
+	}
+}
+
+func Test_InvalidState(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.Close()
+
+	reg := newRegistry(t, provider.URL)
+
+	w := httptest.NewRecorder()
+	if _, err := reg.Begin(w, "fake"); err != nil {
+		t.Fatalf("Should be able to begin the login : %s", err)
+	}
+
+	_, err := reg.Complete(httptest.NewRecorder(), callback(w, "forged"), "fake")
+	if !errors.Is(err, oauth.ErrInvalidState) {
+		t.Fatalf("Should reject a state that doesn't match the cookie : %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/auth/fake/callback?code=abc&state=abc", nil)
+	r.AddCookie(&http.Cookie{Name: "oauth_fake", Value: "payload.signature"})
+
+	_, err = reg.Complete(httptest.NewRecorder(), r, "fake")
+	if !errors.Is(err, oauth.ErrInvalidState) {
+		t.Fatalf("Should reject a cookie with a bad signature : %v", err)
+	}
+
+	if _, err := reg.Begin(httptest.NewRecorder(), "unknown"); !errors.Is(err, oauth.ErrUnknownProvider) {
+		t.Fatalf("Should reject an unknown provider : %v", err)
+	}
+}
+
+// =============================================================================
+
+// challenge holds the PKCE challenge sent to the fake provider so the token
+// endpoint can check the verifier.
+var challenge string
+
+func newRegistry(t *testing.T, issuer string) *oauth.Registry {
+	reg, err := oauth.New(context.Background(), oauth.Config{
+		Providers: []oauth.ProviderConfig{
+			{
+				Name:        "fake",
+				Kind:        oauth.KindOIDC,
+				ClientID:    "client",
+				CallbackURL: "http://localhost/api/auth/fake/callback",
+				IssuerURL:   issuer,
+			},
+		},
+		StateKey: []byte(stateKey),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the registry : %s", err)
+	}
+
+	return reg
+}
+
+func callback(w *httptest.ResponseRecorder, state string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/auth/fake/callback?code=good-code&state="+url.QueryEscape(state), nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	return r
+}
+
+func newFakeProvider(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			t.Logf("token request rejected: %v", r.Form)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"sub":            "user-1",
+			"email":          "bill@ardanlabs.com",
+			"email_verified": true,
+			"name":           "Bill Kennedy",
+		})
+	})
+
+	return srv
+}
@@ -0,0 +1,335 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Set of provider kinds that are supported.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindOIDC   = "oidc"
+)
+
+// ProviderConfig represents the information required to enable a provider.
+// The Name is the value used in the url path and recorded with the linked
+// identity. For OIDC providers the endpoints are discovered from the issuer.
+type ProviderConfig struct {
+	Name         string
+	Kind         string
+	ClientID     string
+	ClientSecret string
+	CallbackURL  string
+	Scopes       []string
+
+	// BaseURL is used by GitLab to support self hosted instances.
+	BaseURL string
+
+	// IssuerURL is used by OIDC providers for discovery.
+	IssuerURL string
+}
+
+// User represents the information an identity provider returns about the
+// user that signed in.
+type User struct {
+	Provider      string
+	ID            string
+	Email         string
+	EmailVerified bool
+	Name          string
+	NickName      string
+}
+
+// Provider represents an enabled identity provider.
+type Provider struct {
+	name        string
+	kind        string
+	config      oauth2.Config
+	userInfoURL string
+	apiURL      string
+	client      *http.Client
+}
+
+// Name returns the name of the provider.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func newProvider(ctx context.Context, client *http.Client, cfg ProviderConfig) (*Provider, error) {
+	if cfg.Name == "" {
+		cfg.Name = cfg.Kind
+	}
+
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("provider[%s]: client id is required", cfg.Name)
+	}
+
+	if cfg.CallbackURL == "" {
+		return nil, fmt.Errorf("provider[%s]: callback url is required", cfg.Name)
+	}
+
+	p := Provider{
+		name:   cfg.Name,
+		kind:   cfg.Kind,
+		client: client,
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.CallbackURL,
+			Scopes:       cfg.Scopes,
+		},
+	}
+
+	switch cfg.Kind {
+	case KindGitHub:
+		p.config.Endpoint = oauth2.Endpoint{
+			AuthURL:  "https://github.com/login/oauth/authorize",
+			TokenURL: "https://github.com/login/oauth/access_token",
+		}
+		p.apiURL = "https://api.github.com"
+		if len(p.config.Scopes) == 0 {
+			p.config.Scopes = []string{"read:user", "user:email"}
+		}
+
+	case KindGitLab:
+		base := strings.TrimSuffix(cfg.BaseURL, "/")
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+		p.config.Endpoint = oauth2.Endpoint{
+			AuthURL:  base + "/oauth/authorize",
+			TokenURL: base + "/oauth/token",
+		}
+		p.apiURL = base + "/api/v4"
+		if len(p.config.Scopes) == 0 {
+			p.config.Scopes = []string{"read_user"}
+		}
+
+	case KindOIDC:
+		doc, err := discover(ctx, client, cfg.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("provider[%s]: %w", cfg.Name, err)
+		}
+		p.config.Endpoint = oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		}
+		p.userInfoURL = doc.UserInfoEndpoint
+		if len(p.config.Scopes) == 0 {
+			p.config.Scopes = []string{"openid", "email", "profile"}
+		}
+
+	default:
+		return nil, fmt.Errorf("provider[%s]: unknown kind %q", cfg.Name, cfg.Kind)
+	}
+
+	return &p, nil
+}
+
+// fetchUser retrieves the user information for the access token.
+func (p *Provider) fetchUser(ctx context.Context, token *oauth2.Token) (User, error) {
+	var usr User
+	var err error
+
+	switch p.kind {
+	case KindGitHub:
+		usr, err = p.fetchGitHubUser(ctx, token)
+	case KindGitLab:
+		usr, err = p.fetchGitLabUser(ctx, token)
+	default:
+		usr, err = p.fetchOIDCUser(ctx, token)
+	}
+
+	if err != nil {
+		return User{}, err
+	}
+
+	if usr.ID == "" {
+		return User{}, errors.New("provider did not return a user id")
+	}
+
+	usr.Provider = p.name
+
+	return usr, nil
+}
+
+func (p *Provider) fetchGitHubUser(ctx context.Context, token *oauth2.Token) (User, error) {
+	var gu struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+
+	if err := p.getJSON(ctx, token, p.apiURL+"/user", &gu); err != nil {
+		return User{}, err
+	}
+
+	// The email on the profile is only set when the user made it public, so
+	// the primary email is looked up separately along with its verification.
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+
+	if err := p.getJSON(ctx, token, p.apiURL+"/user/emails", &emails); err != nil {
+		return User{}, err
+	}
+
+	usr := User{
+		ID:       strconv.FormatInt(gu.ID, 10),
+		Name:     gu.Name,
+		NickName: gu.Login,
+	}
+
+	for _, e := range emails {
+		if e.Primary {
+			usr.Email = e.Email
+			usr.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return usr, nil
+}
+
+func (p *Provider) fetchGitLabUser(ctx context.Context, token *oauth2.Token) (User, error) {
+	var gu struct {
+		ID          int64  `json:"id"`
+		Username    string `json:"username"`
+		Name        string `json:"name"`
+		Email       string `json:"email"`
+		ConfirmedAt string `json:"confirmed_at"`
+	}
+
+	if err := p.getJSON(ctx, token, p.apiURL+"/user", &gu); err != nil {
+		return User{}, err
+	}
+
+	usr := User{
+		ID:            strconv.FormatInt(gu.ID, 10),
+		Email:         gu.Email,
+		EmailVerified: gu.ConfirmedAt != "",
+		Name:          gu.Name,
+		NickName:      gu.Username,
+	}
+
+	return usr, nil
+}
+
+func (p *Provider) fetchOIDCUser(ctx context.Context, token *oauth2.Token) (User, error) {
+	var ou struct {
+		Subject           string `json:"sub"`
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+
+	if err := p.getJSON(ctx, token, p.userInfoURL, &ou); err != nil {
+		return User{}, err
+	}
+
+	// Some providers send email_verified as a string.
+	var verified bool
+	switch v := ou.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	usr := User{
+		ID:            ou.Subject,
+		Email:         ou.Email,
+		EmailVerified: verified,
+		Name:          ou.Name,
+		NickName:      ou.PreferredUsername,
+	}
+
+	return usr, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, token *oauth2.Token, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	token.SetAuthHeader(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: status[%d]: %s", url, resp.StatusCode, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+type discoveryDoc struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// discover retrieves the OpenID Connect discovery document for the issuer.
+func discover(ctx context.Context, client *http.Client, issuer string) (discoveryDoc, error) {
+	if issuer == "" {
+		return discoveryDoc{}, errors.New("issuer url is required")
+	}
+
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return discoveryDoc{}, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return discoveryDoc{}, fmt.Errorf("discovery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return discoveryDoc{}, fmt.Errorf("discovery: status[%d]", resp.StatusCode)
+	}
+
+	var doc discoveryDoc
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return discoveryDoc{}, fmt.Errorf("discovery: decode: %w", err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return discoveryDoc{}, fmt.Errorf("discovery: issuer mismatch %q", doc.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserInfoEndpoint == "" {
+		return discoveryDoc{}, errors.New("discovery: missing endpoints")
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,261 @@
+// Package patch provides support for applying RFC 7396 JSON merge patches and
+// RFC 6902 JSON patches to the JSON document of a resource.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+// Set of media types that select the format of a patch.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// MaxSize is the largest patch in bytes that is read from a request.
+const MaxSize = 1 << 20
+
+// Set of errors for patches that can't be applied.
+var (
+	ErrUnsupportedType = errors.New("unsupported patch media type")
+	ErrTooLarge        = errors.New("patch is too large")
+	ErrTestFailed      = errors.New("test operation failed")
+)
+
+// Apply reads the patch from the body of the request and applies it to the
+// specified document. The Content-Type header of the request selects the
+// format of the patch. A patch larger than MaxSize is rejected.
+func Apply(r *http.Request, doc []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != MergePatchType && mediaType != JSONPatchType {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedType, mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("request: unable to read payload: %w", err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("%w: limit %d bytes", ErrTooLarge, MaxSize)
+	}
+
+	if mediaType == MergePatchType {
+		return Merge(doc, data)
+	}
+
+	return JSON(doc, data)
+}
+
+// Decode unmarshals a patched document into the specified model. Members the
+// model doesn't know and values of the wrong type are reported as field
+// errors, since a patch can put anything into the document.
+func Decode(doc []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors errs.FieldErrors
+
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		fieldErrors.Add(typeErr.Field, fmt.Errorf("must be of type %s", typeErr.Type))
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		fieldErrors.Add(strings.Trim(field, `"`), errors.New("unknown field"))
+
+	default:
+		return err
+	}
+
+	return fieldErrors
+}
+
+// =============================================================================
+
+// Merge applies an RFC 7396 merge patch to the specified document. A member
+// set to null in the patch is removed from the document.
+func Merge(doc []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	var p any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal merge patch: %w", err)
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+func merge(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = merge(t[k], v)
+	}
+
+	return t
+}
+
+// =============================================================================
+
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSON applies an RFC 6902 JSON patch to the specified document. The
+// operations are applied in order and the patch fails as a whole when any
+// of them fails.
+func JSON(doc []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("unmarshal json patch: %w", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		target, err = op.apply(target)
+		if err != nil {
+			return nil, fmt.Errorf("operation[%d]: %s: %w", i, op.Op, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func (op operation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, errors.New("missing path")
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(path) == 0 {
+			return value, nil
+		}
+
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errors.New("missing from")
+		}
+
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			value, err := get(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, path, clone(value))
+		}
+
+		if len(path) > len(from) && isPrefix(from, path) {
+			return nil, fmt.Errorf("can't move %q into one of its children", *op.From)
+		}
+
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+
+		got, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal(got, value) {
+			return nil, fmt.Errorf("%w: path %q", ErrTestFailed, *op.Path)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+func (op operation) value() (any, error) {
+	if op.Value == nil {
+		return nil, errors.New("missing value")
+	}
+
+	var value any
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	return value, nil
+}
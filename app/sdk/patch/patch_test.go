@@ -0,0 +1,194 @@
+package patch_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/patch"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Merge(t *testing.T) {
+	// The examples come from appendix A of RFC 7396.
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		exp   string
+	}{
+		{name: "replace", doc: `{"a":"b"}`, patch: `{"a":"c"}`, exp: `{"a":"c"}`},
+		{name: "add", doc: `{"a":"b"}`, patch: `{"b":"c"}`, exp: `{"a":"b","b":"c"}`},
+		{name: "remove", doc: `{"a":"b"}`, patch: `{"a":null}`, exp: `{}`},
+		{name: "remove-one", doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, exp: `{"b":"c"}`},
+		{name: "array", doc: `{"a":["b"]}`, patch: `{"a":"c"}`, exp: `{"a":"c"}`},
+		{name: "nested", doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, exp: `{"a":{"b":"d"}}`},
+		{name: "nested-add", doc: `{"e":null}`, patch: `{"a":1}`, exp: `{"a":1,"e":null}`},
+		{name: "scalar", doc: `{"a":"foo"}`, patch: `"bar"`, exp: `"bar"`},
+		{name: "deep-null", doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, exp: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.Merge([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Should be able to apply the merge patch : %s", err)
+			}
+
+			if diff := cmp.Diff(string(got), tt.exp); diff != "" {
+				t.Fatalf("Should get the expected document : %s", diff)
+			}
+		})
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	// Most of the examples come from appendix A of RFC 6902.
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		exp   string
+	}{
+		{name: "add-member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, exp: `{"baz":"qux","foo":"bar"}`},
+		{name: "add-element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, exp: `{"foo":["bar","qux","baz"]}`},
+		{name: "add-end", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":"qux"}]`, exp: `{"foo":["bar","qux"]}`},
+		{name: "add-null", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":null}]`, exp: `{"baz":null,"foo":"bar"}`},
+		{name: "remove-member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, exp: `{"foo":"bar"}`},
+		{name: "remove-element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, exp: `{"foo":["bar","baz"]}`},
+		{name: "replace", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, exp: `{"baz":"boo","foo":"bar"}`},
+		{name: "move", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, exp: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move-element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, exp: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "copy", doc: `{"foo":{"bar":1}}`, patch: `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`, exp: `{"baz":{"bar":2},"foo":{"bar":1}}`},
+		{name: "test", doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, exp: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "escape", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, exp: `{"~1":10}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.JSON([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Should be able to apply the json patch : %s", err)
+			}
+
+			if diff := cmp.Diff(string(got), tt.exp); diff != "" {
+				t.Fatalf("Should get the expected document : %s", diff)
+			}
+		})
+	}
+}
+
+func Test_JSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+	}{
+		{name: "missing-member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{name: "remove-missing", doc: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`},
+		{name: "replace-missing", doc: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":1}]`},
+		{name: "index-range", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/2","value":"qux"}]`},
+		{name: "index-leading-zero", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/01"}]`},
+		{name: "missing-value", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz"}]`},
+		{name: "unknown-op", doc: `{"foo":"bar"}`, patch: `[{"op":"append","path":"/baz","value":1}]`},
+		{name: "move-child", doc: `{"foo":{"bar":1}}`, patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`},
+		{name: "bad-pointer", doc: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"foo"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := patch.JSON([]byte(tt.doc), []byte(tt.patch)); err == nil {
+				t.Fatalf("Should not be able to apply the json patch")
+			}
+		})
+	}
+
+	t.Run("test-failed", func(t *testing.T) {
+		_, err := patch.JSON([]byte(`{"baz":"qux"}`), []byte(`[{"op":"test","path":"/baz","value":"bar"}]`))
+		if !errors.Is(err, patch.ErrTestFailed) {
+			t.Fatalf("Should get a failed test error : %v", err)
+		}
+	})
+}
+
+func Test_Apply(t *testing.T) {
+	doc := []byte(`{"name":"Guitar","cost":10}`)
+
+	tests := []struct {
+		contentType string
+		body        string
+		exp         string
+	}{
+		{contentType: "application/merge-patch+json", body: `{"cost":20}`, exp: `{"cost":20,"name":"Guitar"}`},
+		{contentType: "application/json-patch+json; charset=utf-8", body: `[{"op":"replace","path":"/cost","value":20}]`, exp: `{"cost":20,"name":"Guitar"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			got, err := patch.Apply(r, doc)
+			if err != nil {
+				t.Fatalf("Should be able to apply the patch : %s", err)
+			}
+
+			if diff := cmp.Diff(string(got), tt.exp); diff != "" {
+				t.Fatalf("Should get the expected document : %s", diff)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		r := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"cost":20}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		if _, err := patch.Apply(r, doc); !errors.Is(err, patch.ErrUnsupportedType) {
+			t.Fatalf("Should get an unsupported type error : %v", err)
+		}
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		body := `{"name":"` + strings.Repeat("a", patch.MaxSize) + `"}`
+		r := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+
+		if _, err := patch.Apply(r, doc); !errors.Is(err, patch.ErrTooLarge) {
+			t.Fatalf("Should get a too large error : %v", err)
+		}
+	})
+}
+
+func Test_Decode(t *testing.T) {
+	type model struct {
+		Name *string  `json:"name"`
+		Cost *float64 `json:"cost"`
+	}
+
+	tests := []struct {
+		name string
+		doc  string
+		exp  errs.FieldErrors
+	}{
+		{name: "unknown", doc: `{"name":"Guitar","color":"red"}`, exp: errs.FieldErrors{{Field: "color", Err: "unknown field"}}},
+		{name: "type", doc: `{"name":"Guitar","cost":"cheap"}`, exp: errs.FieldErrors{{Field: "cost", Err: "must be of type float64"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m model
+			err := patch.Decode([]byte(tt.doc), &m)
+
+			var fieldErrors errs.FieldErrors
+			if !errors.As(err, &fieldErrors) {
+				t.Fatalf("Should get field errors : %v", err)
+			}
+
+			if diff := cmp.Diff(fieldErrors, tt.exp); diff != "" {
+				t.Fatalf("Should get the expected field errors : %s", diff)
+			}
+		})
+	}
+}
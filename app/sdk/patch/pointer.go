@@ -0,0 +1,229 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parsePointer splits an RFC 6901 JSON pointer into its reference tokens.
+// The empty pointer references the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// index parses an array index that must be less than max.
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if idx >= max {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+
+	return idx, nil
+}
+
+// =============================================================================
+
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			child, exists := n[token]
+			if !exists {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			node = child
+
+		case []any:
+			idx, err := index(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[idx]
+
+		default:
+			return nil, fmt.Errorf("can't reference %q in a scalar value", token)
+		}
+	}
+
+	return node, nil
+}
+
+// add returns the node with the value added at the specified path. Arrays
+// can grow, so the result has to replace the node held by the caller.
+func add(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+
+	switch n := node.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			n[token] = value
+			return n, nil
+		}
+
+		child, exists := n[token]
+		if !exists {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = child
+
+		return n, nil
+
+	case []any:
+		if len(path) == 1 {
+			if token == "-" {
+				return append(n, value), nil
+			}
+
+			idx, err := index(token, len(n)+1)
+			if err != nil {
+				return nil, err
+			}
+
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = value
+
+			return n, nil
+		}
+
+		idx, err := index(token, len(n))
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := add(n[idx], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = child
+
+		return n, nil
+	}
+
+	return nil, fmt.Errorf("can't reference %q in a scalar value", token)
+}
+
+// remove returns the node without the value at the specified path, along
+// with the value that was removed.
+func remove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("can't remove the whole document")
+	}
+
+	token := path[0]
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[token]
+		if !exists {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+
+		if len(path) == 1 {
+			delete(n, token)
+			return n, child, nil
+		}
+
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = child
+
+		return n, removed, nil
+
+	case []any:
+		idx, err := index(token, len(n))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(path) == 1 {
+			removed := n[idx]
+			return append(n[:idx], n[idx+1:]...), removed, nil
+		}
+
+		child, removed, err := remove(n[idx], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[idx] = child
+
+		return n, removed, nil
+	}
+
+	return nil, nil, fmt.Errorf("can't reference %q in a scalar value", token)
+}
+
+// =============================================================================
+
+// clone returns a deep copy of a decoded JSON value so a copied value doesn't
+// share maps or arrays with its source.
+func clone(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = clone(e)
+		}
+		return m
+
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = clone(e)
+		}
+		return s
+	}
+
+	return value
+}
+
+// equal reports whether two decoded JSON values are the same.
+func equal(a any, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
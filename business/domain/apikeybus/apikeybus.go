@@ -0,0 +1,295 @@
+// Package apikeybus provides business access to the api key domain. API keys
+// are long lived, scoped credentials used for service to service calls.
+package apikeybus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for api key operations.
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrInvalidKey   = errors.New("api key invalid")
+	ErrExpired      = errors.New("api key expired")
+	ErrRevoked      = errors.New("api key revoked")
+	ErrUserDisabled = errors.New("user disabled")
+	ErrInvalidRoles = errors.New("api key roles must be a subset of the user's roles")
+)
+
+// keyScheme is the leading marker of every key so they are easy to spot
+// in logs and secret scanners.
+const keyScheme = "sk"
+
+// lastUsedInterval limits how often the last used date is written so an
+// active key doesn't cause a write on every request.
+const lastUsedInterval = time.Minute
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, key APIKey) error
+	Revoke(ctx context.Context, key APIKey) error
+	UpdateLastUsed(ctx context.Context, key APIKey) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]APIKey, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error)
+	QueryByPrefix(ctx context.Context, prefix string) (APIKey, error)
+}
+
+// Business manages the set of APIs for api key access.
+type Business struct {
+	log     *logger.Logger
+	userBus *userbus.Business
+	storer  Storer
+}
+
+// NewBusiness constructs an api key business API for use.
+func NewBusiness(log *logger.Logger, userBus *userbus.Business, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		userBus: userBus,
+		storer:  storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := b.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:     b.log,
+		userBus: userBus,
+		storer:  storer,
+	}
+
+	return &bus, nil
+}
+
+// Create issues a new api key for the specified user. The raw key is returned
+// and is never stored, so it can't be recovered later.
+func (b *Business) Create(ctx context.Context, nak NewAPIKey) (string, APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.create")
+	defer span.End()
+
+	usr, err := b.userBus.QueryByID(ctx, nak.UserID)
+	if err != nil {
+		return "", APIKey{}, fmt.Errorf("user.querybyid: %s: %w", nak.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return "", APIKey{}, ErrUserDisabled
+	}
+
+	for _, r := range nak.Roles {
+		if !slices.Contains(usr.Roles, r) {
+			return "", APIKey{}, ErrInvalidRoles
+		}
+	}
+
+	prefix, secret, err := generateKey()
+	if err != nil {
+		return "", APIKey{}, fmt.Errorf("generatekey: %w", err)
+	}
+
+	raw := fmt.Sprintf("%s_%s_%s", keyScheme, prefix, secret)
+	now := time.Now()
+
+	key := APIKey{
+		ID:          uuid.New(),
+		UserID:      nak.UserID,
+		OrgID:       usr.OrgID,
+		Name:        nak.Name,
+		Prefix:      prefix,
+		Hash:        hashKey(raw),
+		Roles:       nak.Roles,
+		DateExpires: nak.DateExpires,
+		DateCreated: now,
+	}
+
+	if err := b.storer.Create(ctx, key); err != nil {
+		return "", APIKey{}, fmt.Errorf("create: %w", err)
+	}
+
+	return raw, key, nil
+}
+
+// Authenticate validates the raw api key and returns the key it represents.
+// The key must not be expired or revoked and the user must be enabled. The
+// roles of the key are narrowed to the ones the user still holds so a key
+// can't keep a role the user has since lost.
+func (b *Business) Authenticate(ctx context.Context, raw string) (APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.authenticate")
+	defer span.End()
+
+	prefix, err := parsePrefix(raw)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	key, err := b.storer.QueryByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return APIKey{}, ErrInvalidKey
+		}
+		return APIKey{}, fmt.Errorf("querybyprefix: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashKey(raw))) != 1 {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	now := time.Now()
+
+	if key.Revoked() {
+		return APIKey{}, ErrRevoked
+	}
+
+	if key.Expired(now) {
+		return APIKey{}, ErrExpired
+	}
+
+	usr, err := b.userBus.QueryByID(ctx, key.UserID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("user.querybyid: %s: %w", key.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return APIKey{}, ErrUserDisabled
+	}
+
+	key.Roles = slices.DeleteFunc(key.Roles, func(r role.Role) bool {
+		return !slices.Contains(usr.Roles, r)
+	})
+
+	if len(key.Roles) == 0 {
+		return APIKey{}, ErrInvalidRoles
+	}
+
+	if now.Sub(key.DateLastUsed) >= lastUsedInterval {
+		key.DateLastUsed = now
+		if err := b.storer.UpdateLastUsed(ctx, key); err != nil {
+			b.log.Error(ctx, "apikeybus: update last used", "apikey_id", key.ID, "err", err)
+		}
+	}
+
+	return key, nil
+}
+
+// Revoke revokes the specified api key so it can no longer be used.
+func (b *Business) Revoke(ctx context.Context, key APIKey) (APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.revoke")
+	defer span.End()
+
+	if key.Revoked() {
+		return key, nil
+	}
+
+	key.DateRevoked = time.Now()
+
+	if err := b.storer.Revoke(ctx, key); err != nil {
+		return APIKey{}, fmt.Errorf("revoke: %w", err)
+	}
+
+	return key, nil
+}
+
+// Query retrieves a list of existing api keys.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.query")
+	defer span.End()
+
+	keys, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Count returns the total number of api keys.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the api key by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.apikeybus.querybyid")
+	defer span.End()
+
+	key, err := b.storer.QueryByID(ctx, keyID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: keyID[%s]: %w", keyID, err)
+	}
+
+	return key, nil
+}
+
+// =============================================================================
+
+// generateKey produces the public prefix and the secret part of a new key.
+func generateKey() (string, string, error) {
+	p := make([]byte, 6)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", err
+	}
+
+	s := make([]byte, 32)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(p), base64.RawURLEncoding.EncodeToString(s), nil
+}
+
+// parsePrefix extracts the prefix from a raw key of the form
+// sk_<prefix>_<secret>.
+func parsePrefix(raw string) (string, error) {
+	scheme, rest, ok := strings.Cut(raw, "_")
+	if !ok || scheme != keyScheme {
+		return "", ErrInvalidKey
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", ErrInvalidKey
+	}
+
+	return prefix, nil
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
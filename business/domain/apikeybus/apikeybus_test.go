@@ -0,0 +1,279 @@
+package apikeybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_APIKey(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_APIKey")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, authenticate(db.BusDomain, sd), "authenticate")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	admins, err := userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users:  []unitest.User{{User: usrs[0]}},
+		Admins: []unitest.User{{User: admins[0]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				raw, key, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Users[0].ID,
+					Name:   "billing",
+					Roles:  []role.Role{role.User},
+				})
+				if err != nil {
+					return err
+				}
+
+				got, err := busDomain.APIKey.QueryByID(ctx, key.ID)
+				if err != nil {
+					return err
+				}
+
+				return raw != "" && got.Prefix == key.Prefix && got.Hash != raw
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "roles",
+			ExpResp: apikeybus.ErrInvalidRoles,
+			ExcFunc: func(ctx context.Context) any {
+				_, _, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Users[0].ID,
+					Name:   "escalate",
+					Roles:  []role.Role{role.Admin},
+				})
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if !errors.Is(got.(error), exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func authenticate(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	cmpErr := func(got any, exp any) string {
+		err, ok := got.(error)
+		if !ok || !errors.Is(err, exp.(error)) {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				raw, key, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID:      sd.Users[0].ID,
+					Name:        "basic",
+					Roles:       []role.Role{role.User},
+					DateExpires: time.Now().Add(time.Hour),
+				})
+				if err != nil {
+					return err
+				}
+
+				got, err := busDomain.APIKey.Authenticate(ctx, raw)
+				if err != nil {
+					return err
+				}
+
+				stored, err := busDomain.APIKey.QueryByID(ctx, key.ID)
+				if err != nil {
+					return err
+				}
+
+				return got.ID == key.ID && !stored.DateLastUsed.IsZero()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "invalid",
+			ExpResp: apikeybus.ErrInvalidKey,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Users[0].ID,
+					Name:   "invalid",
+					Roles:  []role.Role{role.User},
+				})
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.APIKey.Authenticate(ctx, raw+"x")
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "expired",
+			ExpResp: apikeybus.ErrExpired,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID:      sd.Users[0].ID,
+					Name:        "expired",
+					Roles:       []role.Role{role.User},
+					DateExpires: time.Now().Add(-time.Minute),
+				})
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.APIKey.Authenticate(ctx, raw)
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "revoked",
+			ExpResp: apikeybus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				raw, key, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Users[0].ID,
+					Name:   "revoked",
+					Roles:  []role.Role{role.User},
+				})
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.APIKey.Revoke(ctx, key); err != nil {
+					return err
+				}
+
+				_, err = busDomain.APIKey.Authenticate(ctx, raw)
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "demoted",
+			ExpResp: []role.Role{role.User},
+			ExcFunc: func(ctx context.Context) any {
+				if err := setRoles(ctx, busDomain, sd.Admins[0].User, role.Admin, role.User); err != nil {
+					return err
+				}
+
+				raw, _, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Admins[0].ID,
+					Name:   "demoted",
+					Roles:  []role.Role{role.Admin, role.User},
+				})
+				if err != nil {
+					return err
+				}
+
+				if err := setRoles(ctx, busDomain, sd.Admins[0].User, role.User); err != nil {
+					return err
+				}
+
+				got, err := busDomain.APIKey.Authenticate(ctx, raw)
+				if err != nil {
+					return err
+				}
+
+				return got.Roles
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "no-roles-left",
+			ExpResp: apikeybus.ErrInvalidRoles,
+			ExcFunc: func(ctx context.Context) any {
+				if err := setRoles(ctx, busDomain, sd.Admins[0].User, role.Admin); err != nil {
+					return err
+				}
+
+				raw, _, err := busDomain.APIKey.Create(ctx, apikeybus.NewAPIKey{
+					UserID: sd.Admins[0].ID,
+					Name:   "no-roles-left",
+					Roles:  []role.Role{role.Admin},
+				})
+				if err != nil {
+					return err
+				}
+
+				if err := setRoles(ctx, busDomain, sd.Admins[0].User, role.User); err != nil {
+					return err
+				}
+
+				_, err = busDomain.APIKey.Authenticate(ctx, raw)
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
+
+func setRoles(ctx context.Context, busDomain dbtest.BusDomain, usr userbus.User, roles ...role.Role) error {
+	usr, err := busDomain.User.QueryByID(ctx, usr.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = busDomain.User.Update(ctx, usr, userbus.UpdateUser{Roles: roles})
+	return err
+}
@@ -0,0 +1,47 @@
+package apikeybus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+// APIKey represents a long lived credential issued to a user for service to
+// service calls. Only the hash of the key is stored, the prefix is kept in
+// the clear so a key can be identified. A key belongs to the organisation
+// of its user.
+type APIKey struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	OrgID        uuid.UUID
+	Name         string
+	Prefix       string
+	Hash         string
+	Roles        []role.Role
+	DateExpires  time.Time
+	DateLastUsed time.Time
+	DateCreated  time.Time
+	DateRevoked  time.Time
+}
+
+// Revoked reports if the key has been revoked.
+func (k APIKey) Revoked() bool {
+	return !k.DateRevoked.IsZero()
+}
+
+// Expired reports if the key has expired. A key with no expiration date
+// never expires.
+func (k APIKey) Expired(now time.Time) bool {
+	return !k.DateExpires.IsZero() && !now.Before(k.DateExpires)
+}
+
+// NewAPIKey contains information needed to issue a new API key. The roles
+// must be a subset of the user's roles. A zero DateExpires means the key
+// doesn't expire.
+type NewAPIKey struct {
+	UserID      uuid.UUID
+	Name        string
+	Roles       []role.Role
+	DateExpires time.Time
+}
@@ -0,0 +1,14 @@
+package apikeybus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "apikey_id"
+	OrderByUserID      = "user_id"
+	OrderByName        = "name"
+	OrderByDateCreated = "date_created"
+)
@@ -0,0 +1,205 @@
+// Package apikeydb contains api key related CRUD functionality.
+package apikeydb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for api key database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (apikeybus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new api key into the database.
+func (s *Store) Create(ctx context.Context, key apikeybus.APIKey) error {
+	const q = `
+	INSERT INTO api_keys
+		(apikey_id, user_id, org_id, name, key_prefix, key_hash, roles, date_expires, date_last_used, date_created, date_revoked)
+	VALUES
+		(:apikey_id, :user_id, :org_id, :name, :key_prefix, :key_hash, :roles, :date_expires, :date_last_used, :date_created, :date_revoked)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke marks the api key as revoked.
+func (s *Store) Revoke(ctx context.Context, key apikeybus.APIKey) error {
+	const q = `
+	UPDATE
+		api_keys
+	SET
+		date_revoked = :date_revoked
+	WHERE
+		apikey_id = :apikey_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLastUsed records when the api key was last used.
+func (s *Store) UpdateLastUsed(ctx context.Context, key apikeybus.APIKey) error {
+	const q = `
+	UPDATE
+		api_keys
+	SET
+		date_last_used = :date_last_used
+	WHERE
+		apikey_id = :apikey_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing api keys from the database.
+func (s *Store) Query(ctx context.Context, filter apikeybus.QueryFilter, orderBy order.By, page page.Page) ([]apikeybus.APIKey, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		apikey_id, user_id, org_id, name, key_prefix, key_hash, roles, date_expires, date_last_used, date_created, date_revoked
+	FROM
+		api_keys`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbKeys []apiKey
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbKeys); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusAPIKeys(dbKeys)
+}
+
+// Count returns the total number of api keys in the DB.
+func (s *Store) Count(ctx context.Context, filter apikeybus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		api_keys`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified api key from the database.
+func (s *Store) QueryByID(ctx context.Context, keyID uuid.UUID) (apikeybus.APIKey, error) {
+	data := struct {
+		ID string `db:"apikey_id"`
+	}{
+		ID: keyID.String(),
+	}
+
+	const q = `
+	SELECT
+		apikey_id, user_id, org_id, name, key_prefix, key_hash, roles, date_expires, date_last_used, date_created, date_revoked
+	FROM
+		api_keys
+	WHERE
+		apikey_id = :apikey_id`
+
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikeybus.APIKey{}, fmt.Errorf("db: %w", apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
+
+// QueryByPrefix gets the api key with the specified prefix from the database.
+func (s *Store) QueryByPrefix(ctx context.Context, prefix string) (apikeybus.APIKey, error) {
+	data := struct {
+		Prefix string `db:"key_prefix"`
+	}{
+		Prefix: prefix,
+	}
+
+	const q = `
+	SELECT
+		apikey_id, user_id, org_id, name, key_prefix, key_hash, roles, date_expires, date_last_used, date_created, date_revoked
+	FROM
+		api_keys
+	WHERE
+		key_prefix = :key_prefix`
+
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikeybus.APIKey{}, fmt.Errorf("db: %w", apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
@@ -0,0 +1,41 @@
+package apikeydb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+)
+
+func (s *Store) applyFilter(filter apikeybus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["apikey_id"] = *filter.ID
+		wc = append(wc, "apikey_id = :apikey_id")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
+	if filter.Revoked != nil {
+		switch *filter.Revoked {
+		case true:
+			wc = append(wc, "date_revoked IS NOT NULL")
+		default:
+			wc = append(wc, "date_revoked IS NULL")
+		}
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
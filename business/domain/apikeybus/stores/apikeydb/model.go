@@ -0,0 +1,94 @@
+package apikeydb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+type apiKey struct {
+	ID           uuid.UUID      `db:"apikey_id"`
+	UserID       uuid.UUID      `db:"user_id"`
+	OrgID        uuid.UUID      `db:"org_id"`
+	Name         string         `db:"name"`
+	Prefix       string         `db:"key_prefix"`
+	Hash         string         `db:"key_hash"`
+	Roles        dbarray.String `db:"roles"`
+	DateExpires  sql.NullTime   `db:"date_expires"`
+	DateLastUsed sql.NullTime   `db:"date_last_used"`
+	DateCreated  time.Time      `db:"date_created"`
+	DateRevoked  sql.NullTime   `db:"date_revoked"`
+}
+
+func toDBAPIKey(bus apikeybus.APIKey) apiKey {
+	return apiKey{
+		ID:           bus.ID,
+		UserID:       bus.UserID,
+		OrgID:        bus.OrgID,
+		Name:         bus.Name,
+		Prefix:       bus.Prefix,
+		Hash:         bus.Hash,
+		Roles:        role.ParseToString(bus.Roles),
+		DateExpires:  toNullTime(bus.DateExpires),
+		DateLastUsed: toNullTime(bus.DateLastUsed),
+		DateCreated:  bus.DateCreated.UTC(),
+		DateRevoked:  toNullTime(bus.DateRevoked),
+	}
+}
+
+func toBusAPIKey(db apiKey) (apikeybus.APIKey, error) {
+	roles, err := role.ParseMany(db.Roles)
+	if err != nil {
+		return apikeybus.APIKey{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := apikeybus.APIKey{
+		ID:           db.ID,
+		UserID:       db.UserID,
+		OrgID:        db.OrgID,
+		Name:         db.Name,
+		Prefix:       db.Prefix,
+		Hash:         db.Hash,
+		Roles:        roles,
+		DateExpires:  toTime(db.DateExpires),
+		DateLastUsed: toTime(db.DateLastUsed),
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateRevoked:  toTime(db.DateRevoked),
+	}
+
+	return bus, nil
+}
+
+func toBusAPIKeys(dbs []apiKey) ([]apikeybus.APIKey, error) {
+	bus := make([]apikeybus.APIKey, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusAPIKey(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
+
+func toTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
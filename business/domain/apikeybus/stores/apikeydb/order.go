@@ -0,0 +1,24 @@
+package apikeydb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	apikeybus.OrderByID:          "apikey_id",
+	apikeybus.OrderByUserID:      "user_id",
+	apikeybus.OrderByName:        "name",
+	apikeybus.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
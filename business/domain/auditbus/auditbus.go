@@ -0,0 +1,123 @@
+// Package auditbus provides business access to the history of changes made
+// to the entities of the other domains.
+package auditbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, adt Audit) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Business manages the set of APIs for audit access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an audit business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Record adds a change to the history. The actor and trace id are taken from
+// the context. Callers that want the change and its history to succeed or
+// fail together must use a business value constructed with NewWithTx.
+func (b *Business) Record(ctx context.Context, na NewAudit) (Audit, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.record")
+	defer span.End()
+
+	before, err := marshal(na.Before)
+	if err != nil {
+		return Audit{}, fmt.Errorf("marshal before: %w", err)
+	}
+
+	after, err := marshal(na.After)
+	if err != nil {
+		return Audit{}, fmt.Errorf("marshal after: %w", err)
+	}
+
+	adt := Audit{
+		ID:          uuid.New(),
+		Entity:      na.Entity,
+		EntityID:    na.EntityID,
+		OrgID:       na.OrgID,
+		Action:      na.Action,
+		ActorID:     GetActor(ctx),
+		TraceID:     otel.GetTraceID(ctx),
+		Before:      before,
+		After:       after,
+		DateCreated: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, adt); err != nil {
+		return Audit{}, fmt.Errorf("create: %w", err)
+	}
+
+	return adt, nil
+}
+
+// Query retrieves a list of recorded changes.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.query")
+	defer span.End()
+
+	adts, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return adts, nil
+}
+
+// Count returns the total number of recorded changes.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// marshal converts a snapshot to JSON. A missing snapshot stays empty.
+func marshal(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,183 @@
+package auditbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Audit(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Audit")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, trail(db, sd), "trail")
+	unitest.Run(t, rollback(db, sd), "rollback")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := unitest.User{
+		User: usrs[0],
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := unitest.SeedData{
+		Admins: []unitest.User{tu1},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func trail(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	type result struct {
+		Actions []string
+		Actor   string
+		Cost    float64
+	}
+
+	table := []unitest.Table{
+		{
+			Name: "product",
+			ExpResp: result{
+				Actions: []string{auditbus.ActionCreated, auditbus.ActionDeleted, auditbus.ActionUpdated},
+				Actor:   sd.Admins[0].ID.String(),
+				Cost:    50,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				ctx = auditbus.SetActor(ctx, sd.Admins[0].ID)
+
+				prds, err := productbus.TestGenerateSeedProducts(ctx, 1, db.BusDomain.Product, sd.Admins[0].ID)
+				if err != nil {
+					return err
+				}
+
+				cost := money.MustParse(50)
+				prd, err := db.BusDomain.Product.Update(ctx, prds[0], productbus.UpdateProduct{Cost: &cost})
+				if err != nil {
+					return err
+				}
+
+				if err := db.BusDomain.Product.Delete(ctx, prd); err != nil {
+					return err
+				}
+
+				entity := productbus.DomainName
+				filter := auditbus.QueryFilter{
+					Entity:   &entity,
+					EntityID: &prd.ID,
+				}
+
+				auds, err := db.BusDomain.Audit.Query(ctx, filter, auditbus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				var resp result
+				for _, aud := range auds {
+					resp.Actions = append(resp.Actions, aud.Action)
+					resp.Actor = aud.ActorID.String()
+
+					if aud.Action != auditbus.ActionUpdated {
+						continue
+					}
+
+					var after struct {
+						Cost float64 `json:"cost"`
+					}
+					if err := json.Unmarshal(aud.After, &after); err != nil {
+						return err
+					}
+					resp.Cost = after.Cost
+				}
+
+				slices.Sort(resp.Actions)
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func rollback(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "product",
+			ExpResp: 0,
+			ExcFunc: func(ctx context.Context) any {
+				tx, err := sqldb.NewBeginner(db.DB).Begin()
+				if err != nil {
+					return err
+				}
+
+				productBus, err := db.BusDomain.Product.NewWithTx(tx)
+				if err != nil {
+					return err
+				}
+
+				prds, err := productbus.TestGenerateSeedProducts(ctx, 1, productBus, sd.Admins[0].ID)
+				if err != nil {
+					return err
+				}
+
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+
+				entity := productbus.DomainName
+				filter := auditbus.QueryFilter{
+					Entity:   &entity,
+					EntityID: &prds[0].ID,
+				}
+
+				n, err := db.BusDomain.Audit.Count(ctx, filter)
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,27 @@
+package auditbus
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ctxKey int
+
+const actorKey ctxKey = 1
+
+// SetActor stores the user performing the changes in the context so they
+// can be recorded against every change.
+func SetActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey, actorID)
+}
+
+// GetActor returns the user performing the changes from the context.
+func GetActor(ctx context.Context) uuid.UUID {
+	v, ok := ctx.Value(actorKey).(uuid.UUID)
+	if !ok {
+		return uuid.UUID{}
+	}
+
+	return v
+}
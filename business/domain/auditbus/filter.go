@@ -0,0 +1,12 @@
+package auditbus
+
+import "github.com/google/uuid"
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	Entity   *string
+	EntityID *uuid.UUID
+	OrgID    *uuid.UUID
+	ActorID  *uuid.UUID
+}
@@ -0,0 +1,47 @@
+package auditbus
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of actions that are recorded against an entity.
+const (
+	ActionCreated    = "created"
+	ActionUpdated    = "updated"
+	ActionDeleted    = "deleted"
+	ActionRestored   = "restored"
+	ActionArchived   = "archived"
+	ActionUnarchived = "unarchived"
+)
+
+// Audit represents a single change made to an entity. Before and After hold
+// snapshots of the entity around the change, one of them is empty when the
+// entity was created or deleted. ActorID is empty when the change was not
+// made on behalf of a user, like work driven by events.
+type Audit struct {
+	ID          uuid.UUID
+	Entity      string
+	EntityID    uuid.UUID
+	OrgID       uuid.UUID
+	Action      string
+	ActorID     uuid.UUID
+	TraceID     string
+	Before      json.RawMessage
+	After       json.RawMessage
+	DateCreated time.Time
+}
+
+// NewAudit is what we require to record a change. Before and After are
+// marshaled to JSON, so they should only carry the fields that are safe to
+// keep in the history.
+type NewAudit struct {
+	Entity   string
+	EntityID uuid.UUID
+	OrgID    uuid.UUID
+	Action   string
+	Before   any
+	After    any
+}
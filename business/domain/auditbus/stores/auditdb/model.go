@@ -0,0 +1,64 @@
+package auditdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/google/uuid"
+)
+
+type audit struct {
+	ID          uuid.UUID     `db:"audit_id"`
+	Entity      string        `db:"entity"`
+	EntityID    uuid.UUID     `db:"entity_id"`
+	OrgID       uuid.UUID     `db:"org_id"`
+	Action      string        `db:"action"`
+	ActorID     uuid.NullUUID `db:"actor_id"`
+	TraceID     string        `db:"trace_id"`
+	Before      []byte        `db:"before"`
+	After       []byte        `db:"after"`
+	DateCreated time.Time     `db:"date_created"`
+}
+
+func toDBAudit(bus auditbus.Audit) audit {
+	return audit{
+		ID:       bus.ID,
+		Entity:   bus.Entity,
+		EntityID: bus.EntityID,
+		OrgID:    bus.OrgID,
+		Action:   bus.Action,
+		ActorID: uuid.NullUUID{
+			UUID:  bus.ActorID,
+			Valid: bus.ActorID != uuid.Nil,
+		},
+		TraceID:     bus.TraceID,
+		Before:      bus.Before,
+		After:       bus.After,
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusAudit(db audit) auditbus.Audit {
+	return auditbus.Audit{
+		ID:          db.ID,
+		Entity:      db.Entity,
+		EntityID:    db.EntityID,
+		OrgID:       db.OrgID,
+		Action:      db.Action,
+		ActorID:     db.ActorID.UUID,
+		TraceID:     db.TraceID,
+		Before:      json.RawMessage(db.Before),
+		After:       json.RawMessage(db.After),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
+
+func toBusAudits(dbs []audit) []auditbus.Audit {
+	bus := make([]auditbus.Audit, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusAudit(db)
+	}
+
+	return bus
+}
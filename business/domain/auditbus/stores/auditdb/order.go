@@ -0,0 +1,23 @@
+package auditdb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	auditbus.OrderByID:          "audit_id",
+	auditbus.OrderByAction:      "action",
+	auditbus.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
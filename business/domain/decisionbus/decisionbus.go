@@ -0,0 +1,100 @@
+// Package decisionbus provides business access to the log of decisions made
+// by the auth rules, so it's possible to find out why a request was denied.
+package decisionbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, dec Decision) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Decision, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Business manages the set of APIs for decision access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a decision business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create records a new decision.
+func (b *Business) Create(ctx context.Context, nd NewDecision) (Decision, error) {
+	ctx, span := otel.AddSpan(ctx, "business.decisionbus.create")
+	defer span.End()
+
+	dec := Decision{
+		ID:          uuid.New(),
+		Subject:     nd.Subject,
+		OrgID:       nd.OrgID,
+		Rule:        nd.Rule,
+		Input:       nd.Input,
+		Allowed:     nd.Allowed,
+		Reason:      nd.Reason,
+		Latency:     nd.Latency,
+		DateCreated: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, dec); err != nil {
+		return Decision{}, fmt.Errorf("create: %w", err)
+	}
+
+	return dec, nil
+}
+
+// Query retrieves a list of existing decisions.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Decision, error) {
+	ctx, span := otel.AddSpan(ctx, "business.decisionbus.query")
+	defer span.End()
+
+	decs, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return decs, nil
+}
+
+// Count returns the total number of decisions.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.decisionbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
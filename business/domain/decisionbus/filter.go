@@ -0,0 +1,18 @@
+package decisionbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	Subject          *string
+	OrgID            *uuid.UUID
+	Rule             *string
+	Allowed          *bool
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+}
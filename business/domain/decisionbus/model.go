@@ -0,0 +1,36 @@
+package decisionbus
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision represents the outcome of evaluating an auth rule. The Subject is
+// the subject of the claims that were evaluated, which is a user id for user
+// tokens. The OrgID is the tenant of the subject and is the zero value when
+// the claims didn't carry one. The Input is what was provided to the rule,
+// minus any secrets.
+type Decision struct {
+	ID          uuid.UUID
+	Subject     string
+	OrgID       uuid.UUID
+	Rule        string
+	Input       json.RawMessage
+	Allowed     bool
+	Reason      string
+	Latency     time.Duration
+	DateCreated time.Time
+}
+
+// NewDecision contains the information needed to record a decision.
+type NewDecision struct {
+	Subject string
+	OrgID   uuid.UUID
+	Rule    string
+	Input   json.RawMessage
+	Allowed bool
+	Reason  string
+	Latency time.Duration
+}
@@ -0,0 +1,14 @@
+package decisionbus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByDateCreated, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderBySubject     = "subject"
+	OrderByRule        = "rule"
+	OrderByLatency     = "latency"
+	OrderByDateCreated = "date_created"
+)
@@ -0,0 +1,47 @@
+package decisiondb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+)
+
+func (s *Store) applyFilter(filter decisionbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.Subject != nil {
+		data["subject"] = *filter.Subject
+		wc = append(wc, "subject = :subject")
+	}
+
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
+	if filter.Rule != nil {
+		data["rule"] = *filter.Rule
+		wc = append(wc, "rule = :rule")
+	}
+
+	if filter.Allowed != nil {
+		data["allowed"] = *filter.Allowed
+		wc = append(wc, "allowed = :allowed")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_date_created"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "date_created >= :start_date_created")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_date_created"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "date_created <= :end_date_created")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,67 @@
+package decisiondb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/google/uuid"
+)
+
+type decision struct {
+	ID          uuid.UUID     `db:"decision_id"`
+	Subject     string        `db:"subject"`
+	OrgID       uuid.NullUUID `db:"org_id"`
+	Rule        string        `db:"rule"`
+	Input       string        `db:"input"`
+	Allowed     bool          `db:"allowed"`
+	Reason      string        `db:"reason"`
+	LatencyUS   int64         `db:"latency_us"`
+	DateCreated time.Time     `db:"date_created"`
+}
+
+func toDBDecision(bus decisionbus.Decision) decision {
+	input := string(bus.Input)
+	if input == "" {
+		input = "{}"
+	}
+
+	return decision{
+		ID:      bus.ID,
+		Subject: bus.Subject,
+		OrgID: uuid.NullUUID{
+			UUID:  bus.OrgID,
+			Valid: bus.OrgID != uuid.Nil,
+		},
+		Rule:        bus.Rule,
+		Input:       input,
+		Allowed:     bus.Allowed,
+		Reason:      bus.Reason,
+		LatencyUS:   bus.Latency.Microseconds(),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusDecision(db decision) decisionbus.Decision {
+	return decisionbus.Decision{
+		ID:          db.ID,
+		Subject:     db.Subject,
+		OrgID:       db.OrgID.UUID,
+		Rule:        db.Rule,
+		Input:       json.RawMessage(db.Input),
+		Allowed:     db.Allowed,
+		Reason:      db.Reason,
+		Latency:     time.Duration(db.LatencyUS) * time.Microsecond,
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
+
+func toBusDecisions(dbs []decision) []decisionbus.Decision {
+	bus := make([]decisionbus.Decision, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusDecision(db)
+	}
+
+	return bus
+}
@@ -0,0 +1,24 @@
+package decisiondb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/decisionbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	decisionbus.OrderBySubject:     "subject",
+	decisionbus.OrderByRule:        "rule",
+	decisionbus.OrderByLatency:     "latency_us",
+	decisionbus.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -0,0 +1,66 @@
+package homebus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/google/uuid"
+)
+
+// auditSnapshot is the view of a home kept in the audit history.
+type auditSnapshot struct {
+	UserID      uuid.UUID `json:"userID"`
+	Type        string    `json:"type"`
+	Address1    string    `json:"address1"`
+	Address2    string    `json:"address2"`
+	ZipCode     string    `json:"zipCode"`
+	City        string    `json:"city"`
+	State       string    `json:"state"`
+	Country     string    `json:"country"`
+	Archived    bool      `json:"archived,omitempty"`
+	DateUpdated time.Time `json:"dateUpdated"`
+}
+
+func toAuditSnapshot(hme *Home) any {
+	if hme == nil {
+		return nil
+	}
+
+	return auditSnapshot{
+		UserID:      hme.UserID,
+		Type:        hme.Type.String(),
+		Address1:    hme.Address.Address1,
+		Address2:    hme.Address.Address2,
+		ZipCode:     hme.Address.ZipCode,
+		City:        hme.Address.City,
+		State:       hme.Address.State,
+		Country:     hme.Address.Country,
+		Archived:    hme.Archived(),
+		DateUpdated: hme.DateUpdated,
+	}
+}
+
+// audit records a change to the home in the audit history. Nothing is
+// recorded when the business was constructed without an audit business.
+func (b *Business) audit(ctx context.Context, action string, hme Home, before *Home, after *Home) error {
+	if b.auditBus == nil {
+		return nil
+	}
+
+	na := auditbus.NewAudit{
+		Entity:   DomainName,
+		EntityID: hme.ID,
+		OrgID:    hme.OrgID,
+		Action:   action,
+		Before:   toAuditSnapshot(before),
+		After:    toAuditSnapshot(after),
+	}
+
+	if _, err := b.auditBus.Record(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
@@ -1 +1,37 @@
 package homebus
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+)
+
+// DomainName represents the name of this domain.
+const DomainName = "home"
+
+// registerDelegateFunctions will register action functions with the delegate
+// system. If the business was constructed for query only, there won't be a
+// delegate provided.
+func (b *Business) registerDelegateFunctions() {
+	if b.delegate != nil {
+		delegate.Register(b.delegate, userbus.EventUpdated, b.actionUserUpdated)
+	}
+}
+
+// actionUserUpdated is executed by the user domain indirectly when a user is
+// updated. Disabling a user archives all their homes and enabling the user
+// again restores them. Both operations are safe to repeat.
+func (b *Business) actionUserUpdated(ctx context.Context, params userbus.ActionUpdatedParms) error {
+	b.log.Info(ctx, "action-userupdate", "user_id", params.UserID, "enabled", params.Enabled)
+
+	if params.Enabled == nil {
+		return nil
+	}
+
+	if !*params.Enabled {
+		return b.ArchiveByUserID(ctx, params.UserID)
+	}
+
+	return b.RestoreByUserID(ctx, params.UserID)
+}
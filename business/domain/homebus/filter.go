@@ -9,10 +9,14 @@ import (
 
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
+// Archived homes are left out unless Archived is set, in which case the
+// query returns only the archived or only the active homes.
 type QueryFilter struct {
 	ID               *uuid.UUID
 	UserID           *uuid.UUID
+	OrgID            *uuid.UUID
 	Type             *hometype.HomeType
 	StartCreatedDate *time.Time
 	EndCreatedDate   *time.Time
+	Archived         *bool
 }
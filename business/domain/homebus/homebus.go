@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -19,8 +20,9 @@ import (
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound     = errors.New("home not found")
-	ErrUserDisabled = errors.New("user disabled")
+	ErrNotFound        = errors.New("home not found")
+	ErrUserDisabled    = errors.New("user disabled")
+	ErrVersionConflict = errors.New("home changed by another request")
 )
 
 // Storer interface declares the behaviour this package needs to persist and
@@ -29,11 +31,15 @@ type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, hme Home) error
 	Update(ctx context.Context, hme Home) error
-	Delete(ctx context.Context, hme Home) error
+	Delete(ctx context.Context, hme Home, now time.Time) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Home, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, homeID uuid.UUID) (Home, error)
 	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Home, error)
+	ArchiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]Home, error)
+	RestoreByUserID(ctx context.Context, userID uuid.UUID) ([]Home, error)
+	Restore(ctx context.Context, homeID uuid.UUID) (Home, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 // Business manages the set of APIs for home api access.
@@ -41,17 +47,24 @@ type Business struct {
 	log      *logger.Logger
 	userBus  *userbus.Business
 	delegate *delegate.Delegate
+	auditBus *auditbus.Business
 	storer   Storer
 }
 
-// NewBusiness constructs a home business API for use.
-func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
-	return &Business{
+// NewBusiness constructs a home business API for use. When an audit business
+// is provided, every change to a home is recorded in the audit history.
+func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, auditBus *auditbus.Business, storer Storer) *Business {
+	b := Business{
 		log:      log,
 		userBus:  userBus,
 		delegate: delegate,
+		auditBus: auditBus,
 		storer:   storer,
 	}
+
+	b.registerDelegateFunctions()
+
+	return &b
 }
 
 // NewWithTx constructs a new domain value that will use the
@@ -67,10 +80,19 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		return nil, err
 	}
 
+	auditBus := b.auditBus
+	if auditBus != nil {
+		auditBus, err = auditBus.NewWithTx(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	bus := Business{
 		log:      b.log,
 		userBus:  userBus,
 		delegate: b.delegate,
+		auditBus: auditBus,
 		storer:   storer,
 	}
 
@@ -105,14 +127,20 @@ func (b *Business) Create(ctx context.Context, nh NewHome) (Home, error) {
 			Country:  nh.Address.Country,
 		},
 		UserID:      nh.UserID,
+		OrgID:       usr.OrgID,
 		DateCreated: now,
 		DateUpdated: now,
+		Version:     1,
 	}
 
 	if err := b.storer.Create(ctx, hme); err != nil {
 		return Home{}, fmt.Errorf("create: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionCreated, hme, nil, &hme); err != nil {
+		return Home{}, err
+	}
+
 	return hme, nil
 }
 
@@ -121,6 +149,8 @@ func (b *Business) Update(ctx context.Context, hme Home, uh UpdateHome) (Home, e
 	ctx, span := otel.AddSpan(ctx, "business.homebus.update")
 	defer span.End()
 
+	before := hme
+
 	if uh.Type != nil {
 		hme.Type = *uh.Type
 	}
@@ -152,23 +182,33 @@ func (b *Business) Update(ctx context.Context, hme Home, uh UpdateHome) (Home, e
 	}
 
 	hme.DateUpdated = time.Now()
+	hme.Version++
 
 	if err := b.storer.Update(ctx, hme); err != nil {
 		return Home{}, fmt.Errorf("update: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionUpdated, hme, &before, &hme); err != nil {
+		return Home{}, err
+	}
+
 	return hme, nil
 }
 
-// Delete removes the specified home.
+// Delete marks the specified home as deleted. The home can be restored until
+// it is purged.
 func (b *Business) Delete(ctx context.Context, hme Home) error {
 	ctx, span := otel.AddSpan(ctx, "business.homebus.delete")
 	defer span.End()
 
-	if err := b.storer.Delete(ctx, hme); err != nil {
+	if err := b.storer.Delete(ctx, hme, time.Now()); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionDeleted, hme, &hme, nil); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -218,3 +258,75 @@ func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Home,
 
 	return hmes, nil
 }
+
+// ArchiveByUserID archives all the active homes for the specified user.
+// Archived homes are left out of queries until they are restored. An
+// audit entry is recorded for each home that is archived.
+func (b *Business) ArchiveByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.archivebyuserid")
+	defer span.End()
+
+	hmes, err := b.storer.ArchiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("archive: userID[%s]: %w", userID, err)
+	}
+
+	for _, hme := range hmes {
+		if err := b.audit(ctx, auditbus.ActionArchived, hme, nil, &hme); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreByUserID makes all the archived homes for the specified user
+// active again. An audit entry is recorded for each home that is restored.
+func (b *Business) RestoreByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.restorebyuserid")
+	defer span.End()
+
+	hmes, err := b.storer.RestoreByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("restore: userID[%s]: %w", userID, err)
+	}
+
+	for _, hme := range hmes {
+		if err := b.audit(ctx, auditbus.ActionUnarchived, hme, nil, &hme); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back the specified deleted home.
+func (b *Business) Restore(ctx context.Context, homeID uuid.UUID) (Home, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.restore")
+	defer span.End()
+
+	hme, err := b.storer.Restore(ctx, homeID)
+	if err != nil {
+		return Home{}, fmt.Errorf("restore: homeID[%s]: %w", homeID, err)
+	}
+
+	if err := b.audit(ctx, auditbus.ActionRestored, hme, nil, &hme); err != nil {
+		return Home{}, err
+	}
+
+	return hme, nil
+}
+
+// Purge permanently removes the homes deleted before the specified
+// time. It returns how many homes were removed.
+func (b *Business) Purge(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.homebus.purge")
+	defer span.End()
+
+	n, err := b.storer.Purge(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+
+	return n, nil
+}
@@ -2,12 +2,15 @@ package homebus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/sdk/page"
@@ -33,6 +36,8 @@ func Test_Home(t *testing.T) {
 	unitest.Run(t, create(db.BusDomain, sd), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+	unitest.Run(t, restore(db.BusDomain, sd), "restore")
+	unitest.Run(t, archive(db, sd), "archive")
 }
 
 // =============================================================================
@@ -197,6 +202,8 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					State:    "AL",
 					Country:  "US",
 				},
+				OrgID:   sd.Users[0].OrgID,
+				Version: 1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				nh := homebus.NewHome{
@@ -254,8 +261,10 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					State:    "AL",
 					Country:  "US",
 				},
+				OrgID:       sd.Users[0].OrgID,
 				DateCreated: sd.Users[0].Homes[0].DateCreated,
 				DateUpdated: sd.Users[0].Homes[0].DateCreated,
+				Version:     sd.Users[0].Homes[0].Version + 1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				uh := homebus.UpdateHome{
@@ -292,6 +301,28 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "stale",
+			ExpResp: homebus.ErrVersionConflict,
+			ExcFunc: func(ctx context.Context) any {
+				uh := homebus.UpdateHome{
+					Type: &hometype.Condo,
+				}
+
+				// The basic update moved the home past this version.
+				_, err := busDomain.Home.Update(ctx, sd.Users[0].Homes[0], uh)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, ok := got.(error)
+				if !ok || !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, want %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -331,3 +362,182 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 	return table
 }
+
+// archiveCounts captures the number of active and archived homes and the
+// last audit action recorded for the first home.
+type archiveCounts struct {
+	Active   int
+	Archived int
+	Audit    string
+}
+
+func archive(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Users[0].User
+
+	// The user domain raises its events through the outbox, so the relay
+	// has to run before the homes see the change.
+	setEnabled := func(ctx context.Context, enabled bool) (archiveCounts, error) {
+		uu := userbus.UpdateUser{
+			Enabled: &enabled,
+		}
+
+		updUsr, err := db.BusDomain.User.Update(ctx, usr, uu)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+		usr = updUsr
+
+		rly := outboxbus.NewRelay(db.Log, outboxbus.DefaultRelayConfig, db.BusDomain.Outbox, db.BusDomain.Delegate)
+		if _, err := rly.Process(ctx); err != nil {
+			return archiveCounts{}, err
+		}
+
+		active, err := db.BusDomain.Home.QueryByUserID(ctx, usr.ID)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		archived := true
+		filter := homebus.QueryFilter{
+			Archived: &archived,
+		}
+
+		count, err := db.BusDomain.Home.Count(ctx, filter)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		entityID := sd.Users[0].Homes[0].ID
+		auditFilter := auditbus.QueryFilter{
+			EntityID: &entityID,
+		}
+
+		adts, err := db.BusDomain.Audit.Query(ctx, auditFilter, auditbus.DefaultOrderBy, page.MustParse("1", "1"))
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		if len(adts) == 0 {
+			return archiveCounts{}, errors.New("expected an audit entry")
+		}
+
+		return archiveCounts{Active: len(active), Archived: count, Audit: adts[0].Action}, nil
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "disable",
+			ExpResp: archiveCounts{Active: 0, Archived: 2, Audit: auditbus.ActionArchived},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := setEnabled(ctx, false)
+				if err != nil {
+					return err
+				}
+
+				hme, err := db.BusDomain.Home.QueryByID(ctx, sd.Users[0].Homes[0].ID)
+				if err != nil {
+					return err
+				}
+
+				if !hme.Archived() {
+					return fmt.Errorf("expected home to be archived")
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "enable",
+			ExpResp: archiveCounts{Active: 2, Archived: 0, Audit: auditbus.ActionUnarchived},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := setEnabled(ctx, true)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func restore(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "deleted",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Home.QueryByID(ctx, sd.Users[0].Homes[1].ID)
+				return errors.Is(err, homebus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore",
+			ExpResp: sd.Users[0].Homes[1].ID,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Home.Restore(ctx, sd.Users[0].Homes[1].ID); err != nil {
+					return err
+				}
+
+				hme, err := busDomain.Home.QueryByID(ctx, sd.Users[0].Homes[1].ID)
+				if err != nil {
+					return err
+				}
+
+				return hme.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore-active",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Home.Restore(ctx, sd.Users[0].Homes[1].ID)
+				return errors.Is(err, homebus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "purge",
+			ExpResp: 2,
+			ExcFunc: func(ctx context.Context) any {
+				hme, err := busDomain.Home.QueryByID(ctx, sd.Users[0].Homes[1].ID)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Home.Delete(ctx, hme); err != nil {
+					return err
+				}
+
+				// The admin's home was deleted by the delete tests as well.
+				n, err := busDomain.Home.Purge(ctx, time.Now())
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
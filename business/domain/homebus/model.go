@@ -17,14 +17,25 @@ type Address struct {
 	Country  string
 }
 
-// Home represents an individual home.
+// Home represents an individual home. A home is archived when its owner is
+// disabled and DateArchived holds when that happened. The zero value means
+// the home is active. Version is incremented on every change to the home and
+// is used to detect concurrent updates.
 type Home struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	Type        hometype.HomeType
-	Address     Address
-	DateCreated time.Time
-	DateUpdated time.Time
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Type         hometype.HomeType
+	Address      Address
+	OrgID        uuid.UUID
+	DateCreated  time.Time
+	DateUpdated  time.Time
+	DateArchived time.Time
+	Version      int
+}
+
+// Archived reports whether the home has been archived.
+func (h Home) Archived() bool {
+	return !h.DateArchived.IsZero()
 }
 
 // NewHome is what we require from clients when adding a Home.
@@ -20,6 +20,11 @@ func (s *Store) applyFilter(filter homebus.QueryFilter, data map[string]any, buf
 		wc = append(wc, "user_id = :user_id")
 	}
 
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
 	if filter.Type != nil {
 		data["type"] = filter.Type.String()
 		wc = append(wc, "type = :type")
@@ -35,6 +40,15 @@ func (s *Store) applyFilter(filter homebus.QueryFilter, data map[string]any, buf
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	switch {
+	case filter.Archived != nil && *filter.Archived:
+		wc = append(wc, "date_archived IS NOT NULL")
+	default:
+		wc = append(wc, "date_archived IS NULL")
+	}
+
+	wc = append(wc, "date_deleted IS NULL")
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
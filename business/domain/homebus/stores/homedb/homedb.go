@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -50,9 +51,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
 func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
 	const q = `
     INSERT INTO homes
-        (home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated)
+        (home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, version)
     VALUES
-        (:home_id, :user_id, :type, :address_1, :address_2, :zip_code, :city, :state, :country, :date_created, :date_updated)`
+        (:home_id, :user_id, :type, :address_1, :address_2, :zip_code, :city, :state, :country, :org_id, :date_created, :date_updated, :version)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBHome(hme)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -61,28 +62,48 @@ func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
 	return nil
 }
 
-// Delete removes a home from the database.
-func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+// Delete marks the home identified by a given ID as deleted. The row is
+// kept until it is purged so the home can be restored. It will error if the
+// row is no longer at the version of the specified home.
+func (s *Store) Delete(ctx context.Context, hme homebus.Home, now time.Time) error {
 	data := struct {
-		ID string `db:"home_id"`
+		ID          string    `db:"home_id"`
+		Version     int       `db:"version"`
+		DateDeleted time.Time `db:"date_deleted"`
 	}{
-		ID: hme.ID.String(),
+		ID:          hme.ID.String(),
+		Version:     hme.Version,
+		DateDeleted: now.UTC(),
 	}
 
 	const q = `
-    DELETE FROM
-	    homes
+	UPDATE
+		homes
+	SET
+		"date_deleted" = :date_deleted,
+		"version" = version + 1
 	WHERE
-	  	home_id = :home_id`
+		home_id = :home_id AND
+		version = :version AND
+		date_deleted IS NULL
+	RETURNING
+		home_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", homebus.ErrVersionConflict)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Update replaces a home document in the database.
+// Update replaces a home document in the database. The home carries the
+// version it is moving to, so the row is only changed when it is still at the
+// version before that. It will error if the row was changed or deleted in the
+// meantime.
 func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
 	const q = `
     UPDATE
@@ -95,12 +116,21 @@ func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
         "state"         = :state,
         "country"       = :country,
         "type"          = :type,
-        "date_updated"  = :date_updated
+        "date_updated"  = :date_updated,
+        "version"       = :version
     WHERE
-        home_id = :home_id`
+        home_id = :home_id AND
+        version = :version - 1 AND
+        date_deleted IS NULL
+    RETURNING
+        home_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBHome(hme)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBHome(hme), &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", homebus.ErrVersionConflict)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -115,7 +145,7 @@ func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy o
 
 	const q = `
     SELECT
-	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated
+	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version
 	FROM
 	  	homes`
 
@@ -166,7 +196,8 @@ func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, err
 	return count.Count, nil
 }
 
-// QueryByID gets the specified home from the database.
+// QueryByID gets the specified home from the database. Archived homes are
+// returned as well so callers can see their state.
 func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
 	data := struct {
 		ID string `db:"home_id"`
@@ -176,11 +207,12 @@ func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home,
 
 	const q = `
     SELECT
-	  	home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated
+	  	home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version
     FROM
         homes
     WHERE
-        home_id = :home_id`
+        home_id = :home_id AND
+        date_deleted IS NULL`
 
 	var dbHme home
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbHme); err != nil {
@@ -193,7 +225,7 @@ func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home,
 	return toBusHome(dbHme)
 }
 
-// QueryByUserID gets the specified home from the database by user id.
+// QueryByUserID gets the active homes from the database by user id.
 func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
 	data := struct {
 		ID string `db:"user_id"`
@@ -203,11 +235,13 @@ func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.
 
 	const q = `
 	SELECT
-	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated
+	    home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version
 	FROM
 		homes
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		date_archived IS NULL AND
+		date_deleted IS NULL`
 
 	var dbHmes []home
 	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbHmes); err != nil {
@@ -216,3 +250,127 @@ func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.
 
 	return toBusHomes(dbHmes)
 }
+
+// ArchiveByUserID marks all the active homes for the specified user as
+// archived.
+func (s *Store) ArchiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]homebus.Home, error) {
+	data := struct {
+		UserID       string    `db:"user_id"`
+		DateArchived time.Time `db:"date_archived"`
+	}{
+		UserID:       userID.String(),
+		DateArchived: now.UTC(),
+	}
+
+	const q = `
+    UPDATE
+        homes
+    SET
+        "date_archived" = :date_archived,
+        "version" = version + 1
+    WHERE
+        user_id = :user_id AND
+        date_archived IS NULL
+    RETURNING
+        home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version`
+
+	var dbHmes []home
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbHmes); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusHomes(dbHmes)
+}
+
+// RestoreByUserID makes all the archived homes for the specified user active
+// again.
+func (s *Store) RestoreByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+    UPDATE
+        homes
+    SET
+        "date_archived" = NULL,
+        "version" = version + 1
+    WHERE
+        user_id = :user_id AND
+        date_archived IS NOT NULL
+    RETURNING
+        home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version`
+
+	var dbHmes []home
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbHmes); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusHomes(dbHmes)
+}
+
+// Restore clears the deleted mark from the specified home and returns it.
+func (s *Store) Restore(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	data := struct {
+		ID string `db:"home_id"`
+	}{
+		ID: homeID.String(),
+	}
+
+	const q = `
+	UPDATE
+		homes
+	SET
+		"date_deleted" = NULL,
+		"version" = version + 1
+	WHERE
+		home_id = :home_id AND
+		date_deleted IS NOT NULL
+	RETURNING
+		home_id, user_id, type, address_1, address_2, zip_code, city, state, country, org_id, date_created, date_updated, date_archived, version`
+
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return homebus.Home{}, fmt.Errorf("db: %w", homebus.ErrNotFound)
+		}
+		return homebus.Home{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusHome(dbHme)
+}
+
+// Purge permanently removes the homes deleted before the specified time
+// and returns how many were removed.
+func (s *Store) Purge(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH purged AS (
+		DELETE FROM
+			homes
+		WHERE
+			date_deleted < :before
+		RETURNING
+			home_id
+	)
+	SELECT
+		count(1)
+	FROM
+		purged`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -1,6 +1,7 @@
 package homedb
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -10,17 +11,20 @@ import (
 )
 
 type home struct {
-	ID          uuid.UUID `db:"home_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Type        string    `db:"type"`
-	Address1    string    `db:"address_1"`
-	Address2    string    `db:"address_2"`
-	ZipCode     string    `db:"zip_code"`
-	City        string    `db:"city"`
-	Country     string    `db:"country"`
-	State       string    `db:"state"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID           uuid.UUID    `db:"home_id"`
+	UserID       uuid.UUID    `db:"user_id"`
+	Type         string       `db:"type"`
+	Address1     string       `db:"address_1"`
+	Address2     string       `db:"address_2"`
+	ZipCode      string       `db:"zip_code"`
+	City         string       `db:"city"`
+	Country      string       `db:"country"`
+	State        string       `db:"state"`
+	OrgID        uuid.UUID    `db:"org_id"`
+	DateCreated  time.Time    `db:"date_created"`
+	DateUpdated  time.Time    `db:"date_updated"`
+	DateArchived sql.NullTime `db:"date_archived"`
+	Version      int          `db:"version"`
 }
 
 func toDBHome(bus homebus.Home) home {
@@ -34,8 +38,14 @@ func toDBHome(bus homebus.Home) home {
 		City:        bus.Address.City,
 		Country:     bus.Address.Country,
 		State:       bus.Address.State,
+		OrgID:       bus.OrgID,
 		DateCreated: bus.DateCreated.UTC(),
 		DateUpdated: bus.DateUpdated.UTC(),
+		DateArchived: sql.NullTime{
+			Time:  bus.DateArchived.UTC(),
+			Valid: !bus.DateArchived.IsZero(),
+		},
+		Version: bus.Version,
 	}
 
 	return db
@@ -59,8 +69,14 @@ func toBusHome(db home) (homebus.Home, error) {
 			Country:  db.Country,
 			State:    db.State,
 		},
+		OrgID:       db.OrgID,
 		DateCreated: db.DateCreated.In(time.Local),
 		DateUpdated: db.DateUpdated.In(time.Local),
+		Version:     db.Version,
+	}
+
+	if db.DateArchived.Valid {
+		bus.DateArchived = db.DateArchived.Time.In(time.Local)
 	}
 
 	return bus, nil
@@ -0,0 +1,210 @@
+// Package identitybus provides business access to the identities users have
+// at external identity providers, such as the ones used for OAuth logins.
+package identitybus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("identity not found")
+	ErrUniqueIdentity  = errors.New("identity already linked")
+	ErrEmailUnverified = errors.New("email in use and not verified by the provider")
+	ErrLinkNotAllowed  = errors.New("email in use and can't be linked to this provider")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, idn Identity) error
+	Update(ctx context.Context, idn Identity) error
+	QueryByProvider(ctx context.Context, provider string, providerUserID string) (Identity, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Identity, error)
+}
+
+// Business manages the set of APIs for identity access.
+type Business struct {
+	log     *logger.Logger
+	cfg     Config
+	userBus *userbus.Business
+	storer  Storer
+}
+
+// NewBusiness constructs an identity business API for use.
+func NewBusiness(log *logger.Logger, cfg Config, userBus *userbus.Business, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		cfg:     cfg,
+		userBus: userBus,
+		storer:  storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := b.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:     b.log,
+		cfg:     b.cfg,
+		userBus: userBus,
+		storer:  storer,
+	}
+
+	return &bus, nil
+}
+
+// FindOrCreateUser returns the user linked to the provider identity. When the
+// identity is not linked yet, it is linked to the user with the same email
+// if the provider is trusted and verified that email, otherwise a new user
+// with the default USER role is created. Admins are never linked this way
+// since taking over their account is worth the most.
+func (b *Business) FindOrCreateUser(ctx context.Context, ni NewIdentity) (userbus.User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.identitybus.findorcreateuser")
+	defer span.End()
+
+	idn, err := b.storer.QueryByProvider(ctx, ni.Provider, ni.ProviderUserID)
+	switch {
+	case err == nil:
+		usr, err := b.userBus.QueryByID(ctx, idn.UserID)
+		if err != nil {
+			return userbus.User{}, fmt.Errorf("querybyid: userID[%s]: %w", idn.UserID, err)
+		}
+
+		if idn.Email.Address != ni.Email.Address {
+			idn.Email = ni.Email
+			idn.DateUpdated = time.Now()
+
+			if err := b.storer.Update(ctx, idn); err != nil {
+				return userbus.User{}, fmt.Errorf("update: %w", err)
+			}
+		}
+
+		return usr, nil
+
+	case !errors.Is(err, ErrNotFound):
+		return userbus.User{}, fmt.Errorf("querybyprovider: %w", err)
+	}
+
+	usr, err := b.userBus.QueryByEmail(ctx, ni.Email)
+	switch {
+	case err == nil:
+		if !ni.EmailVerified {
+			return userbus.User{}, ErrEmailUnverified
+		}
+
+		if !slices.Contains(b.cfg.TrustedProviders, ni.Provider) || slices.Contains(usr.Roles, role.Admin) {
+			return userbus.User{}, ErrLinkNotAllowed
+		}
+
+		b.log.Info(ctx, "identitybus: linking identity to existing user", "provider", ni.Provider, "user_id", usr.ID)
+
+	case errors.Is(err, userbus.ErrNotFound):
+		usr, err = b.createUser(ctx, ni)
+		if err != nil {
+			return userbus.User{}, err
+		}
+
+	default:
+		return userbus.User{}, fmt.Errorf("querybyemail: %w", err)
+	}
+
+	if _, err := b.link(ctx, usr.ID, ni); err != nil {
+		return userbus.User{}, err
+	}
+
+	return usr, nil
+}
+
+// QueryByUserID returns the identities linked to the specified user.
+func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Identity, error) {
+	ctx, span := otel.AddSpan(ctx, "business.identitybus.querybyuserid")
+	defer span.End()
+
+	idns, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return idns, nil
+}
+
+// =============================================================================
+
+func (b *Business) createUser(ctx context.Context, ni NewIdentity) (userbus.User, error) {
+
+	// Users created from an identity provider sign in through the provider,
+	// so they get a random password nobody knows. They can set one using the
+	// password reset flow.
+	password, err := randomPassword()
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("randompassword: %w", err)
+	}
+
+	nu := userbus.NewUser{
+		Name:     ni.Name,
+		Email:    ni.Email,
+		Roles:    []role.Role{role.User},
+		Password: password,
+	}
+
+	usr, err := b.userBus.Create(ctx, nu)
+	if err != nil {
+		return userbus.User{}, fmt.Errorf("create user: %w", err)
+	}
+
+	return usr, nil
+}
+
+func (b *Business) link(ctx context.Context, userID uuid.UUID, ni NewIdentity) (Identity, error) {
+	now := time.Now()
+
+	idn := Identity{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Provider:       ni.Provider,
+		ProviderUserID: ni.ProviderUserID,
+		Email:          ni.Email,
+		DateCreated:    now,
+		DateUpdated:    now,
+	}
+
+	if err := b.storer.Create(ctx, idn); err != nil {
+		return Identity{}, fmt.Errorf("create: %w", err)
+	}
+
+	return idn, nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
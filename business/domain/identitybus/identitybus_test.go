@@ -0,0 +1,252 @@
+package identitybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Identity(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Identity")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, findOrCreate(db.BusDomain, sd), "findorcreate")
+	unitest.Run(t, tran(db), "tran")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	admins, err := userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users:  []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+		Admins: []unitest.User{{User: admins[0]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func findOrCreate(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	email, _ := mail.ParseAddress("oauth@ardanlabs.com")
+
+	table := []unitest.Table{
+		{
+			Name:    "create",
+			ExpResp: []role.Role{role.User},
+			ExcFunc: func(ctx context.Context) any {
+				ni := identitybus.NewIdentity{
+					Provider:       "github",
+					ProviderUserID: "1001",
+					Email:          *email,
+					Name:           name.MustParse("OAuth User"),
+				}
+
+				usr, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+				if err != nil {
+					return err
+				}
+
+				// A second login must return the same user.
+				usr2, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+				if err != nil {
+					return err
+				}
+
+				if usr.ID != usr2.ID {
+					return fmt.Errorf("expected the same user, got %s and %s", usr.ID, usr2.ID)
+				}
+
+				return usr.Roles
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "link-verified",
+			ExpResp: sd.Users[0].ID,
+			ExcFunc: func(ctx context.Context) any {
+				ni := identitybus.NewIdentity{
+					Provider:       "google",
+					ProviderUserID: "2001",
+					Email:          sd.Users[0].Email,
+					EmailVerified:  true,
+					Name:           sd.Users[0].Name,
+				}
+
+				usr, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+				if err != nil {
+					return err
+				}
+
+				return usr.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "link-unverified",
+			ExpResp: identitybus.ErrEmailUnverified,
+			ExcFunc: func(ctx context.Context) any {
+				ni := identitybus.NewIdentity{
+					Provider:       "google",
+					ProviderUserID: "2002",
+					Email:          sd.Users[1].Email,
+					Name:           sd.Users[1].Name,
+				}
+
+				_, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				err, ok := got.(error)
+				if !ok || !errors.Is(err, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "link-untrusted",
+			ExpResp: identitybus.ErrLinkNotAllowed,
+			ExcFunc: func(ctx context.Context) any {
+				ni := identitybus.NewIdentity{
+					Provider:       "github",
+					ProviderUserID: "2003",
+					Email:          sd.Users[1].Email,
+					EmailVerified:  true,
+					Name:           sd.Users[1].Name,
+				}
+
+				_, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				err, ok := got.(error)
+				if !ok || !errors.Is(err, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "link-admin",
+			ExpResp: identitybus.ErrLinkNotAllowed,
+			ExcFunc: func(ctx context.Context) any {
+				ni := identitybus.NewIdentity{
+					Provider:       "google",
+					ProviderUserID: "2004",
+					Email:          sd.Admins[0].Email,
+					EmailVerified:  true,
+					Name:           sd.Admins[0].Name,
+				}
+
+				_, err := busDomain.Identity.FindOrCreateUser(ctx, ni)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				err, ok := got.(error)
+				if !ok || !errors.Is(err, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func tran(db *dbtest.Database) []unitest.Table {
+	email, _ := mail.ParseAddress("rollback@ardanlabs.com")
+
+	table := []unitest.Table{
+		{
+			Name:    "rollback",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				tx, err := sqldb.NewBeginner(db.DB).Begin()
+				if err != nil {
+					return err
+				}
+
+				identityBus, err := db.BusDomain.Identity.NewWithTx(tx)
+				if err != nil {
+					return err
+				}
+
+				ni := identitybus.NewIdentity{
+					Provider:       "google",
+					ProviderUserID: "3003",
+					Email:          *email,
+					Name:           name.MustParse("Rolled Back"),
+					EmailVerified:  true,
+				}
+
+				if _, err := identityBus.FindOrCreateUser(ctx, ni); err != nil {
+					return err
+				}
+
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+
+				// Neither the user nor the identity may survive the rollback.
+
+				_, errUsr := db.BusDomain.User.QueryByEmail(ctx, *email)
+				usr, err := db.BusDomain.Identity.FindOrCreateUser(ctx, ni)
+				if err != nil {
+					return err
+				}
+
+				idns, err := db.BusDomain.Identity.QueryByUserID(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				return errors.Is(errUsr, userbus.ErrNotFound) && len(idns) == 1
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,37 @@
+package identitybus
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+// Identity links an account at an external identity provider to a user.
+type Identity struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Provider       string
+	ProviderUserID string
+	Email          mail.Address
+	DateCreated    time.Time
+	DateUpdated    time.Time
+}
+
+// Config controls which identities can be linked to an existing user. Only
+// the providers in TrustedProviders are trusted to prove that the person
+// signing in owns the email of the user.
+type Config struct {
+	TrustedProviders []string
+}
+
+// NewIdentity contains the information returned by an identity provider
+// after a user has signed in.
+type NewIdentity struct {
+	Provider       string
+	ProviderUserID string
+	Email          mail.Address
+	EmailVerified  bool
+	Name           name.Name
+}
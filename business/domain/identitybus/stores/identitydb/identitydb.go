@@ -0,0 +1,136 @@
+// Package identitydb contains identity related CRUD functionality.
+package identitydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for identity database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (identitybus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new identity into the database.
+func (s *Store) Create(ctx context.Context, idn identitybus.Identity) error {
+	const q = `
+	INSERT INTO identities
+		(identity_id, user_id, provider, provider_user_id, email, date_created, date_updated)
+	VALUES
+		(:identity_id, :user_id, :provider, :provider_user_id, :email, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", identitybus.ErrUniqueIdentity)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces an identity document in the database.
+func (s *Store) Update(ctx context.Context, idn identitybus.Identity) error {
+	const q = `
+	UPDATE
+		identities
+	SET
+		"email" = :email,
+		"date_updated" = :date_updated
+	WHERE
+		identity_id = :identity_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByProvider gets the identity for the provider account from the database.
+func (s *Store) QueryByProvider(ctx context.Context, provider string, providerUserID string) (identitybus.Identity, error) {
+	data := struct {
+		Provider       string `db:"provider"`
+		ProviderUserID string `db:"provider_user_id"`
+	}{
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+	}
+
+	const q = `
+	SELECT
+		identity_id, user_id, provider, provider_user_id, email, date_created, date_updated
+	FROM
+		identities
+	WHERE
+		provider = :provider AND
+		provider_user_id = :provider_user_id`
+
+	var dbIdn identity
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdn); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return identitybus.Identity{}, fmt.Errorf("db: %w", identitybus.ErrNotFound)
+		}
+		return identitybus.Identity{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusIdentity(dbIdn), nil
+}
+
+// QueryByUserID gets the identities linked to the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]identitybus.Identity, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		identity_id, user_id, provider, provider_user_id, email, date_created, date_updated
+	FROM
+		identities
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		date_created`
+
+	var dbIdns []identity
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbIdns); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusIdentities(dbIdns), nil
+}
@@ -0,0 +1,53 @@
+package identitydb
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/google/uuid"
+)
+
+type identity struct {
+	ID             uuid.UUID `db:"identity_id"`
+	UserID         uuid.UUID `db:"user_id"`
+	Provider       string    `db:"provider"`
+	ProviderUserID string    `db:"provider_user_id"`
+	Email          string    `db:"email"`
+	DateCreated    time.Time `db:"date_created"`
+	DateUpdated    time.Time `db:"date_updated"`
+}
+
+func toDBIdentity(bus identitybus.Identity) identity {
+	return identity{
+		ID:             bus.ID,
+		UserID:         bus.UserID,
+		Provider:       bus.Provider,
+		ProviderUserID: bus.ProviderUserID,
+		Email:          bus.Email.Address,
+		DateCreated:    bus.DateCreated.UTC(),
+		DateUpdated:    bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusIdentity(db identity) identitybus.Identity {
+	return identitybus.Identity{
+		ID:             db.ID,
+		UserID:         db.UserID,
+		Provider:       db.Provider,
+		ProviderUserID: db.ProviderUserID,
+		Email:          mail.Address{Address: db.Email},
+		DateCreated:    db.DateCreated.In(time.Local),
+		DateUpdated:    db.DateUpdated.In(time.Local),
+	}
+}
+
+func toBusIdentities(dbs []identity) []identitybus.Identity {
+	bus := make([]identitybus.Identity, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusIdentity(db)
+	}
+
+	return bus
+}
@@ -0,0 +1,168 @@
+// Package lockoutbus provides business access to the login lockout domain.
+// It tracks failed login attempts per email and per remote IP and locks them
+// out with an exponential backoff.
+package lockoutbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+)
+
+// ErrLocked is returned when too many failed attempts have been made.
+var ErrLocked = errors.New("too many failed login attempts")
+
+// LockedError reports a lockout and how long until another attempt can be
+// made. It wraps ErrLocked.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (le *LockedError) Error() string {
+	return ErrLocked.Error()
+}
+
+// Unwrap provides support for errors.Is.
+func (le *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Fail(ctx context.Context, key Key, now time.Time, resetBefore time.Time) (Attempt, error)
+	Lock(ctx context.Context, atm Attempt) error
+	Delete(ctx context.Context, key Key) error
+	QueryByKeys(ctx context.Context, keys []Key) ([]Attempt, error)
+}
+
+// Business manages the set of APIs for lockout access.
+type Business struct {
+	log    *logger.Logger
+	cfg    Config
+	storer Storer
+}
+
+// NewBusiness constructs a lockout business API for use.
+func NewBusiness(log *logger.Logger, cfg Config, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		cfg:    cfg,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		cfg:    b.cfg,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Check returns a LockedError if any of the keys are currently locked out.
+func (b *Business) Check(ctx context.Context, keys ...Key) error {
+	ctx, span := otel.AddSpan(ctx, "business.lockoutbus.check")
+	defer span.End()
+
+	atms, err := b.storer.QueryByKeys(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("querybykeys: %w", err)
+	}
+
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for _, atm := range atms {
+		if atm.Locked(now) {
+			retryAfter = max(retryAfter, atm.DateLockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt against each key and locks any key that has
+// reached its threshold.
+func (b *Business) Fail(ctx context.Context, keys ...Key) error {
+	ctx, span := otel.AddSpan(ctx, "business.lockoutbus.fail")
+	defer span.End()
+
+	now := time.Now()
+
+	for _, key := range keys {
+		atm, err := b.storer.Fail(ctx, key, now, now.Add(-b.cfg.ResetAfter))
+		if err != nil {
+			return fmt.Errorf("fail: key[%s]: %w", key, err)
+		}
+
+		lockout := b.lockout(key, atm.Failures)
+		if lockout == 0 {
+			continue
+		}
+
+		atm.DateLockedUntil = now.Add(lockout)
+		if err := b.storer.Lock(ctx, atm); err != nil {
+			return fmt.Errorf("lock: key[%s]: %w", key, err)
+		}
+
+		b.log.Info(ctx, "lockoutbus: locked", "key", key, "failures", atm.Failures, "until", atm.DateLockedUntil)
+	}
+
+	return nil
+}
+
+// Reset clears the failed attempts recorded against the keys. This is called
+// after a successful login and by administrators to unlock an account.
+func (b *Business) Reset(ctx context.Context, keys ...Key) error {
+	ctx, span := otel.AddSpan(ctx, "business.lockoutbus.reset")
+	defer span.End()
+
+	for _, key := range keys {
+		if err := b.storer.Delete(ctx, key); err != nil {
+			return fmt.Errorf("delete: key[%s]: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// lockout calculates how long a key is locked for after the specified number
+// of consecutive failures. Zero means the key is not locked.
+func (b *Business) lockout(key Key, failures int) time.Duration {
+	threshold := b.cfg.IPThreshold
+	if key.IsEmail() {
+		threshold = b.cfg.EmailThreshold
+	}
+
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	lockout := b.cfg.BaseLockout
+	for i := threshold; i < failures && lockout < b.cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, b.cfg.MaxLockout)
+}
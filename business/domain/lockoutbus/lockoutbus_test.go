@@ -0,0 +1,129 @@
+package lockoutbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus/stores/lockoutdb"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Lockout(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Lockout")
+
+	cfg := lockoutbus.Config{
+		EmailThreshold: 3,
+		IPThreshold:    5,
+		BaseLockout:    time.Minute,
+		MaxLockout:     4 * time.Minute,
+		ResetAfter:     time.Hour,
+	}
+
+	lockoutBus := lockoutbus.NewBusiness(db.Log, cfg, lockoutdb.NewStore(db.Log, db.DB))
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, lockout(lockoutBus), "lockout")
+}
+
+// =============================================================================
+
+func lockout(lockoutBus *lockoutbus.Business) []unitest.Table {
+	retryAfter := func(err error) time.Duration {
+		var le *lockoutbus.LockedError
+		if !errors.As(err, &le) {
+			return 0
+		}
+		return le.RetryAfter
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "threshold",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				key := lockoutbus.EmailKey(mail.Address{Address: "threshold@example.com"})
+
+				for i := range 2 {
+					if err := lockoutBus.Fail(ctx, key); err != nil {
+						return err
+					}
+					if err := lockoutBus.Check(ctx, key); err != nil {
+						return fmt.Errorf("locked after %d failures: %w", i+1, err)
+					}
+				}
+
+				if err := lockoutBus.Fail(ctx, key); err != nil {
+					return err
+				}
+
+				d := retryAfter(lockoutBus.Check(ctx, key))
+				return d > 0 && d <= time.Minute
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "backoff",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				key := lockoutbus.EmailKey(mail.Address{Address: "backoff@example.com"})
+
+				for range 6 {
+					if err := lockoutBus.Fail(ctx, key); err != nil {
+						return err
+					}
+				}
+
+				// 3 failures lock for 1m, 4 for 2m, 5 for 4m and 6 is capped at 4m.
+				d := retryAfter(lockoutBus.Check(ctx, key))
+				return d > 3*time.Minute && d <= 4*time.Minute
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "reset",
+			ExpResp: nil,
+			ExcFunc: func(ctx context.Context) any {
+				emailKey := lockoutbus.EmailKey(mail.Address{Address: "reset@example.com"})
+				ipKey := lockoutbus.IPKey("10.0.0.1")
+
+				for range 3 {
+					if err := lockoutBus.Fail(ctx, emailKey, ipKey); err != nil {
+						return err
+					}
+				}
+
+				if err := lockoutBus.Check(ctx, emailKey, ipKey); !errors.Is(err, lockoutbus.ErrLocked) {
+					return fmt.Errorf("expected lockout, got %v", err)
+				}
+
+				if err := lockoutBus.Reset(ctx, emailKey); err != nil {
+					return err
+				}
+
+				return lockoutBus.Check(ctx, emailKey, ipKey)
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != nil {
+					return fmt.Sprintf("got %v, exp nil", got)
+				}
+				return ""
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,67 @@
+package lockoutbus
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Key identifies what failed login attempts are tracked against.
+type Key struct {
+	value string
+}
+
+// EmailKey constructs the key for tracking attempts against an email.
+func EmailKey(email mail.Address) Key {
+	return Key{"email:" + strings.ToLower(email.Address)}
+}
+
+// IPKey constructs the key for tracking attempts from a remote IP.
+func IPKey(ip string) Key {
+	return Key{"ip:" + ip}
+}
+
+// String returns the string representation of the key.
+func (k Key) String() string {
+	return k.value
+}
+
+// IsEmail reports if the key tracks an email.
+func (k Key) IsEmail() bool {
+	return strings.HasPrefix(k.value, "email:")
+}
+
+// Attempt represents the failed login attempts recorded for a key.
+type Attempt struct {
+	Key             Key
+	Failures        int
+	DateLastFailure time.Time
+	DateLockedUntil time.Time
+}
+
+// Locked reports if the key is locked out at the specified time.
+func (a Attempt) Locked(now time.Time) bool {
+	return now.Before(a.DateLockedUntil)
+}
+
+// Config represents the lockout policy. A key is locked once it reaches its
+// threshold of consecutive failures. The first lockout lasts BaseLockout and
+// doubles with every further failure up to MaxLockout. Failures are forgotten
+// once ResetAfter has passed since the last one. A threshold of zero
+// disables the lockout for that kind of key.
+type Config struct {
+	EmailThreshold int
+	IPThreshold    int
+	BaseLockout    time.Duration
+	MaxLockout     time.Duration
+	ResetAfter     time.Duration
+}
+
+// DefaultConfig provides a reasonable lockout policy.
+var DefaultConfig = Config{
+	EmailThreshold: 5,
+	IPThreshold:    20,
+	BaseLockout:    time.Minute,
+	MaxLockout:     time.Hour,
+	ResetAfter:     time.Hour,
+}
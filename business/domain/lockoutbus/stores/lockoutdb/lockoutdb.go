@@ -0,0 +1,156 @@
+// Package lockoutdb contains login lockout related CRUD functionality.
+package lockoutdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for lockout database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (lockoutbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Fail atomically records a failed attempt for the key. If the last failure
+// happened before resetBefore the count starts over.
+func (s *Store) Fail(ctx context.Context, key lockoutbus.Key, now time.Time, resetBefore time.Time) (lockoutbus.Attempt, error) {
+	data := struct {
+		Key         string    `db:"attempt_key"`
+		Now         time.Time `db:"now"`
+		ResetBefore time.Time `db:"reset_before"`
+	}{
+		Key:         key.String(),
+		Now:         now.UTC(),
+		ResetBefore: resetBefore.UTC(),
+	}
+
+	const q = `
+	INSERT INTO login_attempts
+		(attempt_key, failures, date_last_failure, date_locked_until)
+	VALUES
+		(:attempt_key, 1, :now, NULL)
+	ON CONFLICT (attempt_key) DO UPDATE SET
+		failures = CASE
+			WHEN login_attempts.date_last_failure < :reset_before THEN 1
+			ELSE login_attempts.failures + 1
+		END,
+		date_last_failure = :now
+	RETURNING
+		attempt_key, failures, date_last_failure, date_locked_until`
+
+	var dbAtm attempt
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbAtm); err != nil {
+		return lockoutbus.Attempt{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAttempt(key, dbAtm), nil
+}
+
+// Lock sets the time the key is locked until.
+func (s *Store) Lock(ctx context.Context, atm lockoutbus.Attempt) error {
+	const q = `
+	UPDATE
+		login_attempts
+	SET
+		date_locked_until = :date_locked_until
+	WHERE
+		attempt_key = :attempt_key`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAttempt(atm)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the attempts recorded for the key.
+func (s *Store) Delete(ctx context.Context, key lockoutbus.Key) error {
+	data := struct {
+		Key string `db:"attempt_key"`
+	}{
+		Key: key.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		login_attempts
+	WHERE
+		attempt_key = :attempt_key`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByKeys retrieves the attempts recorded for the specified keys.
+func (s *Store) QueryByKeys(ctx context.Context, keys []lockoutbus.Key) ([]lockoutbus.Attempt, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	byKey := make(map[string]lockoutbus.Key, len(keys))
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		byKey[key.String()] = key
+		values[i] = key.String()
+	}
+
+	data := struct {
+		Keys []string `db:"attempt_keys"`
+	}{
+		Keys: values,
+	}
+
+	const q = `
+	SELECT
+		attempt_key, failures, date_last_failure, date_locked_until
+	FROM
+		login_attempts
+	WHERE
+		attempt_key IN (:attempt_keys)`
+
+	var dbAtms []attempt
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbAtms); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	atms := make([]lockoutbus.Attempt, len(dbAtms))
+	for i, dbAtm := range dbAtms {
+		atms[i] = toBusAttempt(byKey[dbAtm.Key], dbAtm)
+	}
+
+	return atms, nil
+}
@@ -0,0 +1,41 @@
+package lockoutdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+)
+
+type attempt struct {
+	Key             string       `db:"attempt_key"`
+	Failures        int          `db:"failures"`
+	DateLastFailure time.Time    `db:"date_last_failure"`
+	DateLockedUntil sql.NullTime `db:"date_locked_until"`
+}
+
+func toDBAttempt(bus lockoutbus.Attempt) attempt {
+	return attempt{
+		Key:             bus.Key.String(),
+		Failures:        bus.Failures,
+		DateLastFailure: bus.DateLastFailure.UTC(),
+		DateLockedUntil: sql.NullTime{
+			Time:  bus.DateLockedUntil.UTC(),
+			Valid: !bus.DateLockedUntil.IsZero(),
+		},
+	}
+}
+
+func toBusAttempt(key lockoutbus.Key, db attempt) lockoutbus.Attempt {
+	bus := lockoutbus.Attempt{
+		Key:             key,
+		Failures:        db.Failures,
+		DateLastFailure: db.DateLastFailure.In(time.Local),
+	}
+
+	if db.DateLockedUntil.Valid {
+		bus.DateLockedUntil = db.DateLockedUntil.Time.In(time.Local)
+	}
+
+	return bus
+}
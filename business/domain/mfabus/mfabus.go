@@ -0,0 +1,388 @@
+// Package mfabus provides business access to multi-factor authentication
+// using time based one time passwords (TOTP) and single use recovery codes.
+package mfabus
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/totp"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for mfa operations.
+var (
+	ErrNotFound       = errors.New("mfa not found")
+	ErrNotEnabled     = errors.New("mfa not enabled")
+	ErrAlreadyEnabled = errors.New("mfa already enabled")
+	ErrInvalidCode    = errors.New("mfa code invalid")
+	ErrUnavailable    = errors.New("mfa is not configured")
+)
+
+// Number and size of the recovery codes issued at enrolment.
+const (
+	recoveryCodes    = 10
+	recoveryCodeSize = 10
+)
+
+// recoveryAlphabet is the lower case base32 alphabet. It has exactly 32
+// characters so a random byte maps onto it without bias.
+const recoveryAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Upsert(ctx context.Context, mfa MFA) error
+	Update(ctx context.Context, mfa MFA) error
+	Delete(ctx context.Context, mfa MFA) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) (MFA, error)
+}
+
+// Business manages the set of APIs for mfa access.
+type Business struct {
+	log    *logger.Logger
+	cfg    Config
+	storer Storer
+}
+
+// NewBusiness constructs a mfa business API for use.
+func NewBusiness(log *logger.Logger, cfg Config, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		cfg:    cfg,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		cfg:    b.cfg,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Enroll generates a new secret and set of recovery codes for the user. The
+// second factor isn't required until the enrolment is confirmed with a code
+// from the authenticator application. Enrolling again before confirming
+// replaces the pending secret.
+func (b *Business) Enroll(ctx context.Context, userID uuid.UUID, account string) (Enrollment, error) {
+	ctx, span := otel.AddSpan(ctx, "business.mfabus.enroll")
+	defer span.End()
+
+	if len(b.cfg.Key) == 0 {
+		return Enrollment{}, ErrUnavailable
+	}
+
+	current, err := b.storer.QueryByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		if current.Enabled() {
+			return Enrollment{}, ErrAlreadyEnabled
+		}
+
+	case !errors.Is(err, ErrNotFound):
+		return Enrollment{}, fmt.Errorf("querybyuserid: %w", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("generatesecret: %w", err)
+	}
+
+	sealed, err := b.seal(secret)
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("seal: %w", err)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("generaterecoverycodes: %w", err)
+	}
+
+	now := time.Now()
+
+	mfa := MFA{
+		UserID:         userID,
+		Secret:         sealed,
+		RecoveryHashes: hashes,
+		DateCreated:    now,
+		DateUpdated:    now,
+	}
+
+	if err := b.storer.Upsert(ctx, mfa); err != nil {
+		return Enrollment{}, fmt.Errorf("upsert: %w", err)
+	}
+
+	enr := Enrollment{
+		Secret:        secret,
+		URI:           totp.URI(b.cfg.Issuer, account, secret),
+		RecoveryCodes: codes,
+	}
+
+	return enr, nil
+}
+
+// Confirm enables the second factor for the user once they prove their
+// authenticator application produces valid codes.
+func (b *Business) Confirm(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := otel.AddSpan(ctx, "business.mfabus.confirm")
+	defer span.End()
+
+	mfa, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("querybyuserid: %w", err)
+	}
+
+	if mfa.Enabled() {
+		return ErrAlreadyEnabled
+	}
+
+	step, err := b.validateCode(mfa, code)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	mfa.LastStep = step
+	mfa.DateEnabled = now
+	mfa.DateUpdated = now
+
+	if err := b.storer.Update(ctx, mfa); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Verify checks the code provided at login. The code can be the current
+// code from the authenticator application or one of the unused recovery
+// codes, which is consumed in the process.
+func (b *Business) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := otel.AddSpan(ctx, "business.mfabus.verify")
+	defer span.End()
+
+	mfa, err := b.queryEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	step, err := b.validateCode(mfa, code)
+	switch {
+	case err == nil:
+		mfa.LastStep = step
+
+	case errors.Is(err, ErrInvalidCode):
+		idx := matchRecoveryCode(mfa.RecoveryHashes, code)
+		if idx < 0 {
+			return ErrInvalidCode
+		}
+
+		b.log.Info(ctx, "mfabus: recovery code used", "user_id", userID, "remaining", len(mfa.RecoveryHashes)-1)
+		mfa.RecoveryHashes = append(mfa.RecoveryHashes[:idx:idx], mfa.RecoveryHashes[idx+1:]...)
+
+	default:
+		return err
+	}
+
+	mfa.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, mfa); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Disable removes the second factor for the user. A valid code is required
+// so a stolen access token can't be used to turn it off.
+func (b *Business) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := otel.AddSpan(ctx, "business.mfabus.disable")
+	defer span.End()
+
+	if err := b.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	mfa, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("querybyuserid: %w", err)
+	}
+
+	if err := b.storer.Delete(ctx, mfa); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Enabled reports if the user must provide a second factor at login.
+func (b *Business) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	ctx, span := otel.AddSpan(ctx, "business.mfabus.enabled")
+	defer span.End()
+
+	mfa, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("querybyuserid: %w", err)
+	}
+
+	return mfa.Enabled(), nil
+}
+
+// =============================================================================
+
+func (b *Business) queryEnabled(ctx context.Context, userID uuid.UUID) (MFA, error) {
+	mfa, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return MFA{}, ErrNotEnabled
+		}
+		return MFA{}, fmt.Errorf("querybyuserid: %w", err)
+	}
+
+	if !mfa.Enabled() {
+		return MFA{}, ErrNotEnabled
+	}
+
+	return mfa, nil
+}
+
+// validateCode checks a TOTP code against the user's secret. A code for a
+// step that was already used is rejected so an observed code can't be
+// replayed.
+func (b *Business) validateCode(mfa MFA, code string) (int64, error) {
+	secret, err := b.open(mfa.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("open: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || step <= mfa.LastStep {
+		return 0, ErrInvalidCode
+	}
+
+	return step, nil
+}
+
+// seal encrypts the secret with AES-GCM. The random nonce is prepended to
+// the ciphertext.
+func (b *Business) seal(secret string) ([]byte, error) {
+	gcm, err := b.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(secret), nil), nil
+}
+
+// open decrypts a secret produced by seal.
+func (b *Business) open(sealed []byte) (string, error) {
+	gcm, err := b.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed secret too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	secret, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+
+	return string(secret), nil
+}
+
+func (b *Business) gcm() (cipher.AEAD, error) {
+	if len(b.cfg.Key) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	block, err := aes.NewCipher(b.cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// generateRecoveryCodes produces the raw recovery codes shown to the user
+// and the hashes that are stored.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodes)
+	hashes := make([]string, recoveryCodes)
+
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		for j := range b {
+			b[j] = recoveryAlphabet[b[j]&31]
+		}
+
+		code := string(b[:recoveryCodeSize/2]) + "-" + string(b[recoveryCodeSize/2:])
+
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// matchRecoveryCode returns the index of the hash matching the code or -1.
+func matchRecoveryCode(hashes []string, code string) int {
+	hash := []byte(hashRecoveryCode(code))
+
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), hash) == 1 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// hashRecoveryCode produces the value that is stored for a recovery code.
+// Codes are normalized so they can be typed without the separator.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
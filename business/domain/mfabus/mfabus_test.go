@@ -0,0 +1,156 @@
+package mfabus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/domain/mfabus/stores/mfadb"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/totp"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_MFA(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_MFA")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	cfg := mfabus.Config{
+		Key:    bytes.Repeat([]byte{7}, 32),
+		Issuer: "Test",
+	}
+
+	mfaBus := mfabus.NewBusiness(db.Log, cfg, mfadb.NewStore(db.Log, db.DB))
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, enroll(mfaBus, sd), "enroll")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func enroll(mfaBus *mfabus.Business, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "confirm",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				userID := sd.Users[0].ID
+
+				enr, err := mfaBus.Enroll(ctx, userID, sd.Users[0].Email.Address)
+				if err != nil {
+					return err
+				}
+
+				enabled, err := mfaBus.Enabled(ctx, userID)
+				if err != nil {
+					return err
+				}
+
+				if enabled {
+					return errors.New("enabled before confirming")
+				}
+
+				code, err := totp.Code(enr.Secret, time.Now())
+				if err != nil {
+					return err
+				}
+
+				if err := mfaBus.Confirm(ctx, userID, code); err != nil {
+					return err
+				}
+
+				// The code used to confirm can't be replayed.
+				if err := mfaBus.Verify(ctx, userID, code); !errors.Is(err, mfabus.ErrInvalidCode) {
+					return fmt.Errorf("replayed code: %v", err)
+				}
+
+				enabled, err = mfaBus.Enabled(ctx, userID)
+				if err != nil {
+					return err
+				}
+
+				return enabled && len(enr.RecoveryCodes) == 10
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "recovery",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				userID := sd.Users[1].ID
+
+				enr, err := mfaBus.Enroll(ctx, userID, sd.Users[1].Email.Address)
+				if err != nil {
+					return err
+				}
+
+				code, err := totp.Code(enr.Secret, time.Now())
+				if err != nil {
+					return err
+				}
+
+				if err := mfaBus.Confirm(ctx, userID, code); err != nil {
+					return err
+				}
+
+				if err := mfaBus.Verify(ctx, userID, enr.RecoveryCodes[0]); err != nil {
+					return err
+				}
+
+				// Recovery codes are single use.
+				if err := mfaBus.Verify(ctx, userID, enr.RecoveryCodes[0]); !errors.Is(err, mfabus.ErrInvalidCode) {
+					return fmt.Errorf("reused recovery code: %v", err)
+				}
+
+				if err := mfaBus.Disable(ctx, userID, enr.RecoveryCodes[1]); err != nil {
+					return err
+				}
+
+				enabled, err := mfaBus.Enabled(ctx, userID)
+				if err != nil {
+					return err
+				}
+
+				return !enabled
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,41 @@
+package mfabus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config represents the settings used to protect and present secrets. The
+// Key must be 32 bytes and is used to encrypt secrets with AES-256-GCM. The
+// Issuer is displayed by authenticator applications next to the account.
+type Config struct {
+	Key    []byte
+	Issuer string
+}
+
+// MFA represents the multi-factor settings of a user. The secret is only
+// ever held encrypted and recovery codes are stored as hashes.
+type MFA struct {
+	UserID         uuid.UUID
+	Secret         []byte
+	RecoveryHashes []string
+	LastStep       int64
+	DateEnabled    time.Time
+	DateCreated    time.Time
+	DateUpdated    time.Time
+}
+
+// Enabled reports if enrolment was confirmed and the second factor is
+// required at login.
+func (m MFA) Enabled() bool {
+	return !m.DateEnabled.IsZero()
+}
+
+// Enrollment represents what a user needs to set up their authenticator
+// application. It is only available at the time of enrolment.
+type Enrollment struct {
+	Secret        string
+	URI           string
+	RecoveryCodes []string
+}
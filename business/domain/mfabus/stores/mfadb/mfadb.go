@@ -0,0 +1,129 @@
+// Package mfadb contains mfa related CRUD functionality.
+package mfadb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for mfa database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (mfabus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Upsert inserts the mfa settings for a user, replacing any settings that
+// already exist.
+func (s *Store) Upsert(ctx context.Context, m mfabus.MFA) error {
+	const q = `
+	INSERT INTO user_mfa
+		(user_id, secret, recovery_hashes, last_step, date_enabled, date_created, date_updated)
+	VALUES
+		(:user_id, :secret, :recovery_hashes, :last_step, :date_enabled, :date_created, :date_updated)
+	ON CONFLICT (user_id) DO UPDATE SET
+		secret = EXCLUDED.secret,
+		recovery_hashes = EXCLUDED.recovery_hashes,
+		last_step = EXCLUDED.last_step,
+		date_enabled = EXCLUDED.date_enabled,
+		date_updated = EXCLUDED.date_updated`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFA(m)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the mfa settings for a user.
+func (s *Store) Update(ctx context.Context, m mfabus.MFA) error {
+	const q = `
+	UPDATE
+		user_mfa
+	SET
+		"secret" = :secret,
+		"recovery_hashes" = :recovery_hashes,
+		"last_step" = :last_step,
+		"date_enabled" = :date_enabled,
+		"date_updated" = :date_updated
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFA(m)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the mfa settings for a user.
+func (s *Store) Delete(ctx context.Context, m mfabus.MFA) error {
+	const q = `
+	DELETE FROM
+		user_mfa
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFA(m)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the mfa settings for the specified user.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) (mfabus.MFA, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, secret, recovery_hashes, last_step, date_enabled, date_created, date_updated
+	FROM
+		user_mfa
+	WHERE
+		user_id = :user_id`
+
+	var dbMFA mfa
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbMFA); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return mfabus.MFA{}, fmt.Errorf("db: %w", mfabus.ErrNotFound)
+		}
+		return mfabus.MFA{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusMFA(dbMFA), nil
+}
@@ -0,0 +1,52 @@
+package mfadb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/mfabus"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/google/uuid"
+)
+
+type mfa struct {
+	UserID         uuid.UUID      `db:"user_id"`
+	Secret         []byte         `db:"secret"`
+	RecoveryHashes dbarray.String `db:"recovery_hashes"`
+	LastStep       int64          `db:"last_step"`
+	DateEnabled    sql.NullTime   `db:"date_enabled"`
+	DateCreated    time.Time      `db:"date_created"`
+	DateUpdated    time.Time      `db:"date_updated"`
+}
+
+func toDBMFA(bus mfabus.MFA) mfa {
+	return mfa{
+		UserID:         bus.UserID,
+		Secret:         bus.Secret,
+		RecoveryHashes: bus.RecoveryHashes,
+		LastStep:       bus.LastStep,
+		DateEnabled: sql.NullTime{
+			Time:  bus.DateEnabled.UTC(),
+			Valid: !bus.DateEnabled.IsZero(),
+		},
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusMFA(db mfa) mfabus.MFA {
+	bus := mfabus.MFA{
+		UserID:         db.UserID,
+		Secret:         db.Secret,
+		RecoveryHashes: db.RecoveryHashes,
+		LastStep:       db.LastStep,
+		DateCreated:    db.DateCreated.In(time.Local),
+		DateUpdated:    db.DateUpdated.In(time.Local),
+	}
+
+	if db.DateEnabled.Valid {
+		bus.DateEnabled = db.DateEnabled.Time.In(time.Local)
+	}
+
+	return bus
+}
@@ -0,0 +1,22 @@
+package orgbus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+// Org represents an organisation, the tenant that users, products and homes
+// belong to.
+type Org struct {
+	ID          uuid.UUID
+	Name        name.Name
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewOrg contains information needed to create a new organisation.
+type NewOrg struct {
+	Name name.Name
+}
@@ -0,0 +1,13 @@
+package orgbus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByName, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "org_id"
+	OrderByName        = "name"
+	OrderByDateCreated = "date_created"
+)
@@ -0,0 +1,123 @@
+// Package orgbus provides business access to organisations, the tenants
+// the rest of the data is scoped to.
+package orgbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// DefaultID is the organisation created by the migrations. Data that existed
+// before organisations were introduced and users created without an
+// organisation belong to it.
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("org not found")
+	ErrUniqueName = errors.New("org name is not unique")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, org Org) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Org, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, orgID uuid.UUID) (Org, error)
+}
+
+// Business manages the set of APIs for organisation access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an organisation business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new organisation to the system.
+func (b *Business) Create(ctx context.Context, no NewOrg) (Org, error) {
+	ctx, span := otel.AddSpan(ctx, "business.orgbus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	org := Org{
+		ID:          uuid.New(),
+		Name:        no.Name,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, org); err != nil {
+		return Org{}, fmt.Errorf("create: %w", err)
+	}
+
+	return org, nil
+}
+
+// Query retrieves a list of existing organisations.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Org, error) {
+	ctx, span := otel.AddSpan(ctx, "business.orgbus.query")
+	defer span.End()
+
+	orgs, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// Count returns the total number of organisations.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.orgbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the organisation by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, orgID uuid.UUID) (Org, error) {
+	ctx, span := otel.AddSpan(ctx, "business.orgbus.querybyid")
+	defer span.End()
+
+	org, err := b.storer.QueryByID(ctx, orgID)
+	if err != nil {
+		return Org{}, fmt.Errorf("query: orgID[%s]: %w", orgID, err)
+	}
+
+	return org, nil
+}
@@ -0,0 +1,27 @@
+package orgdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/orgbus"
+)
+
+func (s *Store) applyFilter(filter orgbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["org_id"] = *filter.ID
+		wc = append(wc, "org_id = :org_id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = filter.Name.String()
+		wc = append(wc, "name = :name")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
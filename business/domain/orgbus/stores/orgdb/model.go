@@ -0,0 +1,56 @@
+package orgdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+type org struct {
+	ID          uuid.UUID `db:"org_id"`
+	Name        string    `db:"name"`
+	DateCreated time.Time `db:"date_created"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBOrg(bus orgbus.Org) org {
+	return org{
+		ID:          bus.ID,
+		Name:        bus.Name.String(),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusOrg(db org) (orgbus.Org, error) {
+	nme, err := name.Parse(db.Name)
+	if err != nil {
+		return orgbus.Org{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	bus := orgbus.Org{
+		ID:          db.ID,
+		Name:        nme,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusOrgs(dbs []org) ([]orgbus.Org, error) {
+	bus := make([]orgbus.Org, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusOrg(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,147 @@
+// Package orgdb contains organisation related CRUD functionality.
+package orgdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for organisation database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (orgbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new organisation into the database.
+func (s *Store) Create(ctx context.Context, org orgbus.Org) error {
+	const q = `
+	INSERT INTO orgs
+		(org_id, name, date_created, date_updated)
+	VALUES
+		(:org_id, :name, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOrg(org)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", orgbus.ErrUniqueName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing organisations from the database.
+func (s *Store) Query(ctx context.Context, filter orgbus.QueryFilter, orderBy order.By, page page.Page) ([]orgbus.Org, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		org_id, name, date_created, date_updated
+	FROM
+		orgs`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbOrgs []org
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbOrgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusOrgs(dbOrgs)
+}
+
+// Count returns the total number of organisations in the DB.
+func (s *Store) Count(ctx context.Context, filter orgbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		orgs`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified organisation from the database.
+func (s *Store) QueryByID(ctx context.Context, orgID uuid.UUID) (orgbus.Org, error) {
+	data := struct {
+		ID string `db:"org_id"`
+	}{
+		ID: orgID.String(),
+	}
+
+	const q = `
+	SELECT
+		org_id, name, date_created, date_updated
+	FROM
+		orgs
+	WHERE
+		org_id = :org_id`
+
+	var dbOrg org
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbOrg); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return orgbus.Org{}, fmt.Errorf("db: %w", orgbus.ErrNotFound)
+		}
+		return orgbus.Org{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusOrg(dbOrg)
+}
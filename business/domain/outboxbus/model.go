@@ -0,0 +1,61 @@
+package outboxbus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/google/uuid"
+)
+
+// Event represents a domain event waiting in the outbox to be dispatched.
+// Events of the same aggregate are dispatched in the order they were added.
+type Event struct {
+	ID              uuid.UUID
+	AggregateID     uuid.UUID
+	Data            delegate.Data
+	Attempts        int
+	LastError       string
+	DateCreated     time.Time
+	DateNextAttempt time.Time
+}
+
+// DeadLetter represents an event that was given up on after it failed to be
+// dispatched too many times.
+type DeadLetter struct {
+	Event
+	DateFailed time.Time
+}
+
+// RelayConfig represents the settings used to dispatch outbox events. The
+// backoff between attempts doubles from BaseBackoff up to MaxBackoff. Lease
+// is how long a claimed event is hidden from other relays while it is being
+// dispatched.
+type RelayConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Lease       time.Duration
+}
+
+// DefaultRelayConfig provides reasonable relay settings.
+var DefaultRelayConfig = RelayConfig{
+	Interval:    time.Second,
+	BatchSize:   100,
+	MaxAttempts: 10,
+	BaseBackoff: time.Second,
+	MaxBackoff:  5 * time.Minute,
+	Lease:       time.Minute,
+}
+
+// backoff returns how long to wait before the next attempt after the
+// specified number of failed attempts.
+func (cfg RelayConfig) backoff(attempts int) time.Duration {
+	d := cfg.BaseBackoff
+	for i := 1; i < attempts && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	return min(d, cfg.MaxBackoff)
+}
@@ -0,0 +1,168 @@
+// Package outboxbus provides a transactional outbox for domain events. Events
+// are written in the same transaction as the change that raised them and a
+// relay dispatches them to the delegate once the transaction has committed.
+package outboxbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for outbox operations.
+var (
+	ErrNotFound = errors.New("event not found")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, evt Event) error
+	Claim(ctx context.Context, now time.Time, lockedUntil time.Time, limit int) ([]Event, error)
+	Update(ctx context.Context, evt Event) error
+	Delete(ctx context.Context, evt Event) error
+	Bury(ctx context.Context, dl DeadLetter) error
+	QueryDeadLetterByID(ctx context.Context, eventID uuid.UUID) (DeadLetter, error)
+}
+
+// Business manages the set of APIs for outbox access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an outbox business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Add writes the event to the outbox. When the business value was constructed
+// with a transaction, the event is only dispatched if that transaction
+// commits.
+func (b *Business) Add(ctx context.Context, aggregateID uuid.UUID, data delegate.Data) (Event, error) {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.add")
+	defer span.End()
+
+	now := time.Now()
+
+	evt := Event{
+		ID:              uuid.New(),
+		AggregateID:     aggregateID,
+		Data:            data,
+		DateCreated:     now,
+		DateNextAttempt: now,
+	}
+
+	if err := b.storer.Create(ctx, evt); err != nil {
+		return Event{}, fmt.Errorf("create: %w", err)
+	}
+
+	return evt, nil
+}
+
+// Claim leases a batch of events that are due to be dispatched. Only the
+// oldest pending event of an aggregate is ever claimed so events of the same
+// aggregate are dispatched in order.
+func (b *Business) Claim(ctx context.Context, lease time.Duration, limit int) ([]Event, error) {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.claim")
+	defer span.End()
+
+	now := time.Now()
+
+	evts, err := b.storer.Claim(ctx, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim: %w", err)
+	}
+
+	return evts, nil
+}
+
+// Complete removes an event that was dispatched.
+func (b *Business) Complete(ctx context.Context, evt Event) error {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.complete")
+	defer span.End()
+
+	if err := b.storer.Delete(ctx, evt); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Retry records a failed attempt and schedules the event to be dispatched
+// again after the specified delay.
+func (b *Business) Retry(ctx context.Context, evt Event, cause error, delay time.Duration) error {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.retry")
+	defer span.End()
+
+	evt.Attempts++
+	evt.LastError = cause.Error()
+	evt.DateNextAttempt = time.Now().Add(delay)
+
+	if err := b.storer.Update(ctx, evt); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Bury records a failed attempt and moves the event to the dead letters. The
+// next event of the aggregate becomes eligible for dispatch.
+func (b *Business) Bury(ctx context.Context, evt Event, cause error) error {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.bury")
+	defer span.End()
+
+	evt.Attempts++
+	evt.LastError = cause.Error()
+
+	dl := DeadLetter{
+		Event:      evt,
+		DateFailed: time.Now(),
+	}
+
+	if err := b.storer.Bury(ctx, dl); err != nil {
+		return fmt.Errorf("bury: %w", err)
+	}
+
+	return nil
+}
+
+// QueryDeadLetterByID finds the dead letter for the specified event id.
+func (b *Business) QueryDeadLetterByID(ctx context.Context, eventID uuid.UUID) (DeadLetter, error) {
+	ctx, span := otel.AddSpan(ctx, "business.outboxbus.querydeadletterbyid")
+	defer span.End()
+
+	dl, err := b.storer.QueryDeadLetterByID(ctx, eventID)
+	if err != nil {
+		return DeadLetter{}, fmt.Errorf("query: eventID[%s]: %w", eventID, err)
+	}
+
+	return dl, nil
+}
@@ -0,0 +1,173 @@
+package outboxbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Outbox(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Outbox")
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, relay(db), "relay")
+}
+
+// =============================================================================
+
+func testData(action string) delegate.Data {
+	return delegate.Data{
+		Domain:    "test",
+		Action:    action,
+		RawParams: []byte(`{}`),
+	}
+}
+
+func relay(db *dbtest.Database) []unitest.Table {
+	cfg := outboxbus.DefaultRelayConfig
+	cfg.MaxAttempts = 2
+	cfg.BaseBackoff = 0
+
+	table := []unitest.Table{
+		{
+			Name:    "ordered",
+			ExpResp: []string{"a1", "b1", "a2"},
+			ExcFunc: func(ctx context.Context) any {
+				dlg := delegate.New(db.Log)
+
+				var got []string
+				dlg.Register("test", "ordered", func(ctx context.Context, data delegate.Data) error {
+					got = append(got, string(data.RawParams))
+					return nil
+				})
+
+				aggA := uuid.New()
+				aggB := uuid.New()
+
+				adds := []struct {
+					agg    uuid.UUID
+					params string
+				}{{aggA, "a1"}, {aggA, "a2"}, {aggB, "b1"}}
+
+				for _, add := range adds {
+					data := delegate.Data{Domain: "test", Action: "ordered", RawParams: []byte(add.params)}
+					if _, err := db.BusDomain.Outbox.Add(ctx, add.agg, data); err != nil {
+						return err
+					}
+				}
+
+				rly := outboxbus.NewRelay(db.Log, cfg, db.BusDomain.Outbox, dlg)
+
+				// The second event of aggregate a waits for the first, so it
+				// is only dispatched by the second pass.
+				if _, err := rly.Process(ctx); err != nil {
+					return err
+				}
+
+				slices.Sort(got)
+
+				if _, err := rly.Process(ctx); err != nil {
+					return err
+				}
+
+				return got
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "deadletter",
+			ExpResp: 2,
+			ExcFunc: func(ctx context.Context) any {
+				dlg := delegate.New(db.Log)
+				dlg.Register("test", "failing", func(ctx context.Context, data delegate.Data) error {
+					return errors.New("handler failed")
+				})
+
+				evt, err := db.BusDomain.Outbox.Add(ctx, uuid.New(), testData("failing"))
+				if err != nil {
+					return err
+				}
+
+				rly := outboxbus.NewRelay(db.Log, cfg, db.BusDomain.Outbox, dlg)
+
+				// The first failure is retried and the second exhausts the
+				// attempts.
+				for range cfg.MaxAttempts {
+					if _, err := rly.Process(ctx); err != nil {
+						return err
+					}
+				}
+
+				dl, err := db.BusDomain.Outbox.QueryDeadLetterByID(ctx, evt.ID)
+				if err != nil {
+					return err
+				}
+
+				if dl.LastError != "handler failed" {
+					return fmt.Errorf("last error: %s", dl.LastError)
+				}
+
+				if n, err := rly.Process(ctx); err != nil || n != 0 {
+					return fmt.Errorf("outbox not empty: %d %v", n, err)
+				}
+
+				return dl.Attempts
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "rollback",
+			ExpResp: 0,
+			ExcFunc: func(ctx context.Context) any {
+				tx, err := sqldb.NewBeginner(db.DB).Begin()
+				if err != nil {
+					return err
+				}
+
+				outboxBus, err := db.BusDomain.Outbox.NewWithTx(tx)
+				if err != nil {
+					return err
+				}
+
+				if _, err := outboxBus.Add(ctx, uuid.New(), testData("rollback")); err != nil {
+					return err
+				}
+
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+
+				rly := outboxbus.NewRelay(db.Log, cfg, db.BusDomain.Outbox, delegate.New(db.Log))
+
+				n, err := rly.Process(ctx)
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,93 @@
+package outboxbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Relay dispatches the events in the outbox to the functions registered with
+// the delegate. Events are delivered at least once: a failed event is retried
+// with backoff until it succeeds or runs out of attempts and is moved to the
+// dead letters.
+type Relay struct {
+	log       *logger.Logger
+	cfg       RelayConfig
+	outboxBus *Business
+	delegate  *delegate.Delegate
+}
+
+// NewRelay constructs a relay for the outbox.
+func NewRelay(log *logger.Logger, cfg RelayConfig, outboxBus *Business, delegate *delegate.Delegate) *Relay {
+	return &Relay{
+		log:       log,
+		cfg:       cfg,
+		outboxBus: outboxBus,
+		delegate:  delegate,
+	}
+}
+
+// Run dispatches events on the configured interval until the context is
+// cancelled. A full batch is followed immediately by the next one so a
+// backlog drains without waiting on the interval.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.Process(ctx)
+		if err != nil {
+			r.log.Error(ctx, "outbox relay", "err", err)
+		}
+
+		if n == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Process dispatches one batch of events that are due and returns the number
+// of events that were claimed.
+func (r *Relay) Process(ctx context.Context) (int, error) {
+	evts, err := r.outboxBus.Claim(ctx, r.cfg.Lease, r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, evt := range evts {
+		if err := r.dispatch(ctx, evt); err != nil {
+			return len(evts), fmt.Errorf("dispatch: eventID[%s]: %w", evt.ID, err)
+		}
+	}
+
+	return len(evts), nil
+}
+
+// dispatch delivers a single event and records the outcome. The error
+// returned is about recording the outcome, not the delivery.
+func (r *Relay) dispatch(ctx context.Context, evt Event) error {
+	derr := r.delegate.Dispatch(ctx, evt.Data)
+
+	switch {
+	case derr == nil:
+		return r.outboxBus.Complete(ctx, evt)
+
+	case evt.Attempts+1 >= r.cfg.MaxAttempts:
+		r.log.Error(ctx, "outbox relay", "status", "dead letter", "event_id", evt.ID, "domain", evt.Data.Domain, "action", evt.Data.Action, "attempts", evt.Attempts+1, "err", derr)
+		return r.outboxBus.Bury(ctx, evt, derr)
+
+	default:
+		delay := r.cfg.backoff(evt.Attempts + 1)
+		r.log.Info(ctx, "outbox relay", "status", "retry", "event_id", evt.ID, "domain", evt.Data.Domain, "action", evt.Data.Action, "attempts", evt.Attempts+1, "delay", delay, "err", derr)
+		return r.outboxBus.Retry(ctx, evt, derr, delay)
+	}
+}
@@ -0,0 +1,116 @@
+package outboxdb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/google/uuid"
+)
+
+type event struct {
+	ID              uuid.UUID      `db:"event_id"`
+	AggregateID     uuid.UUID      `db:"aggregate_id"`
+	Domain          string         `db:"domain"`
+	Action          string         `db:"action"`
+	Version         int            `db:"version"`
+	Params          []byte         `db:"params"`
+	Attempts        int            `db:"attempts"`
+	LastError       sql.NullString `db:"last_error"`
+	DateCreated     time.Time      `db:"date_created"`
+	DateNextAttempt time.Time      `db:"date_next_attempt"`
+}
+
+func toDBEvent(bus outboxbus.Event) event {
+	return event{
+		ID:          bus.ID,
+		AggregateID: bus.AggregateID,
+		Domain:      bus.Data.Domain,
+		Action:      bus.Data.Action,
+		Version:     bus.Data.Version,
+		Params:      bus.Data.RawParams,
+		Attempts:    bus.Attempts,
+		LastError: sql.NullString{
+			String: bus.LastError,
+			Valid:  bus.LastError != "",
+		},
+		DateCreated:     bus.DateCreated.UTC(),
+		DateNextAttempt: bus.DateNextAttempt.UTC(),
+	}
+}
+
+func toBusEvent(db event) outboxbus.Event {
+	return outboxbus.Event{
+		ID:          db.ID,
+		AggregateID: db.AggregateID,
+		Data: delegate.Data{
+			Domain:    db.Domain,
+			Action:    db.Action,
+			Version:   db.Version,
+			RawParams: db.Params,
+		},
+		Attempts:        db.Attempts,
+		LastError:       db.LastError.String,
+		DateCreated:     db.DateCreated.In(time.Local),
+		DateNextAttempt: db.DateNextAttempt.In(time.Local),
+	}
+}
+
+func toBusEvents(dbs []event) []outboxbus.Event {
+	evts := make([]outboxbus.Event, len(dbs))
+	for i, db := range dbs {
+		evts[i] = toBusEvent(db)
+	}
+
+	return evts
+}
+
+// =============================================================================
+
+type deadLetter struct {
+	ID          uuid.UUID `db:"event_id"`
+	AggregateID uuid.UUID `db:"aggregate_id"`
+	Domain      string    `db:"domain"`
+	Action      string    `db:"action"`
+	Version     int       `db:"version"`
+	Params      []byte    `db:"params"`
+	Attempts    int       `db:"attempts"`
+	LastError   string    `db:"last_error"`
+	DateCreated time.Time `db:"date_created"`
+	DateFailed  time.Time `db:"date_failed"`
+}
+
+func toDBDeadLetter(bus outboxbus.DeadLetter) deadLetter {
+	return deadLetter{
+		ID:          bus.ID,
+		AggregateID: bus.AggregateID,
+		Domain:      bus.Data.Domain,
+		Action:      bus.Data.Action,
+		Version:     bus.Data.Version,
+		Params:      bus.Data.RawParams,
+		Attempts:    bus.Attempts,
+		LastError:   bus.LastError,
+		DateCreated: bus.DateCreated.UTC(),
+		DateFailed:  bus.DateFailed.UTC(),
+	}
+}
+
+func toBusDeadLetter(db deadLetter) outboxbus.DeadLetter {
+	return outboxbus.DeadLetter{
+		Event: outboxbus.Event{
+			ID:          db.ID,
+			AggregateID: db.AggregateID,
+			Data: delegate.Data{
+				Domain:    db.Domain,
+				Action:    db.Action,
+				Version:   db.Version,
+				RawParams: db.Params,
+			},
+			Attempts:    db.Attempts,
+			LastError:   db.LastError,
+			DateCreated: db.DateCreated.In(time.Local),
+		},
+		DateFailed: db.DateFailed.In(time.Local),
+	}
+}
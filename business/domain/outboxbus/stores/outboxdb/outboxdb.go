@@ -0,0 +1,196 @@
+// Package outboxdb contains outbox related CRUD functionality.
+package outboxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for outbox database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (outboxbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new event into the outbox.
+func (s *Store) Create(ctx context.Context, evt outboxbus.Event) error {
+	const q = `
+	INSERT INTO outbox
+		(event_id, aggregate_id, domain, action, version, params, attempts, last_error, date_created, date_next_attempt)
+	VALUES
+		(:event_id, :aggregate_id, :domain, :action, :version, :params, :attempts, :last_error, :date_created, :date_next_attempt)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBEvent(evt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Claim leases the events that are due and are the oldest event of their
+// aggregate still in the outbox. Rows locked by another relay are skipped.
+func (s *Store) Claim(ctx context.Context, now time.Time, lockedUntil time.Time, limit int) ([]outboxbus.Event, error) {
+	data := map[string]any{
+		"now":          now.UTC(),
+		"locked_until": lockedUntil.UTC(),
+		"limit":        limit,
+	}
+
+	const q = `
+	UPDATE
+		outbox
+	SET
+		date_locked_until = :locked_until
+	WHERE
+		event_id IN (
+			SELECT
+				o.event_id
+			FROM
+				outbox AS o
+			WHERE
+				o.date_next_attempt <= :now AND
+				(o.date_locked_until IS NULL OR o.date_locked_until <= :now) AND
+				NOT EXISTS (
+					SELECT 1 FROM outbox AS p WHERE p.aggregate_id = o.aggregate_id AND p.seq < o.seq
+				)
+			ORDER BY
+				o.seq
+			FETCH NEXT :limit ROWS ONLY
+			FOR UPDATE SKIP LOCKED
+		)
+	RETURNING
+		event_id, aggregate_id, domain, action, version, params, attempts, last_error, date_created, date_next_attempt`
+
+	var dbEvts []event
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbEvts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusEvents(dbEvts), nil
+}
+
+// Update records the outcome of a failed attempt and releases the lease.
+func (s *Store) Update(ctx context.Context, evt outboxbus.Event) error {
+	const q = `
+	UPDATE
+		outbox
+	SET
+		"attempts" = :attempts,
+		"last_error" = :last_error,
+		"date_next_attempt" = :date_next_attempt,
+		"date_locked_until" = NULL
+	WHERE
+		event_id = :event_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBEvent(evt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an event from the outbox.
+func (s *Store) Delete(ctx context.Context, evt outboxbus.Event) error {
+	data := struct {
+		ID string `db:"event_id"`
+	}{
+		ID: evt.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		outbox
+	WHERE
+		event_id = :event_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Bury moves an event from the outbox to the dead letters in a single
+// statement.
+func (s *Store) Bury(ctx context.Context, dl outboxbus.DeadLetter) error {
+	const q = `
+	WITH moved AS (
+		DELETE FROM
+			outbox
+		WHERE
+			event_id = :event_id
+		RETURNING
+			event_id, aggregate_id, domain, action, version, params, date_created
+	)
+	INSERT INTO outbox_dead_letters
+		(event_id, aggregate_id, domain, action, version, params, attempts, last_error, date_created, date_failed)
+	SELECT
+		event_id, aggregate_id, domain, action, version, params, :attempts, :last_error, date_created, :date_failed
+	FROM
+		moved`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBDeadLetter(dl)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryDeadLetterByID gets the specified dead letter from the database.
+func (s *Store) QueryDeadLetterByID(ctx context.Context, eventID uuid.UUID) (outboxbus.DeadLetter, error) {
+	data := struct {
+		ID string `db:"event_id"`
+	}{
+		ID: eventID.String(),
+	}
+
+	const q = `
+	SELECT
+		event_id, aggregate_id, domain, action, version, params, attempts, last_error, date_created, date_failed
+	FROM
+		outbox_dead_letters
+	WHERE
+		event_id = :event_id`
+
+	var dbDL deadLetter
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbDL); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return outboxbus.DeadLetter{}, fmt.Errorf("db: %w", outboxbus.ErrNotFound)
+		}
+		return outboxbus.DeadLetter{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusDeadLetter(dbDL), nil
+}
@@ -0,0 +1,58 @@
+package productbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/google/uuid"
+)
+
+// auditSnapshot is the view of a product kept in the audit history.
+type auditSnapshot struct {
+	UserID      uuid.UUID `json:"userID"`
+	Name        string    `json:"name"`
+	Cost        float64   `json:"cost"`
+	Quantity    int       `json:"quantity"`
+	Archived    bool      `json:"archived,omitempty"`
+	DateUpdated time.Time `json:"dateUpdated"`
+}
+
+func toAuditSnapshot(prd *Product) any {
+	if prd == nil {
+		return nil
+	}
+
+	return auditSnapshot{
+		UserID:      prd.UserID,
+		Name:        prd.Name.String(),
+		Cost:        prd.Cost.Value(),
+		Quantity:    prd.Quantity.Value(),
+		Archived:    prd.Archived(),
+		DateUpdated: prd.DateUpdated,
+	}
+}
+
+// audit records a change to the product in the audit history. Nothing is
+// recorded when the business was constructed without an audit business.
+func (b *Business) audit(ctx context.Context, action string, prd Product, before *Product, after *Product) error {
+	if b.auditBus == nil {
+		return nil
+	}
+
+	na := auditbus.NewAudit{
+		Entity:   DomainName,
+		EntityID: prd.ID,
+		OrgID:    prd.OrgID,
+		Action:   action,
+		Before:   toAuditSnapshot(before),
+		After:    toAuditSnapshot(after),
+	}
+
+	if _, err := b.auditBus.Record(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
@@ -2,35 +2,36 @@ package productbus
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 )
 
+// DomainName represents the name of this domain.
+const DomainName = "product"
+
 // registerDelegateFunctions will register action functions with the delegate
 // system. If the business was constructed for query only, there won't be a
 // delegate provided.
 func (b *Business) registerDelegateFunctions() {
 	if b.delegate != nil {
-		b.delegate.Register(userbus.DomainName, userbus.ActionUpdated, b.actionUserUpdated)
+		delegate.Register(b.delegate, userbus.EventUpdated, b.actionUserUpdated)
 	}
 }
 
-// actionUserUpdated is executed by the user domain indirectly when a user is updated.
-func (b *Business) actionUserUpdated(ctx context.Context, data delegate.Data) error {
-	var params userbus.ActionUpdatedParms
-	err := json.Unmarshal(data.RawParams, &params)
-	if err != nil {
-		return fmt.Errorf("expected an encoded %T: %w", params, err)
-	}
-
+// actionUserUpdated is executed by the user domain indirectly when a user is
+// updated. Disabling a user archives all their products and enabling the user
+// again restores them. Both operations are safe to repeat.
+func (b *Business) actionUserUpdated(ctx context.Context, params userbus.ActionUpdatedParms) error {
 	b.log.Info(ctx, "action-userupdate", "user_id", params.UserID, "enabled", params.Enabled)
 
-	// Now we can see if this user has been disabled. If they have been, we will
-	// want to disable to mark all these products as deleted. Right now we don't
-	// have support for this, but you can see how we can process the event.
+	if params.Enabled == nil {
+		return nil
+	}
+
+	if !*params.Enabled {
+		return b.ArchiveByUserID(ctx, params.UserID)
+	}
 
-	return nil
+	return b.RestoreByUserID(ctx, params.UserID)
 }
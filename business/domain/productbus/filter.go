@@ -7,9 +7,13 @@ import (
 
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
+// Archived products are left out unless Archived is set, in which case the
+// query returns only the archived or only the active products.
 type QueryFilter struct {
 	ID       *uuid.UUID
+	OrgID    *uuid.UUID
 	Name     *name.Name
 	Cost     *float64
 	Quantity *int
+	Archived *bool
 }
@@ -9,15 +9,26 @@ import (
 	"github.com/google/uuid"
 )
 
-// Product represents an individual product.
+// Product represents an individual product. A product is archived when its
+// owner is disabled and DateArchived holds when that happened. The zero value
+// means the product is active. Version is incremented on every change to the
+// product and is used to detect concurrent updates.
 type Product struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	Name        name.Name
-	Cost        money.Money
-	Quantity    quantity.Quantity
-	DateCreated time.Time
-	DateUpdated time.Time
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         name.Name
+	Cost         money.Money
+	Quantity     quantity.Quantity
+	OrgID        uuid.UUID
+	DateCreated  time.Time
+	DateUpdated  time.Time
+	DateArchived time.Time
+	Version      int
+}
+
+// Archived reports whether the product has been archived.
+func (p Product) Archived() bool {
+	return !p.DateArchived.IsZero()
 }
 
 // NewProduct is what we require from clients when adding a Product.
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -19,9 +20,10 @@ import (
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound     = errors.New("product not found")
-	ErrUserDisabled = errors.New("user disabled")
-	ErrInvalidCost  = errors.New("cost not valid")
+	ErrNotFound        = errors.New("product not found")
+	ErrUserDisabled    = errors.New("user disabled")
+	ErrInvalidCost     = errors.New("cost not valid")
+	ErrVersionConflict = errors.New("product changed by another request")
 )
 
 // Storer interface declares the behavior this package needs to persist and
@@ -30,11 +32,15 @@ type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, prd Product) error
 	Update(ctx context.Context, prd Product) error
-	Delete(ctx context.Context, prd Product) error
+	Delete(ctx context.Context, prd Product, now time.Time) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
 	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
+	ArchiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]Product, error)
+	RestoreByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
+	Restore(ctx context.Context, productID uuid.UUID) (Product, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 // Business manages the set of APIs for product access.
@@ -42,15 +48,19 @@ type Business struct {
 	log      *logger.Logger
 	userBus  *userbus.Business
 	delegate *delegate.Delegate
+	auditBus *auditbus.Business
 	storer   Storer
 }
 
-// NewBusiness constructs a product business API for use.
-func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, storer Storer) *Business {
+// NewBusiness constructs a product business API for use. When an audit
+// business is provided, every change to a product is recorded in the audit
+// history.
+func NewBusiness(log *logger.Logger, userBus *userbus.Business, delegate *delegate.Delegate, auditBus *auditbus.Business, storer Storer) *Business {
 	b := Business{
 		log:      log,
 		userBus:  userBus,
 		delegate: delegate,
+		auditBus: auditBus,
 		storer:   storer,
 	}
 
@@ -72,10 +82,19 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		return nil, err
 	}
 
+	auditBus := b.auditBus
+	if auditBus != nil {
+		auditBus, err = auditBus.NewWithTx(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	bus := Business{
 		log:      b.log,
 		userBus:  userBus,
 		delegate: b.delegate,
+		auditBus: auditBus,
 		storer:   storer,
 	}
 
@@ -104,14 +123,20 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 		Cost:        np.Cost,
 		Quantity:    np.Quantity,
 		UserID:      np.UserID,
+		OrgID:       usr.OrgID,
 		DateCreated: now,
 		DateUpdated: now,
+		Version:     1,
 	}
 
 	if err := b.storer.Create(ctx, prd); err != nil {
 		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionCreated, prd, nil, &prd); err != nil {
+		return Product{}, err
+	}
+
 	return prd, nil
 }
 
@@ -120,6 +145,8 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 	ctx, span := otel.AddSpan(ctx, "business.productbus.update")
 	defer span.End()
 
+	before := prd
+
 	if up.Name != nil {
 		prd.Name = *up.Name
 	}
@@ -133,23 +160,33 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 	}
 
 	prd.DateUpdated = time.Now()
+	prd.Version++
 
 	if err := b.storer.Update(ctx, prd); err != nil {
 		return Product{}, fmt.Errorf("update: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionUpdated, prd, &before, &prd); err != nil {
+		return Product{}, err
+	}
+
 	return prd, nil
 }
 
-// Delete removes the specified product.
+// Delete marks the specified product as deleted. The product can be restored
+// until it is purged.
 func (b *Business) Delete(ctx context.Context, prd Product) error {
 	ctx, span := otel.AddSpan(ctx, "business.productbus.delete")
 	defer span.End()
 
-	if err := b.storer.Delete(ctx, prd); err != nil {
+	if err := b.storer.Delete(ctx, prd, time.Now()); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionDeleted, prd, &prd, nil); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -199,3 +236,75 @@ func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Produ
 
 	return prds, nil
 }
+
+// ArchiveByUserID archives all the active products for the specified user.
+// Archived products are left out of queries until they are restored. An
+// audit entry is recorded for each product that is archived.
+func (b *Business) ArchiveByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.archivebyuserid")
+	defer span.End()
+
+	prds, err := b.storer.ArchiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("archive: userID[%s]: %w", userID, err)
+	}
+
+	for _, prd := range prds {
+		if err := b.audit(ctx, auditbus.ActionArchived, prd, nil, &prd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreByUserID makes all the archived products for the specified user
+// active again. An audit entry is recorded for each product that is restored.
+func (b *Business) RestoreByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.restorebyuserid")
+	defer span.End()
+
+	prds, err := b.storer.RestoreByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("restore: userID[%s]: %w", userID, err)
+	}
+
+	for _, prd := range prds {
+		if err := b.audit(ctx, auditbus.ActionUnarchived, prd, nil, &prd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back the specified deleted product.
+func (b *Business) Restore(ctx context.Context, productID uuid.UUID) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.restore")
+	defer span.End()
+
+	prd, err := b.storer.Restore(ctx, productID)
+	if err != nil {
+		return Product{}, fmt.Errorf("restore: productID[%s]: %w", productID, err)
+	}
+
+	if err := b.audit(ctx, auditbus.ActionRestored, prd, nil, &prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// Purge permanently removes the products deleted before the specified
+// time. It returns how many products were removed.
+func (b *Business) Purge(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.purge")
+	defer span.End()
+
+	n, err := b.storer.Purge(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+
+	return n, nil
+}
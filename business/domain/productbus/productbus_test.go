@@ -2,21 +2,26 @@ package productbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/business/sdk/unitest"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
 	"github.com/ardanlabs/service/business/types/role"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func Test_Product(t *testing.T) {
@@ -35,6 +40,9 @@ func Test_Product(t *testing.T) {
 	unitest.Run(t, create(db.BusDomain, sd), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+	unitest.Run(t, restore(db.BusDomain, sd), "restore")
+	unitest.Run(t, archive(db, sd), "archive")
+	unitest.Run(t, tenant(db, sd), "tenant")
 }
 
 // =============================================================================
@@ -176,6 +184,8 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				Name:     name.MustParse("Guitar"),
 				Cost:     money.MustParse(10.34),
 				Quantity: quantity.MustParse(10),
+				OrgID:    sd.Users[0].OrgID,
+				Version:  1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				np := productbus.NewProduct{
@@ -222,8 +232,10 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				Name:        name.MustParse("Guitar"),
 				Cost:        money.MustParse(10.34),
 				Quantity:    quantity.MustParse(10),
+				OrgID:       sd.Users[0].OrgID,
 				DateCreated: sd.Users[0].Products[0].DateCreated,
 				DateUpdated: sd.Users[0].Products[0].DateCreated,
+				Version:     sd.Users[0].Products[0].Version + 1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				up := productbus.UpdateProduct{
@@ -252,6 +264,28 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "stale",
+			ExpResp: productbus.ErrVersionConflict,
+			ExcFunc: func(ctx context.Context) any {
+				up := productbus.UpdateProduct{
+					Name: dbtest.NamePointer("Piano"),
+				}
+
+				// The basic update moved the product past this version.
+				_, err := busDomain.Product.Update(ctx, sd.Users[0].Products[0], up)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, ok := got.(error)
+				if !ok || !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, want %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -291,3 +325,234 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 	return table
 }
+
+// archiveCounts captures the number of active and archived products and the
+// last audit action recorded for the first product.
+type archiveCounts struct {
+	Active   int
+	Archived int
+	Audit    string
+}
+
+func archive(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Users[0].User
+
+	// The user domain raises its events through the outbox, so the relay
+	// has to run before the products see the change.
+	setEnabled := func(ctx context.Context, enabled bool) (archiveCounts, error) {
+		uu := userbus.UpdateUser{
+			Enabled: &enabled,
+		}
+
+		updUsr, err := db.BusDomain.User.Update(ctx, usr, uu)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+		usr = updUsr
+
+		rly := outboxbus.NewRelay(db.Log, outboxbus.DefaultRelayConfig, db.BusDomain.Outbox, db.BusDomain.Delegate)
+		if _, err := rly.Process(ctx); err != nil {
+			return archiveCounts{}, err
+		}
+
+		active, err := db.BusDomain.Product.QueryByUserID(ctx, usr.ID)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		archived := true
+		filter := productbus.QueryFilter{
+			Archived: &archived,
+		}
+
+		count, err := db.BusDomain.Product.Count(ctx, filter)
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		entityID := sd.Users[0].Products[0].ID
+		auditFilter := auditbus.QueryFilter{
+			EntityID: &entityID,
+		}
+
+		adts, err := db.BusDomain.Audit.Query(ctx, auditFilter, auditbus.DefaultOrderBy, page.MustParse("1", "1"))
+		if err != nil {
+			return archiveCounts{}, err
+		}
+
+		if len(adts) == 0 {
+			return archiveCounts{}, errors.New("expected an audit entry")
+		}
+
+		return archiveCounts{Active: len(active), Archived: count, Audit: adts[0].Action}, nil
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "disable",
+			ExpResp: archiveCounts{Active: 0, Archived: 2, Audit: auditbus.ActionArchived},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := setEnabled(ctx, false)
+				if err != nil {
+					return err
+				}
+
+				prd, err := db.BusDomain.Product.QueryByID(ctx, sd.Users[0].Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				if !prd.Archived() {
+					return fmt.Errorf("expected product to be archived")
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "enable",
+			ExpResp: archiveCounts{Active: 2, Archived: 0, Audit: auditbus.ActionUnarchived},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := setEnabled(ctx, true)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func restore(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "deleted",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Product.QueryByID(ctx, sd.Users[0].Products[1].ID)
+				return errors.Is(err, productbus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore",
+			ExpResp: sd.Users[0].Products[1].ID,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Product.Restore(ctx, sd.Users[0].Products[1].ID); err != nil {
+					return err
+				}
+
+				prd, err := busDomain.Product.QueryByID(ctx, sd.Users[0].Products[1].ID)
+				if err != nil {
+					return err
+				}
+
+				return prd.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore-active",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Product.Restore(ctx, sd.Users[0].Products[1].ID)
+				return errors.Is(err, productbus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "purge",
+			ExpResp: 2,
+			ExcFunc: func(ctx context.Context) any {
+				prd, err := busDomain.Product.QueryByID(ctx, sd.Users[0].Products[1].ID)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Product.Delete(ctx, prd); err != nil {
+					return err
+				}
+
+				// The admin's product was deleted by the delete tests as well.
+				n, err := busDomain.Product.Purge(ctx, time.Now())
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func tenant(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	// queryByID looks up a product in a transaction scoped to the tenant so
+	// only row level security stands between the caller and the row.
+	queryByID := func(ctx context.Context, tenant uuid.UUID, productID uuid.UUID) error {
+		tx, err := sqldb.NewBeginner(db.DB).Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := sqldb.SetTenant(ctx, db.Log, tx, tenant); err != nil {
+			return err
+		}
+
+		productBus, err := db.BusDomain.Product.NewWithTx(tx)
+		if err != nil {
+			return err
+		}
+
+		_, err = productBus.QueryByID(ctx, productID)
+		return err
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "same",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				prd := sd.Users[0].Products[0]
+				return queryByID(ctx, prd.OrgID, prd.ID) == nil
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "other",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				prd := sd.Users[0].Products[0]
+				err := queryByID(ctx, uuid.New(), prd.ID)
+				return errors.Is(err, productbus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
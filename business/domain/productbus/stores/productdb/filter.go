@@ -16,6 +16,11 @@ func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any,
 		wc = append(wc, "product_id = :product_id")
 	}
 
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
 	if filter.Name != nil {
 		data["name"] = fmt.Sprintf("%%%s%%", *filter.Name)
 		wc = append(wc, "name LIKE :name")
@@ -31,6 +36,15 @@ func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any,
 		wc = append(wc, "quantity = :quantity")
 	}
 
+	switch {
+	case filter.Archived != nil && *filter.Archived:
+		wc = append(wc, "date_archived IS NOT NULL")
+	default:
+		wc = append(wc, "date_archived IS NULL")
+	}
+
+	wc = append(wc, "date_deleted IS NULL")
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
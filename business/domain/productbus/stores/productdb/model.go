@@ -1,6 +1,7 @@
 package productdb
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -12,13 +13,16 @@ import (
 )
 
 type product struct {
-	ID          uuid.UUID `db:"product_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Name        string    `db:"name"`
-	Cost        float64   `db:"cost"`
-	Quantity    int       `db:"quantity"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID           uuid.UUID    `db:"product_id"`
+	UserID       uuid.UUID    `db:"user_id"`
+	Name         string       `db:"name"`
+	Cost         float64      `db:"cost"`
+	Quantity     int          `db:"quantity"`
+	OrgID        uuid.UUID    `db:"org_id"`
+	DateCreated  time.Time    `db:"date_created"`
+	DateUpdated  time.Time    `db:"date_updated"`
+	DateArchived sql.NullTime `db:"date_archived"`
+	Version      int          `db:"version"`
 }
 
 func toDBProduct(bus productbus.Product) product {
@@ -28,8 +32,14 @@ func toDBProduct(bus productbus.Product) product {
 		Name:        bus.Name.String(),
 		Cost:        bus.Cost.Value(),
 		Quantity:    bus.Quantity.Value(),
+		OrgID:       bus.OrgID,
 		DateCreated: bus.DateCreated.UTC(),
 		DateUpdated: bus.DateUpdated.UTC(),
+		DateArchived: sql.NullTime{
+			Time:  bus.DateArchived.UTC(),
+			Valid: !bus.DateArchived.IsZero(),
+		},
+		Version: bus.Version,
 	}
 
 	return db
@@ -57,8 +67,14 @@ func toBusProduct(db product) (productbus.Product, error) {
 		Name:        name,
 		Cost:        cost,
 		Quantity:    quantity,
+		OrgID:       db.OrgID,
 		DateCreated: db.DateCreated.In(time.Local),
 		DateUpdated: db.DateUpdated.In(time.Local),
+		Version:     db.Version,
+	}
+
+	if db.DateArchived.Valid {
+		bus.DateArchived = db.DateArchived.Time.In(time.Local)
 	}
 
 	return bus, nil
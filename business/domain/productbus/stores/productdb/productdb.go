@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -51,9 +52,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error)
 func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
 	const q = `
 	INSERT INTO products
-		(product_id, user_id, name, cost, quantity, date_created, date_updated)
+		(product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, version)
 	VALUES
-		(:product_id, :user_id, :name, :cost, :quantity, :date_created, :date_updated)`
+		(:product_id, :user_id, :name, :cost, :quantity, :org_id, :date_created, :date_updated, :version)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -62,8 +63,9 @@ func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
 	return nil
 }
 
-// Update modifies data about a productbus. It will error if the specified ID is
-// invalid or does not reference an existing productbus.
+// Update modifies data about a productbus. The product carries the version
+// it is moving to, so the row is only changed when it is still at the version
+// before that. It will error if the row was changed or deleted in the meantime.
 func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 	const q = `
 	UPDATE
@@ -72,33 +74,59 @@ func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 		"name" = :name,
 		"cost" = :cost,
 		"quantity" = :quantity,
-		"date_updated" = :date_updated
+		"date_updated" = :date_updated,
+		"version" = :version
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		version = :version - 1 AND
+		date_deleted IS NULL
+	RETURNING
+		product_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBProduct(prd), &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", productbus.ErrVersionConflict)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes the product identified by a given ID.
-func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+// Delete marks the product identified by a given ID as deleted. The row is
+// kept until it is purged so the product can be restored. It will error if
+// the row is no longer at the version of the specified product.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product, now time.Time) error {
 	data := struct {
-		ID string `db:"product_id"`
+		ID          string    `db:"product_id"`
+		Version     int       `db:"version"`
+		DateDeleted time.Time `db:"date_deleted"`
 	}{
-		ID: prd.ID.String(),
+		ID:          prd.ID.String(),
+		Version:     prd.Version,
+		DateDeleted: now.UTC(),
 	}
 
 	const q = `
-	DELETE FROM
+	UPDATE
 		products
+	SET
+		"date_deleted" = :date_deleted,
+		"version" = version + 1
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		version = :version AND
+		date_deleted IS NULL
+	RETURNING
+		product_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", productbus.ErrVersionConflict)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -113,7 +141,7 @@ func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderB
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version
 	FROM
 		products`
 
@@ -161,7 +189,8 @@ func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int,
 	return count.Count, nil
 }
 
-// QueryByID finds the product identified by a given ID.
+// QueryByID finds the product identified by a given ID. Archived products
+// are returned as well so callers can see their state.
 func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
 	data := struct {
 		ID string `db:"product_id"`
@@ -171,11 +200,12 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version
 	FROM
 		products
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		date_deleted IS NULL`
 
 	var dbPrd product
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
@@ -188,7 +218,7 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.
 	return toBusProduct(dbPrd)
 }
 
-// QueryByUserID finds the product identified by a given User ID.
+// QueryByUserID finds the active products identified by a given User ID.
 func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
 	data := struct {
 		ID string `db:"user_id"`
@@ -198,11 +228,13 @@ func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productb
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version
 	FROM
 		products
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		date_archived IS NULL AND
+		date_deleted IS NULL`
 
 	var dbPrds []product
 	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
@@ -211,3 +243,127 @@ func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productb
 
 	return toBusProducts(dbPrds)
 }
+
+// ArchiveByUserID marks all the active products for the specified user as
+// archived.
+func (s *Store) ArchiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]productbus.Product, error) {
+	data := struct {
+		UserID       string    `db:"user_id"`
+		DateArchived time.Time `db:"date_archived"`
+	}{
+		UserID:       userID.String(),
+		DateArchived: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"date_archived" = :date_archived,
+		"version" = version + 1
+	WHERE
+		user_id = :user_id AND
+		date_archived IS NULL
+	RETURNING
+		product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// RestoreByUserID makes all the archived products for the specified user
+// active again.
+func (s *Store) RestoreByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"date_archived" = NULL,
+		"version" = version + 1
+	WHERE
+		user_id = :user_id AND
+		date_archived IS NOT NULL
+	RETURNING
+		product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// Restore clears the deleted mark from the specified product and returns it.
+func (s *Store) Restore(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	data := struct {
+		ID string `db:"product_id"`
+	}{
+		ID: productID.String(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"date_deleted" = NULL,
+		"version" = version + 1
+	WHERE
+		product_id = :product_id AND
+		date_deleted IS NOT NULL
+	RETURNING
+		product_id, user_id, name, cost, quantity, org_id, date_created, date_updated, date_archived, version`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusProduct(dbPrd)
+}
+
+// Purge permanently removes the products deleted before the specified time
+// and returns how many were removed.
+func (s *Store) Purge(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH purged AS (
+		DELETE FROM
+			products
+		WHERE
+			date_deleted < :before
+		RETURNING
+			product_id
+	)
+	SELECT
+		count(1)
+	FROM
+		purged`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
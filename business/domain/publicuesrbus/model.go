@@ -11,15 +11,17 @@ import (
 
 // User represents information about an individual user.
 type PublicUser struct {
-	ID           uuid.UUID
-	Name         name.Name
-	Email        mail.Address
-	Roles        []role.Role
-	PasswordHash []byte
-	Department   name.Null
-	Enabled      bool
-	DateCreated  time.Time
-	DateUpdated  time.Time
+	ID            uuid.UUID
+	OrgID         uuid.UUID
+	Name          name.Name
+	Email         mail.Address
+	Roles         []role.Role
+	PasswordHash  []byte
+	Department    name.Null
+	Enabled       bool
+	EmailVerified bool
+	DateCreated   time.Time
+	DateUpdated   time.Time
 }
 
 // NewUser contains information needed to create a new user.
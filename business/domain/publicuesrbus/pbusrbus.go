@@ -7,7 +7,9 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/orgbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
 	"github.com/google/uuid"
@@ -19,11 +21,14 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrUserDisabled          = errors.New("user disabled")
+	ErrEmailNotVerified      = errors.New("email not verified")
 )
 
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, usr PublicUser) error
 	Update(ctx context.Context, usr PublicUser) error
 	Delete(ctx context.Context, usr PublicUser) error
@@ -46,7 +51,25 @@ func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer)
 	}
 }
 
-// Create adds a new user to the system.
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:      b.log,
+		delegate: b.delegate,
+		storer:   storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new user to the system. The user can't log in until their
+// email address has been verified.
 func (b *Business) Create(ctx context.Context, nu NewPublicUser) (PublicUser, error) {
 	ctx, span := otel.AddSpan(ctx, "business.publicnewuser.create")
 	defer span.End()
@@ -59,15 +82,17 @@ func (b *Business) Create(ctx context.Context, nu NewPublicUser) (PublicUser, er
 	now := time.Now()
 
 	usr := PublicUser{
-		ID:           uuid.New(),
-		Name:         nu.Name,
-		Email:        nu.Email,
-		PasswordHash: hash,
-		Roles:        nu.Roles,
-		Department:   nu.Department,
-		Enabled:      true,
-		DateCreated:  now,
-		DateUpdated:  now,
+		ID:            uuid.New(),
+		OrgID:         orgbus.DefaultID,
+		Name:          nu.Name,
+		Email:         nu.Email,
+		PasswordHash:  hash,
+		Roles:         nu.Roles,
+		Department:    nu.Department,
+		Enabled:       true,
+		EmailVerified: false,
+		DateCreated:   now,
+		DateUpdated:   now,
 	}
 
 	if err := b.storer.Create(ctx, usr); err != nil {
@@ -106,5 +131,13 @@ func (b *Business) Authenticate(ctx context.Context, email mail.Address, passwor
 		return PublicUser{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
 	}
 
+	if !usr.Enabled {
+		return PublicUser{}, ErrUserDisabled
+	}
+
+	if !usr.EmailVerified {
+		return PublicUser{}, ErrEmailNotVerified
+	}
+
 	return usr, nil
 }
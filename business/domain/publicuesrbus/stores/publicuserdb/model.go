@@ -14,20 +14,23 @@ import (
 )
 
 type publicUser struct {
-	ID           uuid.UUID      `db:"user_id"`
-	Name         string         `db:"name"`
-	Email        string         `db:"email"`
-	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
-	Department   sql.NullString `db:"department"`
-	Enabled      bool           `db:"enabled"`
-	DateCreated  time.Time      `db:"date_created"`
-	DateUpdated  time.Time      `db:"date_updated"`
+	ID            uuid.UUID      `db:"user_id"`
+	OrgID         uuid.UUID      `db:"org_id"`
+	Name          string         `db:"name"`
+	Email         string         `db:"email"`
+	Roles         dbarray.String `db:"roles"`
+	PasswordHash  []byte         `db:"password_hash"`
+	Department    sql.NullString `db:"department"`
+	Enabled       bool           `db:"enabled"`
+	EmailVerified bool           `db:"email_verified"`
+	DateCreated   time.Time      `db:"date_created"`
+	DateUpdated   time.Time      `db:"date_updated"`
 }
 
 func toDBUser(bus publicuesrbus.PublicUser) publicUser {
 	return publicUser{
 		ID:           bus.ID,
+		OrgID:        bus.OrgID,
 		Name:         bus.Name.String(),
 		Email:        bus.Email.Address,
 		Roles:        role.ParseToString(bus.Roles),
@@ -36,9 +39,10 @@ func toDBUser(bus publicuesrbus.PublicUser) publicUser {
 			String: bus.Department.String(),
 			Valid:  bus.Department.Valid(),
 		},
-		Enabled:     bus.Enabled,
-		DateCreated: bus.DateCreated.UTC(),
-		DateUpdated: bus.DateUpdated.UTC(),
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		DateCreated:   bus.DateCreated.UTC(),
+		DateUpdated:   bus.DateUpdated.UTC(),
 	}
 }
 
@@ -63,15 +67,17 @@ func toBusUser(db publicUser) (publicuesrbus.PublicUser, error) {
 	}
 
 	bus := publicuesrbus.PublicUser{
-		ID:           db.ID,
-		Name:         nme,
-		Email:        addr,
-		Roles:        roles,
-		PasswordHash: db.PasswordHash,
-		Enabled:      db.Enabled,
-		Department:   department,
-		DateCreated:  db.DateCreated.In(time.Local),
-		DateUpdated:  db.DateUpdated.In(time.Local),
+		ID:            db.ID,
+		OrgID:         db.OrgID,
+		Name:          nme,
+		Email:         addr,
+		Roles:         roles,
+		PasswordHash:  db.PasswordHash,
+		Enabled:       db.Enabled,
+		EmailVerified: db.EmailVerified,
+		Department:    department,
+		DateCreated:   db.DateCreated.In(time.Local),
+		DateUpdated:   db.DateUpdated.In(time.Local),
 	}
 
 	return bus, nil
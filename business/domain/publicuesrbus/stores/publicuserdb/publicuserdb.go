@@ -29,13 +29,29 @@ func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
 	}
 }
 
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (publicuesrbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
 // Create inserts a new user into the database.
 func (s *Store) Create(ctx context.Context, usr publicuesrbus.PublicUser) error {
 	const q = `
 	INSERT INTO users
-		(user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated)
+		(user_id, org_id, name, email, password_hash, roles, department, enabled, email_verified, date_created, date_updated)
 	VALUES
-		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :date_created, :date_updated)`
+		(:user_id, :org_id, :name, :email, :password_hash, :roles, :department, :enabled, :email_verified, :date_created, :date_updated)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
@@ -59,6 +75,7 @@ func (s *Store) Update(ctx context.Context, usr publicuesrbus.PublicUser) error
 		"password_hash" = :password_hash,
 		"department" = :department,
 		"enabled" = :enabled,
+		"email_verified" = :email_verified,
 		"date_updated" = :date_updated
 	WHERE
 		user_id = :user_id`
@@ -98,11 +115,12 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (publicuesrbus.
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, org_id, name, email, password_hash, roles, department, enabled, email_verified, date_created, date_updated
 	FROM
 		users
-	WHERE 
-		user_id = :user_id`
+	WHERE
+		user_id = :user_id AND
+		date_deleted IS NULL`
 
 	var dbUsr publicUser
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
@@ -125,11 +143,12 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (publicues
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, org_id, name, email, password_hash, roles, department, enabled, email_verified, date_created, date_updated
 	FROM
 		users
 	WHERE
-		email = :email`
+		email = :email AND
+		date_deleted IS NULL`
 
 	var dbUsr publicUser
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/business/domain/publicuesrbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/viccon/sturdyc"
 )
@@ -16,6 +17,7 @@ type Store struct {
 	log    *logger.Logger
 	storer publicuesrbus.Storer
 	cache  *sturdyc.Client[publicuesrbus.PublicUser]
+	inTx   bool
 }
 
 // NewStore constructs the api for data and caching access.
@@ -31,6 +33,24 @@ func NewStore(log *logger.Logger, storer publicuesrbus.Storer, ttl time.Duration
 	}
 }
 
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (publicuesrbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:    s.log,
+		storer: storer,
+		cache:  s.cache,
+		inTx:   true,
+	}
+
+	return &store, nil
+}
+
 // Create inserts a new user into the database.
 func (s *Store) Create(ctx context.Context, usr publicuesrbus.PublicUser) error {
 	if err := s.storer.Create(ctx, usr); err != nil {
@@ -66,6 +86,10 @@ func (s *Store) Delete(ctx context.Context, usr publicuesrbus.PublicUser) error
 
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (publicuesrbus.PublicUser, error) {
+	if s.inTx {
+		return s.storer.QueryByEmail(ctx, email)
+	}
+
 	cachedUsr, ok := s.readCache(email.Address)
 	if ok {
 		return cachedUsr, nil
@@ -92,7 +116,13 @@ func (s *Store) readCache(key string) (publicuesrbus.PublicUser, bool) {
 }
 
 // writeCache performs a safe write to the cache for the specified userbus.
+// Inside a transaction the user is evicted instead.
 func (s *Store) writeCache(bus publicuesrbus.PublicUser) {
+	if s.inTx {
+		s.deleteCache(bus)
+		return
+	}
+
 	s.cache.Set(bus.ID.String(), bus)
 	s.cache.Set(bus.Email.Address, bus)
 }
@@ -0,0 +1,8 @@
+package rolebus
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	Name       *string
+	Permission *string
+}
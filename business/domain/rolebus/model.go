@@ -0,0 +1,39 @@
+package rolebus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/types/permission"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+// Role represents a named set of permissions. The built in roles match the
+// roles a user is given directly and custom roles are assigned to users.
+type Role struct {
+	Name        string
+	Description string
+	Permissions []permission.Permission
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// BuiltIn reports if this is one of the roles known to the role package.
+// Built in roles can't be deleted or assigned, but their permissions can
+// be changed.
+func (r Role) BuiltIn() bool {
+	_, err := role.Parse(r.Name)
+	return err == nil
+}
+
+// NewRole is what we require from clients when adding a Role.
+type NewRole struct {
+	Name        string
+	Description string
+	Permissions []permission.Permission
+}
+
+// UpdateRole contains information needed to update a role.
+type UpdateRole struct {
+	Description *string
+	Permissions []permission.Permission
+}
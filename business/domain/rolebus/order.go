@@ -0,0 +1,12 @@
+package rolebus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByName, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByName        = "name"
+	OrderByDateCreated = "date_created"
+)
@@ -0,0 +1,245 @@
+// Package rolebus provides business access to roles and the permissions
+// they grant.
+package rolebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("role not found")
+	ErrUniqueName = errors.New("role name is not unique")
+	ErrBuiltIn    = errors.New("built in roles can't be deleted or assigned")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, rle Role) error
+	Update(ctx context.Context, rle Role) error
+	Delete(ctx context.Context, rle Role) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Role, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByName(ctx context.Context, name string) (Role, error)
+	QueryByNames(ctx context.Context, names []string) ([]Role, error)
+	Assign(ctx context.Context, userID uuid.UUID, rle Role, now time.Time) error
+	Unassign(ctx context.Context, userID uuid.UUID, rle Role) error
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Role, error)
+}
+
+// Business manages the set of APIs for role access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a role business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new custom role to the system.
+func (b *Business) Create(ctx context.Context, nr NewRole) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	rle := Role{
+		Name:        nr.Name,
+		Description: nr.Description,
+		Permissions: nr.Permissions,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, rle); err != nil {
+		return Role{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rle, nil
+}
+
+// Update modifies information about a role. The new permissions take effect
+// as tokens are issued.
+func (b *Business) Update(ctx context.Context, rle Role, ur UpdateRole) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.update")
+	defer span.End()
+
+	if ur.Description != nil {
+		rle.Description = *ur.Description
+	}
+
+	if ur.Permissions != nil {
+		rle.Permissions = ur.Permissions
+	}
+
+	rle.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, rle); err != nil {
+		return Role{}, fmt.Errorf("update: %w", err)
+	}
+
+	return rle, nil
+}
+
+// Delete removes the specified custom role and every assignment of it.
+func (b *Business) Delete(ctx context.Context, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.delete")
+	defer span.End()
+
+	if rle.BuiltIn() {
+		return ErrBuiltIn
+	}
+
+	if err := b.storer.Delete(ctx, rle); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing roles.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.query")
+	defer span.End()
+
+	rles, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return rles, nil
+}
+
+// Count returns the total number of roles.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByName finds the role by the specified name.
+func (b *Business) QueryByName(ctx context.Context, name string) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.querybyname")
+	defer span.End()
+
+	rle, err := b.storer.QueryByName(ctx, name)
+	if err != nil {
+		return Role{}, fmt.Errorf("query: name[%s]: %w", name, err)
+	}
+
+	return rle, nil
+}
+
+// Assign gives the user the permissions of the custom role. Assigning a role
+// the user already has is not an error.
+func (b *Business) Assign(ctx context.Context, userID uuid.UUID, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.assign")
+	defer span.End()
+
+	if rle.BuiltIn() {
+		return ErrBuiltIn
+	}
+
+	if err := b.storer.Assign(ctx, userID, rle, time.Now()); err != nil {
+		return fmt.Errorf("assign: %w", err)
+	}
+
+	return nil
+}
+
+// Unassign removes the custom role from the user.
+func (b *Business) Unassign(ctx context.Context, userID uuid.UUID, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.unassign")
+	defer span.End()
+
+	if err := b.storer.Unassign(ctx, userID, rle); err != nil {
+		return fmt.Errorf("unassign: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID retrieves the custom roles assigned to the user.
+func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.querybyuserid")
+	defer span.End()
+
+	rles, err := b.storer.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return rles, nil
+}
+
+// Permissions returns the sorted set of permissions granted by the named
+// roles and, unless the user id is uuid.Nil, the custom roles assigned to
+// the user.
+func (b *Business) Permissions(ctx context.Context, userID uuid.UUID, names []string) ([]string, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.permissions")
+	defer span.End()
+
+	var rles []Role
+
+	if len(names) > 0 {
+		named, err := b.storer.QueryByNames(ctx, names)
+		if err != nil {
+			return nil, fmt.Errorf("querybynames: %w", err)
+		}
+		rles = append(rles, named...)
+	}
+
+	if userID != uuid.Nil {
+		assigned, err := b.storer.QueryByUserID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("querybyuserid: %w", err)
+		}
+		rles = append(rles, assigned...)
+	}
+
+	var perms []string
+	for _, rle := range rles {
+		for _, p := range rle.Permissions {
+			perms = append(perms, p.String())
+		}
+	}
+
+	slices.Sort(perms)
+
+	return slices.Compact(perms), nil
+}
@@ -0,0 +1,160 @@
+package rolebus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/permission"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Role(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Role")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, builtIn(db.BusDomain), "builtin")
+	unitest.Run(t, custom(db.BusDomain, sd), "custom")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func builtIn(busDomain dbtest.BusDomain) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "delete",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				rle, err := busDomain.Role.QueryByName(ctx, role.Admin.String())
+				if err != nil {
+					return err
+				}
+
+				return rle.BuiltIn() && errors.Is(busDomain.Role.Delete(ctx, rle), rolebus.ErrBuiltIn)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "permissions",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				perms, err := busDomain.Role.Permissions(ctx, uuid.Nil, []string{role.User.String()})
+				if err != nil {
+					return err
+				}
+
+				return slices.Contains(perms, "product:write") && !slices.Contains(perms, "role:*")
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func custom(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "assign",
+			ExpResp: []string{"home:read", "home:write", "product:read", "product:write", "report:read", "user:read", "user:update"},
+			ExcFunc: func(ctx context.Context) any {
+				nr := rolebus.NewRole{
+					Name:        "AUDITOR",
+					Description: "Reads reports",
+					Permissions: []permission.Permission{permission.MustParse("report:read"), permission.MustParse("product:read")},
+				}
+
+				rle, err := busDomain.Role.Create(ctx, nr)
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Role.Create(ctx, nr); !errors.Is(err, rolebus.ErrUniqueName) {
+					return fmt.Errorf("duplicate role: %v", err)
+				}
+
+				userID := sd.Users[0].ID
+
+				// Assigning twice is not an error.
+				for range 2 {
+					if err := busDomain.Role.Assign(ctx, userID, rle); err != nil {
+						return err
+					}
+				}
+
+				perms, err := busDomain.Role.Permissions(ctx, userID, []string{role.User.String()})
+				if err != nil {
+					return err
+				}
+
+				return perms
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "delete",
+			ExpResp: 0,
+			ExcFunc: func(ctx context.Context) any {
+				rle, err := busDomain.Role.QueryByName(ctx, "AUDITOR")
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Role.Delete(ctx, rle); err != nil {
+					return err
+				}
+
+				rles, err := busDomain.Role.QueryByUserID(ctx, sd.Users[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return len(rles)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
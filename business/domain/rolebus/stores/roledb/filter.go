@@ -0,0 +1,27 @@
+package roledb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/rolebus"
+)
+
+func (s *Store) applyFilter(filter rolebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.Name != nil {
+		data["name"] = *filter.Name
+		wc = append(wc, "name = :name")
+	}
+
+	if filter.Permission != nil {
+		data["permission"] = *filter.Permission
+		wc = append(wc, ":permission = ANY(permissions)")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
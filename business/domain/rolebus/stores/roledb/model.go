@@ -0,0 +1,68 @@
+package roledb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/ardanlabs/service/business/types/permission"
+	"github.com/google/uuid"
+)
+
+type role struct {
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Permissions dbarray.String `db:"permissions"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+}
+
+func toDBRole(bus rolebus.Role) role {
+	return role{
+		Name:        bus.Name,
+		Description: bus.Description,
+		Permissions: permission.ParseToString(bus.Permissions),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusRole(db role) (rolebus.Role, error) {
+	perms, err := permission.ParseMany(db.Permissions)
+	if err != nil {
+		return rolebus.Role{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := rolebus.Role{
+		Name:        db.Name,
+		Description: db.Description,
+		Permissions: perms,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusRoles(dbs []role) ([]rolebus.Role, error) {
+	bus := make([]rolebus.Role, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusRole(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type userRole struct {
+	UserID      uuid.UUID `db:"user_id"`
+	RoleName    string    `db:"role_name"`
+	DateCreated time.Time `db:"date_created"`
+}
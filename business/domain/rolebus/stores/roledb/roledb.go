@@ -0,0 +1,277 @@
+// Package roledb contains role related CRUD functionality.
+package roledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for role database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (rolebus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new role into the database.
+func (s *Store) Create(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	INSERT INTO roles
+		(name, description, permissions, date_created, date_updated)
+	VALUES
+		(:name, :description, :permissions, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRole(rle)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", rolebus.ErrUniqueName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a role document in the database.
+func (s *Store) Update(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	UPDATE
+		roles
+	SET
+		"description" = :description,
+		"permissions" = :permissions,
+		"date_updated" = :date_updated
+	WHERE
+		name = :name`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRole(rle)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a role from the database.
+func (s *Store) Delete(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	DELETE FROM
+		roles
+	WHERE
+		name = :name`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRole(rle)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing roles from the database.
+func (s *Store) Query(ctx context.Context, filter rolebus.QueryFilter, orderBy order.By, page page.Page) ([]rolebus.Role, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		name, description, permissions, date_created, date_updated
+	FROM
+		roles`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbRles []role
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbRles); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusRoles(dbRles)
+}
+
+// Count returns the total number of roles in the DB.
+func (s *Store) Count(ctx context.Context, filter rolebus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		roles`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByName gets the specified role from the database.
+func (s *Store) QueryByName(ctx context.Context, name string) (rolebus.Role, error) {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: name,
+	}
+
+	const q = `
+	SELECT
+		name, description, permissions, date_created, date_updated
+	FROM
+		roles
+	WHERE
+		name = :name`
+
+	var dbRle role
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRle); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return rolebus.Role{}, fmt.Errorf("db: %w", rolebus.ErrNotFound)
+		}
+		return rolebus.Role{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRole(dbRle)
+}
+
+// QueryByNames gets the specified roles from the database. Names that don't
+// match a role are ignored.
+func (s *Store) QueryByNames(ctx context.Context, names []string) ([]rolebus.Role, error) {
+	data := struct {
+		Names []string `db:"names"`
+	}{
+		Names: names,
+	}
+
+	const q = `
+	SELECT
+		name, description, permissions, date_created, date_updated
+	FROM
+		roles
+	WHERE
+		name IN (:names)`
+
+	var dbRles []role
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbRles); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRoles(dbRles)
+}
+
+// Assign records the role against the user in the database.
+func (s *Store) Assign(ctx context.Context, userID uuid.UUID, rle rolebus.Role, now time.Time) error {
+	const q = `
+	INSERT INTO user_roles
+		(user_id, role_name, date_created)
+	VALUES
+		(:user_id, :role_name, :date_created)
+	ON CONFLICT (user_id, role_name) DO NOTHING`
+
+	ur := userRole{
+		UserID:      userID,
+		RoleName:    rle.Name,
+		DateCreated: now.UTC(),
+	}
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, ur); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Unassign removes the role from the user in the database.
+func (s *Store) Unassign(ctx context.Context, userID uuid.UUID, rle rolebus.Role) error {
+	const q = `
+	DELETE FROM
+		user_roles
+	WHERE
+		user_id = :user_id AND role_name = :role_name`
+
+	ur := userRole{
+		UserID:   userID,
+		RoleName: rle.Name,
+	}
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, ur); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByUserID gets the roles assigned to the user from the database.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]rolebus.Role, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		r.name, r.description, r.permissions, r.date_created, r.date_updated
+	FROM
+		user_roles ur
+	JOIN
+		roles r ON r.name = ur.role_name
+	WHERE
+		ur.user_id = :user_id
+	ORDER BY
+		r.name`
+
+	var dbRles []role
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRles); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRoles(dbRles)
+}
@@ -0,0 +1,15 @@
+package sessionbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	ID       *uuid.UUID
+	UserID   *uuid.UUID
+	ActiveAt *time.Time
+}
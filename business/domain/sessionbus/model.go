@@ -0,0 +1,50 @@
+package sessionbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents a login on a device. A session starts when a token is
+// issued and, for logins that hand out a refresh token, lives as long as the
+// refresh token family. TokenID is the id (jti) of the latest access token
+// issued for the session. FamilyID is uuid.Nil when no refresh token was
+// issued.
+type Session struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	TokenID      string
+	FamilyID     uuid.UUID
+	Client       Client
+	DateCreated  time.Time
+	DateLastSeen time.Time
+	DateExpires  time.Time
+	DateRevoked  time.Time
+}
+
+// Revoked reports if the session has been ended.
+func (s Session) Revoked() bool {
+	return !s.DateRevoked.IsZero()
+}
+
+// Active reports if the session can still be used as of the specified time.
+func (s Session) Active(now time.Time) bool {
+	return !s.Revoked() && now.Before(s.DateExpires)
+}
+
+// Client describes the device a session was started from.
+type Client struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+// NewSession contains information needed to start a new session.
+type NewSession struct {
+	UserID      uuid.UUID
+	TokenID     string
+	FamilyID    uuid.UUID
+	Client      Client
+	DateExpires time.Time
+}
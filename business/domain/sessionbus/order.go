@@ -0,0 +1,13 @@
+package sessionbus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByDateLastSeen, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID           = "session_id"
+	OrderByDateCreated  = "date_created"
+	OrderByDateLastSeen = "date_last_seen"
+)
@@ -0,0 +1,244 @@
+// Package sessionbus provides business access to the sessions started by the
+// tokens issued to users, so users can see where they are signed in and end
+// those sessions.
+package sessionbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for session operations.
+var (
+	ErrNotFound = errors.New("session not found")
+)
+
+// touchInterval limits how often the last seen time of a session is written
+// to the database.
+const touchInterval = time.Minute
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, ses Session) error
+	Update(ctx context.Context, ses Session) error
+	Touch(ctx context.Context, tokenID string, now time.Time, before time.Time) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Session, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, sessionID uuid.UUID) (Session, error)
+	QueryByTokenID(ctx context.Context, tokenID string) (Session, error)
+	QueryByFamilyID(ctx context.Context, familyID uuid.UUID) (Session, error)
+	QueryActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]Session, error)
+}
+
+// Business manages the set of APIs for session access.
+type Business struct {
+	log      *logger.Logger
+	tokenBus *tokenbus.Business
+	storer   Storer
+}
+
+// NewBusiness constructs a session business API for use.
+func NewBusiness(log *logger.Logger, tokenBus *tokenbus.Business, storer Storer) *Business {
+	return &Business{
+		log:      log,
+		tokenBus: tokenBus,
+		storer:   storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenBus, err := b.tokenBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:      b.log,
+		tokenBus: tokenBus,
+		storer:   storer,
+	}
+
+	return &bus, nil
+}
+
+// Create records a new session for a token that was issued.
+func (b *Business) Create(ctx context.Context, ns NewSession) (Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	ses := Session{
+		ID:           uuid.New(),
+		UserID:       ns.UserID,
+		TokenID:      ns.TokenID,
+		FamilyID:     ns.FamilyID,
+		Client:       ns.Client,
+		DateCreated:  now,
+		DateLastSeen: now,
+		DateExpires:  ns.DateExpires,
+	}
+
+	if err := b.storer.Create(ctx, ses); err != nil {
+		return Session{}, fmt.Errorf("create: %w", err)
+	}
+
+	return ses, nil
+}
+
+// Refresh moves the session of the refresh token family on to the access
+// token issued when the refresh token was rotated.
+func (b *Business) Refresh(ctx context.Context, familyID uuid.UUID, tokenID string, expires time.Time, client Client) (Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.refresh")
+	defer span.End()
+
+	ses, err := b.storer.QueryByFamilyID(ctx, familyID)
+	if err != nil {
+		return Session{}, fmt.Errorf("querybyfamilyid: familyID[%s]: %w", familyID, err)
+	}
+
+	ses.TokenID = tokenID
+	ses.Client = client
+	ses.DateLastSeen = time.Now()
+	ses.DateExpires = expires
+
+	if err := b.storer.Update(ctx, ses); err != nil {
+		return Session{}, fmt.Errorf("update: %w", err)
+	}
+
+	return ses, nil
+}
+
+// Touch records the session of the specified access token as being used. To
+// limit the writes, the last seen time only moves once per touchInterval.
+func (b *Business) Touch(ctx context.Context, tokenID string) error {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.touch")
+	defer span.End()
+
+	now := time.Now()
+
+	if err := b.storer.Touch(ctx, tokenID, now, now.Add(-touchInterval)); err != nil {
+		return fmt.Errorf("touch: tokenID[%s]: %w", tokenID, err)
+	}
+
+	return nil
+}
+
+// Revoke ends the session. The current access token is revoked along with
+// the refresh token family so the session can't be continued.
+func (b *Business) Revoke(ctx context.Context, ses Session) error {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.revoke")
+	defer span.End()
+
+	if ses.Revoked() {
+		return nil
+	}
+
+	if err := b.tokenBus.RevokeAccess(ctx, ses.TokenID, ses.DateExpires); err != nil {
+		return fmt.Errorf("revokeaccess: %w", err)
+	}
+
+	if ses.FamilyID != uuid.Nil {
+		if err := b.tokenBus.RevokeFamily(ctx, ses.FamilyID); err != nil {
+			return fmt.Errorf("revokefamily: %w", err)
+		}
+	}
+
+	ses.DateRevoked = time.Now()
+
+	if err := b.storer.Update(ctx, ses); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser signs the user out everywhere by ending every active session
+// and revoking every refresh token issued to the user.
+func (b *Business) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.revokeuser")
+	defer span.End()
+
+	sess, err := b.storer.QueryActiveByUserID(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("queryactivebyuserid: userID[%s]: %w", userID, err)
+	}
+
+	for _, ses := range sess {
+		if err := b.Revoke(ctx, ses); err != nil {
+			return fmt.Errorf("revoke: sessionID[%s]: %w", ses.ID, err)
+		}
+	}
+
+	if err := b.tokenBus.RevokeUser(ctx, userID); err != nil {
+		return fmt.Errorf("revokeuser: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing sessions.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.query")
+	defer span.End()
+
+	sess, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return sess, nil
+}
+
+// Count returns the total number of sessions.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the session by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, sessionID uuid.UUID) (Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.querybyid")
+	defer span.End()
+
+	ses, err := b.storer.QueryByID(ctx, sessionID)
+	if err != nil {
+		return Session{}, fmt.Errorf("query: sessionID[%s]: %w", sessionID, err)
+	}
+
+	return ses, nil
+}
+
+// QueryByTokenID finds the session of the specified access token id.
+func (b *Business) QueryByTokenID(ctx context.Context, tokenID string) (Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.sessionbus.querybytokenid")
+	defer span.End()
+
+	ses, err := b.storer.QueryByTokenID(ctx, tokenID)
+	if err != nil {
+		return Session{}, fmt.Errorf("query: tokenID[%s]: %w", tokenID, err)
+	}
+
+	return ses, nil
+}
@@ -0,0 +1,163 @@
+package sessionbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Session(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Session")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, refresh(db.BusDomain, sd), "refresh")
+	unitest.Run(t, revokeUser(db.BusDomain, sd), "revokeuser")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func refresh(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "rotate",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				userID := sd.Users[0].ID
+
+				_, rt, err := busDomain.Token.CreateRefresh(ctx, userID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				ns := sessionbus.NewSession{
+					UserID:      userID,
+					TokenID:     uuid.NewString(),
+					FamilyID:    rt.FamilyID,
+					Client:      sessionbus.Client{Device: "mobile"},
+					DateExpires: rt.DateExpires,
+				}
+
+				ses, err := busDomain.Session.Create(ctx, ns)
+				if err != nil {
+					return err
+				}
+
+				tokenID := uuid.NewString()
+
+				if _, err := busDomain.Session.Refresh(ctx, rt.FamilyID, tokenID, rt.DateExpires, ns.Client); err != nil {
+					return err
+				}
+
+				got, err := busDomain.Session.QueryByTokenID(ctx, tokenID)
+				if err != nil {
+					return err
+				}
+
+				// A family without a session is reported as not found.
+				if _, err := busDomain.Session.Refresh(ctx, uuid.New(), tokenID, rt.DateExpires, ns.Client); !errors.Is(err, sessionbus.ErrNotFound) {
+					return fmt.Errorf("unknown family: %v", err)
+				}
+
+				return got.ID == ses.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func revokeUser(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "signout-everywhere",
+			ExpResp: 0,
+			ExcFunc: func(ctx context.Context) any {
+				userID := sd.Users[1].ID
+
+				sess, err := sessionbus.TestGenerateSeedSessions(ctx, 3, busDomain.Session, userID)
+				if err != nil {
+					return err
+				}
+
+				now := time.Now()
+				filter := sessionbus.QueryFilter{
+					UserID:   &userID,
+					ActiveAt: &now,
+				}
+
+				n, err := busDomain.Session.Count(ctx, filter)
+				if err != nil {
+					return err
+				}
+
+				if n != len(sess) {
+					return fmt.Errorf("active sessions before: got %d, exp %d", n, len(sess))
+				}
+
+				if err := busDomain.Session.RevokeUser(ctx, userID); err != nil {
+					return err
+				}
+
+				// The access tokens of the sessions are revoked as well.
+				revoked, err := busDomain.Token.IsRevoked(ctx, sess[0].TokenID)
+				if err != nil {
+					return err
+				}
+
+				if !revoked {
+					return errors.New("access token not revoked")
+				}
+
+				n, err = busDomain.Session.Count(ctx, filter)
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
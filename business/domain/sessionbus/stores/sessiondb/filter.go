@@ -0,0 +1,32 @@
+package sessiondb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+)
+
+func (s *Store) applyFilter(filter sessionbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["session_id"] = *filter.ID
+		wc = append(wc, "session_id = :session_id")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.ActiveAt != nil {
+		data["active_at"] = filter.ActiveAt.UTC()
+		wc = append(wc, "date_revoked IS NULL AND date_expires > :active_at")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
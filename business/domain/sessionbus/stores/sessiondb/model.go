@@ -0,0 +1,77 @@
+package sessiondb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/google/uuid"
+)
+
+type session struct {
+	ID           uuid.UUID     `db:"session_id"`
+	UserID       uuid.UUID     `db:"user_id"`
+	TokenID      string        `db:"token_id"`
+	FamilyID     uuid.NullUUID `db:"family_id"`
+	Device       string        `db:"device"`
+	IPAddress    string        `db:"ip_address"`
+	UserAgent    string        `db:"user_agent"`
+	DateCreated  time.Time     `db:"date_created"`
+	DateLastSeen time.Time     `db:"date_last_seen"`
+	DateExpires  time.Time     `db:"date_expires"`
+	DateRevoked  sql.NullTime  `db:"date_revoked"`
+}
+
+func toDBSession(bus sessionbus.Session) session {
+	return session{
+		ID:      bus.ID,
+		UserID:  bus.UserID,
+		TokenID: bus.TokenID,
+		FamilyID: uuid.NullUUID{
+			UUID:  bus.FamilyID,
+			Valid: bus.FamilyID != uuid.Nil,
+		},
+		Device:       bus.Client.Device,
+		IPAddress:    bus.Client.IPAddress,
+		UserAgent:    bus.Client.UserAgent,
+		DateCreated:  bus.DateCreated.UTC(),
+		DateLastSeen: bus.DateLastSeen.UTC(),
+		DateExpires:  bus.DateExpires.UTC(),
+		DateRevoked: sql.NullTime{
+			Time:  bus.DateRevoked.UTC(),
+			Valid: !bus.DateRevoked.IsZero(),
+		},
+	}
+}
+
+func toBusSession(db session) sessionbus.Session {
+	bus := sessionbus.Session{
+		ID:       db.ID,
+		UserID:   db.UserID,
+		TokenID:  db.TokenID,
+		FamilyID: db.FamilyID.UUID,
+		Client: sessionbus.Client{
+			Device:    db.Device,
+			IPAddress: db.IPAddress,
+			UserAgent: db.UserAgent,
+		},
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateLastSeen: db.DateLastSeen.In(time.Local),
+		DateExpires:  db.DateExpires.In(time.Local),
+	}
+
+	if db.DateRevoked.Valid {
+		bus.DateRevoked = db.DateRevoked.Time.In(time.Local)
+	}
+
+	return bus
+}
+
+func toBusSessions(dbs []session) []sessionbus.Session {
+	bus := make([]sessionbus.Session, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusSession(db)
+	}
+
+	return bus
+}
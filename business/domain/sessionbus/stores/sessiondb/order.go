@@ -0,0 +1,23 @@
+package sessiondb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	sessionbus.OrderByID:           "session_id",
+	sessionbus.OrderByDateCreated:  "date_created",
+	sessionbus.OrderByDateLastSeen: "date_last_seen",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
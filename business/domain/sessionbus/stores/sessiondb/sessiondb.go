@@ -0,0 +1,281 @@
+// Package sessiondb contains session related CRUD functionality.
+package sessiondb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for session database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (sessionbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new session into the database.
+func (s *Store) Create(ctx context.Context, ses sessionbus.Session) error {
+	const q = `
+	INSERT INTO sessions
+		(session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked)
+	VALUES
+		(:session_id, :user_id, :token_id, :family_id, :device, :ip_address, :user_agent, :date_created, :date_last_seen, :date_expires, :date_revoked)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSession(ses)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a session document in the database.
+func (s *Store) Update(ctx context.Context, ses sessionbus.Session) error {
+	const q = `
+	UPDATE
+		sessions
+	SET
+		"token_id" = :token_id,
+		"device" = :device,
+		"ip_address" = :ip_address,
+		"user_agent" = :user_agent,
+		"date_last_seen" = :date_last_seen,
+		"date_expires" = :date_expires,
+		"date_revoked" = :date_revoked
+	WHERE
+		session_id = :session_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSession(ses)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Touch moves the last seen time of the session for the access token when
+// it was last seen before the specified time.
+func (s *Store) Touch(ctx context.Context, tokenID string, now time.Time, before time.Time) error {
+	data := struct {
+		TokenID      string    `db:"token_id"`
+		DateLastSeen time.Time `db:"date_last_seen"`
+		Before       time.Time `db:"before"`
+	}{
+		TokenID:      tokenID,
+		DateLastSeen: now.UTC(),
+		Before:       before.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		sessions
+	SET
+		date_last_seen = :date_last_seen
+	WHERE
+		token_id = :token_id AND
+		date_last_seen < :before`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing sessions from the database.
+func (s *Store) Query(ctx context.Context, filter sessionbus.QueryFilter, orderBy order.By, page page.Page) ([]sessionbus.Session, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked
+	FROM
+		sessions`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbSess []session
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbSess); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSessions(dbSess), nil
+}
+
+// Count returns the total number of sessions in the DB.
+func (s *Store) Count(ctx context.Context, filter sessionbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		sessions`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified session from the database.
+func (s *Store) QueryByID(ctx context.Context, sessionID uuid.UUID) (sessionbus.Session, error) {
+	data := struct {
+		ID string `db:"session_id"`
+	}{
+		ID: sessionID.String(),
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked
+	FROM
+		sessions
+	WHERE
+		session_id = :session_id`
+
+	var dbSes session
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSes); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return sessionbus.Session{}, fmt.Errorf("db: %w", sessionbus.ErrNotFound)
+		}
+		return sessionbus.Session{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSession(dbSes), nil
+}
+
+// QueryByTokenID gets the session of the access token from the database.
+func (s *Store) QueryByTokenID(ctx context.Context, tokenID string) (sessionbus.Session, error) {
+	data := struct {
+		TokenID string `db:"token_id"`
+	}{
+		TokenID: tokenID,
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked
+	FROM
+		sessions
+	WHERE
+		token_id = :token_id`
+
+	var dbSes session
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSes); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return sessionbus.Session{}, fmt.Errorf("db: %w", sessionbus.ErrNotFound)
+		}
+		return sessionbus.Session{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSession(dbSes), nil
+}
+
+// QueryByFamilyID gets the session of the refresh token family from the
+// database.
+func (s *Store) QueryByFamilyID(ctx context.Context, familyID uuid.UUID) (sessionbus.Session, error) {
+	data := struct {
+		FamilyID string `db:"family_id"`
+	}{
+		FamilyID: familyID.String(),
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked
+	FROM
+		sessions
+	WHERE
+		family_id = :family_id`
+
+	var dbSes session
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSes); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return sessionbus.Session{}, fmt.Errorf("db: %w", sessionbus.ErrNotFound)
+		}
+		return sessionbus.Session{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSession(dbSes), nil
+}
+
+// QueryActiveByUserID gets the sessions of the user that are still active
+// as of the specified time.
+func (s *Store) QueryActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]sessionbus.Session, error) {
+	data := struct {
+		UserID   string    `db:"user_id"`
+		ActiveAt time.Time `db:"active_at"`
+	}{
+		UserID:   userID.String(),
+		ActiveAt: now.UTC(),
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, token_id, family_id, device, ip_address, user_agent, date_created, date_last_seen, date_expires, date_revoked
+	FROM
+		sessions
+	WHERE
+		user_id = :user_id AND
+		date_revoked IS NULL AND
+		date_expires > :active_at`
+
+	var dbSess []session
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSess); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSessions(dbSess), nil
+}
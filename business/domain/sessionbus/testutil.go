@@ -0,0 +1,52 @@
+package sessionbus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestGenerateNewSessions is a helper method for testing.
+func TestGenerateNewSessions(n int, userID uuid.UUID) []NewSession {
+	newSess := make([]NewSession, n)
+
+	idx := rand.Intn(10000)
+	for i := 0; i < n; i++ {
+		idx++
+
+		ns := NewSession{
+			UserID:  userID,
+			TokenID: uuid.NewString(),
+			Client: Client{
+				Device:    "desktop",
+				IPAddress: fmt.Sprintf("10.0.%d.%d", idx/256%256, idx%256),
+				UserAgent: fmt.Sprintf("Agent%d", idx),
+			},
+			DateExpires: time.Now().Add(time.Hour),
+		}
+
+		newSess[i] = ns
+	}
+
+	return newSess
+}
+
+// TestGenerateSeedSessions is a helper method for testing.
+func TestGenerateSeedSessions(ctx context.Context, n int, api *Business, userID uuid.UUID) ([]Session, error) {
+	newSess := TestGenerateNewSessions(n, userID)
+
+	sess := make([]Session, len(newSess))
+	for i, ns := range newSess {
+		ses, err := api.Create(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("seeding session: idx: %d : %w", i, err)
+		}
+
+		sess[i] = ses
+	}
+
+	return sess, nil
+}
@@ -0,0 +1,40 @@
+package tokenbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken represents a refresh token that was issued to a user. Only
+// the hash of the token is stored, the raw token is only ever known by the
+// client it was issued to. MFA records if the family was started by a login
+// that completed multi-factor authentication.
+type RefreshToken struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	FamilyID    uuid.UUID
+	Hash        string
+	MFA         bool
+	DateExpires time.Time
+	DateCreated time.Time
+	DateRevoked time.Time
+}
+
+// Revoked reports if the refresh token has been revoked.
+func (rt RefreshToken) Revoked() bool {
+	return !rt.DateRevoked.IsZero()
+}
+
+// Expired reports if the refresh token has expired as of the specified time.
+func (rt RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(rt.DateExpires)
+}
+
+// RevokedToken represents an access token (jti) that has been revoked before
+// it expired.
+type RevokedToken struct {
+	TokenID     string
+	DateExpires time.Time
+	DateRevoked time.Time
+}
@@ -0,0 +1,78 @@
+package tokendb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/google/uuid"
+)
+
+type refreshToken struct {
+	ID          uuid.UUID    `db:"token_id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	FamilyID    uuid.UUID    `db:"family_id"`
+	Hash        string       `db:"token_hash"`
+	MFA         bool         `db:"mfa"`
+	DateExpires time.Time    `db:"date_expires"`
+	DateCreated time.Time    `db:"date_created"`
+	DateRevoked sql.NullTime `db:"date_revoked"`
+}
+
+func toDBRefreshToken(bus tokenbus.RefreshToken) refreshToken {
+	return refreshToken{
+		ID:          bus.ID,
+		UserID:      bus.UserID,
+		FamilyID:    bus.FamilyID,
+		Hash:        bus.Hash,
+		MFA:         bus.MFA,
+		DateExpires: bus.DateExpires.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+		DateRevoked: sql.NullTime{
+			Time:  bus.DateRevoked.UTC(),
+			Valid: !bus.DateRevoked.IsZero(),
+		},
+	}
+}
+
+func toBusRefreshToken(db refreshToken) tokenbus.RefreshToken {
+	bus := tokenbus.RefreshToken{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		FamilyID:    db.FamilyID,
+		Hash:        db.Hash,
+		MFA:         db.MFA,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	if db.DateRevoked.Valid {
+		bus.DateRevoked = db.DateRevoked.Time.In(time.Local)
+	}
+
+	return bus
+}
+
+// =============================================================================
+
+type revokedToken struct {
+	TokenID     string    `db:"token_id"`
+	DateExpires time.Time `db:"date_expires"`
+	DateRevoked time.Time `db:"date_revoked"`
+}
+
+func toDBRevokedToken(bus tokenbus.RevokedToken) revokedToken {
+	return revokedToken{
+		TokenID:     bus.TokenID,
+		DateExpires: bus.DateExpires.UTC(),
+		DateRevoked: bus.DateRevoked.UTC(),
+	}
+}
+
+func toBusRevokedToken(db revokedToken) tokenbus.RevokedToken {
+	return tokenbus.RevokedToken{
+		TokenID:     db.TokenID,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateRevoked: db.DateRevoked.In(time.Local),
+	}
+}
@@ -0,0 +1,274 @@
+// Package tokendb contains token related CRUD functionality.
+package tokendb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for token database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (tokenbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// CreateRefresh inserts a new refresh token into the database.
+func (s *Store) CreateRefresh(ctx context.Context, rt tokenbus.RefreshToken) error {
+	const q = `
+	INSERT INTO refresh_tokens
+		(token_id, user_id, family_id, token_hash, mfa, date_expires, date_created, date_revoked)
+	VALUES
+		(:token_id, :user_id, :family_id, :token_hash, :mfa, :date_expires, :date_created, :date_revoked)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRefreshToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRefresh marks a single refresh token as revoked. The update only
+// succeeds if the token has not already been revoked, which prevents two
+// concurrent requests from rotating the same token.
+func (s *Store) RevokeRefresh(ctx context.Context, rt tokenbus.RefreshToken) error {
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		date_revoked = :date_revoked
+	WHERE
+		token_id = :token_id AND
+		date_revoked IS NULL
+	RETURNING
+		token_id, user_id, family_id, token_hash, mfa, date_expires, date_created, date_revoked`
+
+	var dbRT refreshToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBRefreshToken(rt), &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", tokenbus.ErrRevoked)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeFamily marks every active refresh token in the family as revoked.
+func (s *Store) RevokeFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	data := struct {
+		FamilyID    string    `db:"family_id"`
+		DateRevoked time.Time `db:"date_revoked"`
+	}{
+		FamilyID:    familyID.String(),
+		DateRevoked: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		date_revoked = :date_revoked
+	WHERE
+		family_id = :family_id AND
+		date_revoked IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser marks every active refresh token for the user as revoked.
+func (s *Store) RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	data := struct {
+		UserID      string    `db:"user_id"`
+		DateRevoked time.Time `db:"date_revoked"`
+	}{
+		UserID:      userID.String(),
+		DateRevoked: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		refresh_tokens
+	SET
+		date_revoked = :date_revoked
+	WHERE
+		user_id = :user_id AND
+		date_revoked IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRefreshByHash gets the specified refresh token from the database.
+func (s *Store) QueryRefreshByHash(ctx context.Context, hash string) (tokenbus.RefreshToken, error) {
+	data := struct {
+		Hash string `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, family_id, token_hash, mfa, date_expires, date_created, date_revoked
+	FROM
+		refresh_tokens
+	WHERE
+		token_hash = :token_hash`
+
+	var dbRT refreshToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return tokenbus.RefreshToken{}, fmt.Errorf("db: %w", tokenbus.ErrNotFound)
+		}
+		return tokenbus.RefreshToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRefreshToken(dbRT), nil
+}
+
+// CreateRevoked records a revoked access token in the database.
+func (s *Store) CreateRevoked(ctx context.Context, rt tokenbus.RevokedToken) error {
+	const q = `
+	INSERT INTO revoked_tokens
+		(token_id, date_expires, date_revoked)
+	VALUES
+		(:token_id, :date_expires, :date_revoked)
+	ON CONFLICT (token_id) DO NOTHING`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRevokedToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRevokedByID gets the specified revoked access token from the database.
+func (s *Store) QueryRevokedByID(ctx context.Context, tokenID string) (tokenbus.RevokedToken, error) {
+	data := struct {
+		TokenID string `db:"token_id"`
+	}{
+		TokenID: tokenID,
+	}
+
+	const q = `
+	SELECT
+		token_id, date_expires, date_revoked
+	FROM
+		revoked_tokens
+	WHERE
+		token_id = :token_id`
+
+	var dbRT revokedToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return tokenbus.RevokedToken{}, fmt.Errorf("db: %w", tokenbus.ErrNotFound)
+		}
+		return tokenbus.RevokedToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRevokedToken(dbRT), nil
+}
+
+// PurgeRefresh deletes the refresh tokens that expired before the specified
+// time and returns how many were removed.
+func (s *Store) PurgeRefresh(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH purged AS (
+		DELETE FROM
+			refresh_tokens
+		WHERE
+			date_expires < :before
+		RETURNING
+			token_id
+	)
+	SELECT
+		count(1)
+	FROM
+		purged`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// PurgeRevoked deletes the revoked access tokens that expired before the
+// specified time and returns how many were removed.
+func (s *Store) PurgeRevoked(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH purged AS (
+		DELETE FROM
+			revoked_tokens
+		WHERE
+			date_expires < :before
+		RETURNING
+			token_id
+	)
+	SELECT
+		count(1)
+	FROM
+		purged`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -0,0 +1,289 @@
+// Package tokenbus provides business access to the token domain. It manages
+// rotating refresh tokens and the set of access tokens that were revoked
+// before they expired.
+package tokenbus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for token operations.
+var (
+	ErrNotFound = errors.New("token not found")
+	ErrExpired  = errors.New("token expired")
+	ErrRevoked  = errors.New("token revoked")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	CreateRefresh(ctx context.Context, rt RefreshToken) error
+	RevokeRefresh(ctx context.Context, rt RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error
+	RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+	QueryRefreshByHash(ctx context.Context, hash string) (RefreshToken, error)
+	CreateRevoked(ctx context.Context, rt RevokedToken) error
+	QueryRevokedByID(ctx context.Context, tokenID string) (RevokedToken, error)
+	PurgeRefresh(ctx context.Context, before time.Time) (int, error)
+	PurgeRevoked(ctx context.Context, before time.Time) (int, error)
+}
+
+// Business manages the set of APIs for token access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a token business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// CreateRefresh issues a new refresh token for the specified user that starts
+// a new token family. The raw token is returned and is never stored.
+func (b *Business) CreateRefresh(ctx context.Context, userID uuid.UUID, ttl time.Duration) (string, RefreshToken, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.createrefresh")
+	defer span.End()
+
+	return b.createRefresh(ctx, userID, uuid.New(), false, ttl)
+}
+
+// CreateMFARefresh issues a new refresh token like CreateRefresh for a user
+// that completed multi-factor authentication. Tokens rotated from it keep
+// recording that the second factor was provided.
+func (b *Business) CreateMFARefresh(ctx context.Context, userID uuid.UUID, ttl time.Duration) (string, RefreshToken, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.createmfarefresh")
+	defer span.End()
+
+	return b.createRefresh(ctx, userID, uuid.New(), true, ttl)
+}
+
+// Rotate exchanges a refresh token for a new one in the same family. The
+// provided token is revoked in the process. If a token that was already
+// revoked is presented, the token was likely stolen and the entire family is
+// revoked so neither party can continue to use it. The caller should run it
+// in a transaction so a token is never revoked without its replacement, and
+// commit it when ErrRevoked is returned so the family stays revoked.
+func (b *Business) Rotate(ctx context.Context, token string, ttl time.Duration) (string, RefreshToken, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.rotate")
+	defer span.End()
+
+	rt, err := b.storer.QueryRefreshByHash(ctx, hashToken(token))
+	if err != nil {
+		return "", RefreshToken{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if rt.Revoked() {
+		b.log.Info(ctx, "tokenbus: refresh token reuse detected", "user_id", rt.UserID, "family_id", rt.FamilyID)
+
+		if err := b.storer.RevokeFamily(ctx, rt.FamilyID, now); err != nil {
+			return "", RefreshToken{}, fmt.Errorf("revokefamily: %w", err)
+		}
+
+		return "", RefreshToken{}, ErrRevoked
+	}
+
+	if rt.Expired(now) {
+		return "", RefreshToken{}, ErrExpired
+	}
+
+	rt.DateRevoked = now
+
+	if err := b.storer.RevokeRefresh(ctx, rt); err != nil {
+		return "", RefreshToken{}, fmt.Errorf("revokerefresh: %w", err)
+	}
+
+	return b.createRefresh(ctx, rt.UserID, rt.FamilyID, rt.MFA, ttl)
+}
+
+// RevokeRefresh revokes the family the specified refresh token belongs to
+// when the token was issued to the specified user. Nothing is revoked for a
+// token that doesn't exist or belongs to another user, and no error is
+// returned so the call can't be used to learn which tokens exist.
+func (b *Business) RevokeRefresh(ctx context.Context, userID uuid.UUID, token string) error {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.revokerefresh")
+	defer span.End()
+
+	rt, err := b.storer.QueryRefreshByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if rt.UserID != userID {
+		return nil
+	}
+
+	if err := b.storer.RevokeFamily(ctx, rt.FamilyID, time.Now()); err != nil {
+		return fmt.Errorf("revokefamily: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in the specified family.
+func (b *Business) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.revokefamily")
+	defer span.End()
+
+	if err := b.storer.RevokeFamily(ctx, familyID, time.Now()); err != nil {
+		return fmt.Errorf("revokefamily: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every refresh token issued to the specified user.
+func (b *Business) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.revokeuser")
+	defer span.End()
+
+	if err := b.storer.RevokeUser(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("revokeuser: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAccess records the specified access token id as revoked. The record
+// only needs to be kept until the access token would have expired.
+func (b *Business) RevokeAccess(ctx context.Context, tokenID string, expires time.Time) error {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.revokeaccess")
+	defer span.End()
+
+	rt := RevokedToken{
+		TokenID:     tokenID,
+		DateExpires: expires,
+		DateRevoked: time.Now(),
+	}
+
+	if err := b.storer.CreateRevoked(ctx, rt); err != nil {
+		return fmt.Errorf("createrevoked: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked reports if the specified access token id has been revoked.
+func (b *Business) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.isrevoked")
+	defer span.End()
+
+	if _, err := b.storer.QueryRevokedByID(ctx, tokenID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("query: tokenID[%s]: %w", tokenID, err)
+	}
+
+	return true, nil
+}
+
+// PurgeRefresh permanently removes the refresh tokens that expired before the
+// specified time. It returns how many tokens were removed.
+func (b *Business) PurgeRefresh(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.purgerefresh")
+	defer span.End()
+
+	n, err := b.storer.PurgeRefresh(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("purgerefresh: %w", err)
+	}
+
+	return n, nil
+}
+
+// PurgeRevoked permanently removes the records of revoked access tokens that
+// expired before the specified time, since those tokens are rejected anyway.
+// It returns how many records were removed.
+func (b *Business) PurgeRevoked(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.tokenbus.purgerevoked")
+	defer span.End()
+
+	n, err := b.storer.PurgeRevoked(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("purgerevoked: %w", err)
+	}
+
+	return n, nil
+}
+
+// =============================================================================
+
+func (b *Business) createRefresh(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, mfa bool, ttl time.Duration) (string, RefreshToken, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", RefreshToken{}, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	now := time.Now()
+
+	rt := RefreshToken{
+		ID:          uuid.New(),
+		UserID:      userID,
+		FamilyID:    familyID,
+		Hash:        hashToken(token),
+		MFA:         mfa,
+		DateExpires: now.Add(ttl),
+		DateCreated: now,
+	}
+
+	if err := b.storer.CreateRefresh(ctx, rt); err != nil {
+		return "", RefreshToken{}, fmt.Errorf("createrefresh: %w", err)
+	}
+
+	return token, rt, nil
+}
+
+// generateToken produces a random, url safe token with 256 bits of entropy.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken produces the value that is stored for a raw token. A fast hash
+// is fine here since the token has enough entropy to not be guessable.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
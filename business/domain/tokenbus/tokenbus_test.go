@@ -0,0 +1,331 @@
+package tokenbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Token(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Token")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, rotate(db.BusDomain, sd), "rotate")
+	unitest.Run(t, revoke(db.BusDomain, sd), "revoke")
+	unitest.Run(t, tran(db, sd), "tran")
+	unitest.Run(t, purge(db.BusDomain, sd), "purge")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func rotate(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				token, rt, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				_, newRT, err := busDomain.Token.Rotate(ctx, token, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				return newRT.FamilyID == rt.FamilyID && newRT.ID != rt.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "reuse",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				token, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				newToken, _, err := busDomain.Token.Rotate(ctx, token, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				// Presenting the old token again must revoke the family.
+				if _, _, err := busDomain.Token.Rotate(ctx, token, time.Hour); !errors.Is(err, tokenbus.ErrRevoked) {
+					return fmt.Errorf("expected revoked on reuse, got %v", err)
+				}
+
+				_, _, err = busDomain.Token.Rotate(ctx, newToken, time.Hour)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if !errors.Is(got.(error), exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "expired",
+			ExpResp: tokenbus.ErrExpired,
+			ExcFunc: func(ctx context.Context) any {
+				token, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, -time.Minute)
+				if err != nil {
+					return err
+				}
+
+				_, _, err = busDomain.Token.Rotate(ctx, token, time.Hour)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if !errors.Is(got.(error), exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func revoke(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "access",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				const tokenID = "4c5b2ad6-6e51-4fa5-9b1b-8a4f8a1d6a10"
+
+				if err := busDomain.Token.RevokeAccess(ctx, tokenID, time.Now().Add(time.Hour)); err != nil {
+					return err
+				}
+
+				revoked, err := busDomain.Token.IsRevoked(ctx, tokenID)
+				if err != nil {
+					return err
+				}
+
+				return revoked
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "refresh",
+			ExpResp: tokenbus.ErrRevoked,
+			ExcFunc: func(ctx context.Context) any {
+				token, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.RevokeRefresh(ctx, sd.Users[0].ID, token); err != nil {
+					return err
+				}
+
+				_, _, err = busDomain.Token.Rotate(ctx, token, time.Hour)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if !errors.Is(got.(error), exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "refresh-other-user",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				token, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.RevokeRefresh(ctx, sd.Users[1].ID, token); err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.RevokeRefresh(ctx, sd.Users[1].ID, "unknown"); err != nil {
+					return err
+				}
+
+				if _, _, err := busDomain.Token.Rotate(ctx, token, time.Hour); err != nil {
+					return err
+				}
+
+				return true
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func tran(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "rollback",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				token, rt, err := db.BusDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				tx, err := sqldb.NewBeginner(db.DB).Begin()
+				if err != nil {
+					return err
+				}
+
+				tokenBus, err := db.BusDomain.Token.NewWithTx(tx)
+				if err != nil {
+					return err
+				}
+
+				if _, _, err := tokenBus.Rotate(ctx, token, time.Hour); err != nil {
+					return err
+				}
+
+				if err := tx.Rollback(); err != nil {
+					return err
+				}
+
+				// The revoke was rolled back with the replacement, so the
+				// token can still be exchanged.
+				_, newRT, err := db.BusDomain.Token.Rotate(ctx, token, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				return newRT.FamilyID == rt.FamilyID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func purge(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "refresh",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				expired, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, -time.Minute)
+				if err != nil {
+					return err
+				}
+
+				live, _, err := busDomain.Token.CreateRefresh(ctx, sd.Users[0].ID, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				n, err := busDomain.Token.PurgeRefresh(ctx, time.Now())
+				if err != nil {
+					return err
+				}
+
+				if _, _, err := busDomain.Token.Rotate(ctx, expired, time.Hour); !errors.Is(err, tokenbus.ErrNotFound) {
+					return fmt.Errorf("expected not found for the expired token, got %v", err)
+				}
+
+				if _, _, err := busDomain.Token.Rotate(ctx, live, time.Hour); err != nil {
+					return err
+				}
+
+				return n >= 1
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "revoked",
+			ExpResp: []bool{false, true},
+			ExcFunc: func(ctx context.Context) any {
+				const expiredID = "0b6f3c1e-8f2a-4a4e-9c55-3f2d7e1a9b01"
+				const liveID = "0b6f3c1e-8f2a-4a4e-9c55-3f2d7e1a9b02"
+
+				if err := busDomain.Token.RevokeAccess(ctx, expiredID, time.Now().Add(-time.Minute)); err != nil {
+					return err
+				}
+
+				if err := busDomain.Token.RevokeAccess(ctx, liveID, time.Now().Add(time.Hour)); err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Token.PurgeRevoked(ctx, time.Now()); err != nil {
+					return err
+				}
+
+				got := make([]bool, 2)
+				for i, id := range []string{expiredID, liveID} {
+					revoked, err := busDomain.Token.IsRevoked(ctx, id)
+					if err != nil {
+						return err
+					}
+					got[i] = revoked
+				}
+
+				return got
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
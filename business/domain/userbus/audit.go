@@ -0,0 +1,66 @@
+package userbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+// auditSnapshot is the view of a user kept in the audit history. The
+// password hash is never recorded.
+type auditSnapshot struct {
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Roles         []string  `json:"roles"`
+	Department    string    `json:"department,omitempty"`
+	Enabled       bool      `json:"enabled"`
+	EmailVerified bool      `json:"emailVerified"`
+	DateUpdated   time.Time `json:"dateUpdated"`
+}
+
+func toAuditSnapshot(usr *User) any {
+	if usr == nil {
+		return nil
+	}
+
+	var department string
+	if usr.Department.Valid() {
+		department = usr.Department.String()
+	}
+
+	return auditSnapshot{
+		Name:          usr.Name.String(),
+		Email:         usr.Email.Address,
+		Roles:         role.ParseToString(usr.Roles),
+		Department:    department,
+		Enabled:       usr.Enabled,
+		EmailVerified: usr.EmailVerified,
+		DateUpdated:   usr.DateUpdated,
+	}
+}
+
+// audit records a change to the user in the audit history. Nothing is
+// recorded when the business was constructed without an audit business.
+func (b *Business) audit(ctx context.Context, action string, usr User, before *User, after *User) error {
+	if b.auditBus == nil {
+		return nil
+	}
+
+	na := auditbus.NewAudit{
+		Entity:   DomainName,
+		EntityID: usr.ID,
+		OrgID:    usr.OrgID,
+		Action:   action,
+		Before:   toAuditSnapshot(before),
+		After:    toAuditSnapshot(after),
+	}
+
+	if _, err := b.auditBus.Record(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
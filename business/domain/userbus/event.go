@@ -1,7 +1,6 @@
 package userbus
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/ardanlabs/service/business/sdk/delegate"
@@ -13,7 +12,21 @@ const DomainName = "user"
 
 // Set of delegate actions.
 const (
-	ActionUpdated = "updated"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// Set of events raised by this domain.
+var (
+	// EventUpdated is the event raised when a user is updated.
+	EventUpdated = delegate.NewEvent[ActionUpdatedParms](DomainName, ActionUpdated, 1)
+
+	// EventDeleted is the event raised when a user is deleted.
+	EventDeleted = delegate.NewEvent[ActionDeletedParms](DomainName, ActionDeleted, 1)
+
+	// EventRestored is the event raised when a deleted user is restored.
+	EventRestored = delegate.NewEvent[ActionRestoredParms](DomainName, ActionRestored, 1)
 )
 
 // ActionUpdatedParms represents the parameters for the updated action.
@@ -27,13 +40,8 @@ func (au *ActionUpdatedParms) String() string {
 	return fmt.Sprintf("&EventParamsUpdated{UserID:%v, Enabled:%v}", au.UserID, au.Enabled)
 }
 
-// Marshal returns the event parameters encoded as JSON.
-func (au *ActionUpdatedParms) Marshal() ([]byte, error) {
-	return json.Marshal(au)
-}
-
 // ActionUpdatedData constructs the data for the updated action.
-func ActionUpdatedData(uu UpdateUser, userID uuid.UUID) delegate.Data {
+func ActionUpdatedData(uu UpdateUser, userID uuid.UUID) (delegate.Data, error) {
 	params := ActionUpdatedParms{
 		UserID: userID,
 		UpdateUser: UpdateUser{
@@ -41,14 +49,47 @@ func ActionUpdatedData(uu UpdateUser, userID uuid.UUID) delegate.Data {
 		},
 	}
 
-	rawParams, err := params.Marshal()
-	if err != nil {
-		panic(err)
+	return EventUpdated.Data(params)
+}
+
+// =============================================================================
+
+// ActionDeletedParms represents the parameters for the deleted action.
+type ActionDeletedParms struct {
+	UserID uuid.UUID
+}
+
+// String returns a string representation of the action parameters.
+func (ad *ActionDeletedParms) String() string {
+	return fmt.Sprintf("&EventParamsDeleted{UserID:%v}", ad.UserID)
+}
+
+// ActionDeletedData constructs the data for the deleted action.
+func ActionDeletedData(userID uuid.UUID) (delegate.Data, error) {
+	params := ActionDeletedParms{
+		UserID: userID,
 	}
 
-	return delegate.Data{
-		Domain:    DomainName,
-		Action:    ActionUpdated,
-		RawParams: rawParams,
+	return EventDeleted.Data(params)
+}
+
+// =============================================================================
+
+// ActionRestoredParms represents the parameters for the restored action.
+type ActionRestoredParms struct {
+	UserID uuid.UUID
+}
+
+// String returns a string representation of the action parameters.
+func (ar *ActionRestoredParms) String() string {
+	return fmt.Sprintf("&EventParamsRestored{UserID:%v}", ar.UserID)
+}
+
+// ActionRestoredData constructs the data for the restored action.
+func ActionRestoredData(userID uuid.UUID) (delegate.Data, error) {
+	params := ActionRestoredParms{
+		UserID: userID,
 	}
+
+	return EventRestored.Data(params)
 }
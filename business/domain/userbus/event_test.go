@@ -0,0 +1,82 @@
+package userbus_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/google/uuid"
+)
+
+// Test_EventContract protects consumers of the user events from changes to
+// the params that aren't accompanied by a new version.
+func Test_EventContract(t *testing.T) {
+	events := map[string]reflect.Type{
+		userbus.ActionUpdated:  reflect.TypeFor[userbus.ActionUpdatedParms](),
+		userbus.ActionDeleted:  reflect.TypeFor[userbus.ActionDeletedParms](),
+		userbus.ActionRestored: reflect.TypeFor[userbus.ActionRestoredParms](),
+	}
+
+	for action, params := range events {
+		var info delegate.EventInfo
+		for _, ei := range delegate.Events() {
+			if ei.Domain == userbus.DomainName && ei.Action == action {
+				info = ei
+			}
+		}
+
+		if info.Version != 1 || info.Params != params {
+			t.Fatalf("Should define the %s event at version 1 : %v", action, info)
+		}
+	}
+
+	enabled := false
+	userID := uuid.New()
+
+	data, err := userbus.ActionUpdatedData(userbus.UpdateUser{Enabled: &enabled}, userID)
+	if err != nil {
+		t.Fatalf("Should be able to construct the data : %s", err)
+	}
+
+	params, err := userbus.EventUpdated.Decode(data)
+	if err != nil {
+		t.Fatalf("Should be able to decode the data : %s", err)
+	}
+
+	if params.UserID != userID || params.Enabled == nil || *params.Enabled {
+		t.Fatalf("Should round trip the params : %s", params.String())
+	}
+}
+
+func Test_EventDeletedRestored(t *testing.T) {
+	userID := uuid.New()
+
+	data, err := userbus.ActionDeletedData(userID)
+	if err != nil {
+		t.Fatalf("Should be able to construct the deleted data : %s", err)
+	}
+
+	deleted, err := userbus.EventDeleted.Decode(data)
+	if err != nil {
+		t.Fatalf("Should be able to decode the deleted data : %s", err)
+	}
+
+	if deleted.UserID != userID {
+		t.Fatalf("Should round trip the deleted params : %s", deleted.String())
+	}
+
+	data, err = userbus.ActionRestoredData(userID)
+	if err != nil {
+		t.Fatalf("Should be able to construct the restored data : %s", err)
+	}
+
+	restored, err := userbus.EventRestored.Decode(data)
+	if err != nil {
+		t.Fatalf("Should be able to decode the restored data : %s", err)
+	}
+
+	if restored.UserID != userID {
+		t.Fatalf("Should round trip the restored params : %s", restored.String())
+	}
+}
@@ -12,6 +12,7 @@ import (
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
 	ID               *uuid.UUID
+	OrgID            *uuid.UUID
 	Name             *name.Name
 	Email            *mail.Address
 	StartCreatedDate *time.Time
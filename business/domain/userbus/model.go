@@ -9,17 +9,23 @@ import (
 	"github.com/google/uuid"
 )
 
-// User represents information about an individual user.
+// User represents information about an individual user. Version is
+// incremented on every change to the user and is used to detect concurrent
+// updates. A user can only log in when they are enabled and their email has
+// been verified.
 type User struct {
-	ID           uuid.UUID
-	Name         name.Name
-	Email        mail.Address
-	Roles        []role.Role
-	PasswordHash []byte
-	Department   name.Null
-	Enabled      bool
-	DateCreated  time.Time
-	DateUpdated  time.Time
+	ID            uuid.UUID
+	Name          name.Name
+	Email         mail.Address
+	Roles         []role.Role
+	PasswordHash  []byte
+	Department    name.Null
+	Enabled       bool
+	EmailVerified bool
+	OrgID         uuid.UUID
+	DateCreated   time.Time
+	DateUpdated   time.Time
+	Version       int
 }
 
 // NewUser contains information needed to create a new user.
@@ -29,14 +35,16 @@ type NewUser struct {
 	Roles      []role.Role
 	Department name.Null
 	Password   string
+	OrgID      uuid.UUID
 }
 
 // UpdateUser contains information needed to update a user.
 type UpdateUser struct {
-	Name       *name.Name
-	Email      *mail.Address
-	Roles      []role.Role
-	Department *name.Null
-	Password   *string
-	Enabled    *bool
+	Name          *name.Name
+	Email         *mail.Address
+	Roles         []role.Role
+	Department    *name.Null
+	Password      *string
+	Enabled       *bool
+	EmailVerified *bool
 }
@@ -15,11 +15,14 @@ import (
 	"github.com/viccon/sturdyc"
 )
 
-// Store manages the set of APIs for user data and caching.
+// Store manages the set of APIs for user data and caching. A store used
+// inside a transaction never reads from the cache and evicts the users it
+// writes, since the transaction may still be rolled back.
 type Store struct {
 	log    *logger.Logger
 	storer userbus.Storer
 	cache  *sturdyc.Client[userbus.User]
+	inTx   bool
 }
 
 // NewStore constructs the api for data and caching access.
@@ -38,7 +41,19 @@ func NewStore(log *logger.Logger, storer userbus.Storer, ttl time.Duration) *Sto
 // NewWithTx constructs a new Store value replacing the sqlx DB
 // value with a sqlx DB value that is currently inside a transaction.
 func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
-	return s.storer.NewWithTx(tx)
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:    s.log,
+		storer: storer,
+		cache:  s.cache,
+		inTx:   true,
+	}
+
+	return &store, nil
 }
 
 // Create inserts a new user into the database.
@@ -58,14 +73,19 @@ func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 		return err
 	}
 
+	// The email may have changed, so the entry under the old one goes too.
+	if cachedUsr, ok := s.readCache(usr.ID.String()); ok {
+		s.deleteCache(cachedUsr)
+	}
+
 	s.writeCache(usr)
 
 	return nil
 }
 
-// Delete removes a user from the database.
-func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
-	if err := s.storer.Delete(ctx, usr); err != nil {
+// Delete marks a user as deleted in the database.
+func (s *Store) Delete(ctx context.Context, usr userbus.User, now time.Time) error {
+	if err := s.storer.Delete(ctx, usr, now); err != nil {
 		return err
 	}
 
@@ -86,6 +106,10 @@ func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, err
 
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	if s.inTx {
+		return s.storer.QueryByID(ctx, userID)
+	}
+
 	cachedUsr, ok := s.readCache(userID.String())
 	if ok {
 		return cachedUsr, nil
@@ -103,6 +127,10 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	if s.inTx {
+		return s.storer.QueryByEmail(ctx, email)
+	}
+
 	cachedUsr, ok := s.readCache(email.Address)
 	if ok {
 		return cachedUsr, nil
@@ -118,6 +146,24 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 	return usr, nil
 }
 
+// Restore clears the deleted mark from the specified user and returns it.
+func (s *Store) Restore(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	usr, err := s.storer.Restore(ctx, userID)
+	if err != nil {
+		return userbus.User{}, err
+	}
+
+	s.writeCache(usr)
+
+	return usr, nil
+}
+
+// Purge permanently removes the users deleted before the specified time.
+// Deleted users are never cached so there is nothing to evict.
+func (s *Store) Purge(ctx context.Context, before time.Time) (int, error) {
+	return s.storer.Purge(ctx, before)
+}
+
 // readCache performs a safe search in the cache for the specified key.
 func (s *Store) readCache(key string) (userbus.User, bool) {
 	usr, exists := s.cache.Get(key)
@@ -129,7 +175,13 @@ func (s *Store) readCache(key string) (userbus.User, bool) {
 }
 
 // writeCache performs a safe write to the cache for the specified userbus.
+// Inside a transaction the user is evicted instead.
 func (s *Store) writeCache(bus userbus.User) {
+	if s.inTx {
+		s.deleteCache(bus)
+		return
+	}
+
 	s.cache.Set(bus.ID.String(), bus)
 	s.cache.Set(bus.Email.Address, bus)
 }
@@ -16,6 +16,11 @@ func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buf
 		wc = append(wc, "user_id = :user_id")
 	}
 
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
 	if filter.Name != nil {
 		data["name"] = fmt.Sprintf("%%%s%%", *filter.Name)
 		wc = append(wc, "name LIKE :name")
@@ -36,8 +41,8 @@ func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buf
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
-	if len(wc) > 0 {
-		buf.WriteString(" WHERE ")
-		buf.WriteString(strings.Join(wc, " AND "))
-	}
+	wc = append(wc, "date_deleted IS NULL")
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
 }
@@ -14,15 +14,18 @@ import (
 )
 
 type user struct {
-	ID           uuid.UUID      `db:"user_id"`
-	Name         string         `db:"name"`
-	Email        string         `db:"email"`
-	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
-	Department   sql.NullString `db:"department"`
-	Enabled      bool           `db:"enabled"`
-	DateCreated  time.Time      `db:"date_created"`
-	DateUpdated  time.Time      `db:"date_updated"`
+	ID            uuid.UUID      `db:"user_id"`
+	Name          string         `db:"name"`
+	Email         string         `db:"email"`
+	Roles         dbarray.String `db:"roles"`
+	PasswordHash  []byte         `db:"password_hash"`
+	Department    sql.NullString `db:"department"`
+	Enabled       bool           `db:"enabled"`
+	EmailVerified bool           `db:"email_verified"`
+	OrgID         uuid.UUID      `db:"org_id"`
+	DateCreated   time.Time      `db:"date_created"`
+	DateUpdated   time.Time      `db:"date_updated"`
+	Version       int            `db:"version"`
 }
 
 func toDBUser(bus userbus.User) user {
@@ -36,9 +39,12 @@ func toDBUser(bus userbus.User) user {
 			String: bus.Department.String(),
 			Valid:  bus.Department.Valid(),
 		},
-		Enabled:     bus.Enabled,
-		DateCreated: bus.DateCreated.UTC(),
-		DateUpdated: bus.DateUpdated.UTC(),
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		OrgID:         bus.OrgID,
+		DateCreated:   bus.DateCreated.UTC(),
+		DateUpdated:   bus.DateUpdated.UTC(),
+		Version:       bus.Version,
 	}
 }
 
@@ -63,15 +69,18 @@ func toBusUser(db user) (userbus.User, error) {
 	}
 
 	bus := userbus.User{
-		ID:           db.ID,
-		Name:         nme,
-		Email:        addr,
-		Roles:        roles,
-		PasswordHash: db.PasswordHash,
-		Enabled:      db.Enabled,
-		Department:   department,
-		DateCreated:  db.DateCreated.In(time.Local),
-		DateUpdated:  db.DateUpdated.In(time.Local),
+		ID:            db.ID,
+		Name:          nme,
+		Email:         addr,
+		Roles:         roles,
+		PasswordHash:  db.PasswordHash,
+		Enabled:       db.Enabled,
+		EmailVerified: db.EmailVerified,
+		Department:    department,
+		OrgID:         db.OrgID,
+		DateCreated:   db.DateCreated.In(time.Local),
+		DateUpdated:   db.DateUpdated.In(time.Local),
+		Version:       db.Version,
 	}
 
 	return bus, nil
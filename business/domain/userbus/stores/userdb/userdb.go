@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/mail"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -51,9 +52,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
 func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 	const q = `
 	INSERT INTO users
-		(user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated)
+		(user_id, name, email, password_hash, roles, department, enabled, email_verified, org_id, date_created, date_updated, version)
 	VALUES
-		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :date_created, :date_updated)`
+		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :email_verified, :org_id, :date_created, :date_updated, :version)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
@@ -65,7 +66,10 @@ func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 	return nil
 }
 
-// Update replaces a user document in the database.
+// Update replaces a user document in the database. The user carries the
+// version it is moving to, so the row is only changed when it is still at the
+// version before that. It will error if the row was changed or deleted in the
+// meantime.
 func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 	const q = `
 	UPDATE
@@ -77,30 +81,63 @@ func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 		"password_hash" = :password_hash,
 		"department" = :department,
 		"enabled" = :enabled,
-		"date_updated" = :date_updated
+		"email_verified" = :email_verified,
+		"date_updated" = :date_updated,
+		"version" = :version
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		version = :version - 1 AND
+		date_deleted IS NULL
+	RETURNING
+		user_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
-		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBUser(usr), &dbUsr); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrDBDuplicatedEntry):
 			return userbus.ErrUniqueEmail
+		case errors.Is(err, sqldb.ErrDBNotFound):
+			return fmt.Errorf("db: %w", userbus.ErrVersionConflict)
 		}
-		return fmt.Errorf("namedexeccontext: %w", err)
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes a user from the database.
-func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+// Delete marks a user as deleted in the database. The row is kept until it
+// is purged so the user can be restored. It will error if the row is no
+// longer at the version of the specified user.
+func (s *Store) Delete(ctx context.Context, usr userbus.User, now time.Time) error {
+	data := struct {
+		ID          string    `db:"user_id"`
+		Version     int       `db:"version"`
+		DateDeleted time.Time `db:"date_deleted"`
+	}{
+		ID:          usr.ID.String(),
+		Version:     usr.Version,
+		DateDeleted: now.UTC(),
+	}
+
 	const q = `
-	DELETE FROM
+	UPDATE
 		users
+	SET
+		"date_deleted" = :date_deleted,
+		"version" = version + 1
 	WHERE
-		user_id = :user_id`
+		user_id = :user_id AND
+		version = :version AND
+		date_deleted IS NULL
+	RETURNING
+		user_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrVersionConflict)
+		}
+		return fmt.Errorf("db: %w", err)
 	}
 
 	return nil
@@ -115,7 +152,7 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 
 	const q = `
 	SELECT
-		user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, org_id, date_created, date_updated, version
 	FROM
 		users`
 
@@ -171,16 +208,20 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, name, email, password_hash, roles, department, enabled, email_verified, org_id, date_created, date_updated, version
 	FROM
 		users
 	WHERE 
-		user_id = :user_id`
+		user_id = :user_id AND
+		date_deleted IS NULL`
 
 	var dbUsr user
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
-		if errors.Is(err, sqldb.ErrDBNotFound) {
+		switch {
+		case errors.Is(err, sqldb.ErrDBNotFound):
 			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+		case errors.Is(err, sqldb.ErrDBDuplicatedEntry):
+			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrUniqueEmail)
 		}
 		return userbus.User{}, fmt.Errorf("db: %w", err)
 	}
@@ -198,11 +239,43 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, name, email, password_hash, roles, department, enabled, email_verified, org_id, date_created, date_updated, version
 	FROM
 		users
 	WHERE
-		email = :email`
+		email = :email AND
+		date_deleted IS NULL`
+
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+		}
+		return userbus.User{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusUser(dbUsr)
+}
+
+// Restore clears the deleted mark from the specified user and returns it.
+func (s *Store) Restore(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		"date_deleted" = NULL,
+		"version" = version + 1
+	WHERE
+		user_id = :user_id AND
+		date_deleted IS NOT NULL
+	RETURNING
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, org_id, date_created, date_updated, version`
 
 	var dbUsr user
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
@@ -214,3 +287,36 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	return toBusUser(dbUsr)
 }
+
+// Purge permanently removes the users deleted before the specified time and
+// returns how many were removed.
+func (s *Store) Purge(ctx context.Context, before time.Time) (int, error) {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before.UTC(),
+	}
+
+	const q = `
+	WITH purged AS (
+		DELETE FROM
+			users
+		WHERE
+			date_deleted < :before
+		RETURNING
+			user_id
+	)
+	SELECT
+		count(1)
+	FROM
+		purged`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -8,6 +8,9 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
@@ -23,6 +26,7 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrVersionConflict       = errors.New("user changed by another request")
 )
 
 // Storer interface declares the behavior this package needs to persist and
@@ -31,26 +35,35 @@ type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, usr User) error
 	Update(ctx context.Context, usr User) error
-	Delete(ctx context.Context, usr User) error
+	Delete(ctx context.Context, usr User, now time.Time) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	Restore(ctx context.Context, userID uuid.UUID) (User, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 // Business manages the set of APIs for user access.
 type Business struct {
-	log      *logger.Logger
-	storer   Storer
-	delegate *delegate.Delegate
+	log       *logger.Logger
+	storer    Storer
+	delegate  *delegate.Delegate
+	outboxBus *outboxbus.Business
+	auditBus  *auditbus.Business
 }
 
-// NewBusiness constructs a user business API for use.
-func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
+// NewBusiness constructs a user business API for use. When an outbox is
+// provided, events are written to it and dispatched by the outbox relay,
+// otherwise the delegate is called directly. When an audit business is
+// provided, every change to a user is recorded in the audit history.
+func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, outboxBus *outboxbus.Business, auditBus *auditbus.Business, storer Storer) *Business {
 	return &Business{
-		log:      log,
-		delegate: delegate,
-		storer:   storer,
+		log:       log,
+		delegate:  delegate,
+		outboxBus: outboxBus,
+		auditBus:  auditBus,
+		storer:    storer,
 	}
 }
 
@@ -62,10 +75,28 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		return nil, err
 	}
 
+	outboxBus := b.outboxBus
+	if outboxBus != nil {
+		outboxBus, err = outboxBus.NewWithTx(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	auditBus := b.auditBus
+	if auditBus != nil {
+		auditBus, err = auditBus.NewWithTx(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	bus := Business{
-		log:      b.log,
-		delegate: b.delegate,
-		storer:   storer,
+		log:       b.log,
+		delegate:  b.delegate,
+		outboxBus: outboxBus,
+		auditBus:  auditBus,
+		storer:    storer,
 	}
 
 	return &bus, nil
@@ -81,24 +112,37 @@ func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
 		return User{}, fmt.Errorf("generatefrompassword: %w", err)
 	}
 
+	// Users created without an organisation belong to the default one.
+	orgID := nu.OrgID
+	if orgID == uuid.Nil {
+		orgID = orgbus.DefaultID
+	}
+
 	now := time.Now()
 
 	usr := User{
-		ID:           uuid.New(),
-		Name:         nu.Name,
-		Email:        nu.Email,
-		PasswordHash: hash,
-		Roles:        nu.Roles,
-		Department:   nu.Department,
-		Enabled:      true,
-		DateCreated:  now,
-		DateUpdated:  now,
+		ID:            uuid.New(),
+		Name:          nu.Name,
+		Email:         nu.Email,
+		PasswordHash:  hash,
+		Roles:         nu.Roles,
+		Department:    nu.Department,
+		Enabled:       true,
+		EmailVerified: true,
+		OrgID:         orgID,
+		DateCreated:   now,
+		DateUpdated:   now,
+		Version:       1,
 	}
 
 	if err := b.storer.Create(ctx, usr); err != nil {
 		return User{}, fmt.Errorf("create: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionCreated, usr, nil, &usr); err != nil {
+		return User{}, err
+	}
+
 	return usr, nil
 }
 
@@ -107,6 +151,8 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 	ctx, span := otel.AddSpan(ctx, "business.userbus.update")
 	defer span.End()
 
+	before := usr
+
 	if uu.Name != nil {
 		usr.Name = *uu.Name
 	}
@@ -135,30 +181,59 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 		usr.Enabled = *uu.Enabled
 	}
 
+	if uu.EmailVerified != nil {
+		usr.EmailVerified = *uu.EmailVerified
+	}
+
 	usr.DateUpdated = time.Now()
+	usr.Version++
 
 	if err := b.storer.Update(ctx, usr); err != nil {
 		return User{}, fmt.Errorf("update: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionUpdated, usr, &before, &usr); err != nil {
+		return User{}, err
+	}
+
 	// Other domains may need to know when a user is updated so business
-	// logic can be applied. This represents a delegate call to other domains.
-	if err := b.delegate.Call(ctx, ActionUpdatedData(uu, usr.ID)); err != nil {
+	// logic can be applied. The event goes through the outbox so it is only
+	// dispatched when the update is committed.
+	data, err := ActionUpdatedData(uu, usr.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("data: %w", err)
+	}
+
+	if err := b.publish(ctx, usr.ID, data); err != nil {
 		return User{}, fmt.Errorf("failed to execute `%s` action: %w", ActionUpdated, err)
 	}
 
 	return usr, nil
 }
 
-// Delete removes the specified user.
+// Delete marks the specified user as deleted. The user can be restored until
+// it is purged. An event is published so other domains can react.
 func (b *Business) Delete(ctx context.Context, usr User) error {
 	ctx, span := otel.AddSpan(ctx, "business.userbus.delete")
 	defer span.End()
 
-	if err := b.storer.Delete(ctx, usr); err != nil {
+	if err := b.storer.Delete(ctx, usr, time.Now()); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if err := b.audit(ctx, auditbus.ActionDeleted, usr, &usr, nil); err != nil {
+		return err
+	}
+
+	data, err := ActionDeletedData(usr.ID)
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	if err := b.publish(ctx, usr.ID, data); err != nil {
+		return fmt.Errorf("failed to execute `%s` action: %w", ActionDeleted, err)
+	}
+
 	return nil
 }
 
@@ -227,3 +302,65 @@ func (b *Business) Authenticate(ctx context.Context, email mail.Address, passwor
 
 	return usr, nil
 }
+
+// =============================================================================
+
+// publish sends the event to the outbox when there is one, otherwise the
+// delegate functions are called before returning.
+func (b *Business) publish(ctx context.Context, userID uuid.UUID, data delegate.Data) error {
+	if b.outboxBus != nil {
+		if _, err := b.outboxBus.Add(ctx, userID, data); err != nil {
+			return fmt.Errorf("outbox: %w", err)
+		}
+		return nil
+	}
+
+	if b.delegate != nil {
+		return b.delegate.Call(ctx, data)
+	}
+
+	return nil
+}
+
+// Restore brings back the specified deleted user. It fails with
+// ErrUniqueEmail when another user has taken the email in the meantime.
+// An event is published so other domains can react.
+func (b *Business) Restore(ctx context.Context, userID uuid.UUID) (User, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.restore")
+	defer span.End()
+
+	usr, err := b.storer.Restore(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("restore: userID[%s]: %w", userID, err)
+	}
+
+	if err := b.audit(ctx, auditbus.ActionRestored, usr, nil, &usr); err != nil {
+		return User{}, err
+	}
+
+	data, err := ActionRestoredData(usr.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("data: %w", err)
+	}
+
+	if err := b.publish(ctx, usr.ID, data); err != nil {
+		return User{}, fmt.Errorf("failed to execute `%s` action: %w", ActionRestored, err)
+	}
+
+	return usr, nil
+}
+
+// Purge permanently removes the users deleted before the specified time,
+// along with their products and homes. It returns how many users were
+// removed.
+func (b *Business) Purge(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.userbus.purge")
+	defer span.End()
+
+	n, err := b.storer.Purge(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+
+	return n, nil
+}
@@ -2,12 +2,14 @@ package userbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/mail"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/orgbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/sdk/page"
@@ -34,6 +36,7 @@ func Test_User(t *testing.T) {
 	unitest.Run(t, create(db.BusDomain), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+	unitest.Run(t, restore(db.BusDomain, sd), "restore")
 }
 
 // =============================================================================
@@ -175,11 +178,14 @@ func create(busDomain dbtest.BusDomain) []unitest.Table {
 		{
 			Name: "basic",
 			ExpResp: userbus.User{
-				Name:       name.MustParse("Bill Kennedy"),
-				Email:      *email,
-				Roles:      []role.Role{role.Admin},
-				Department: name.MustParseNull("ITO"),
-				Enabled:    true,
+				Name:          name.MustParse("Bill Kennedy"),
+				Email:         *email,
+				Roles:         []role.Role{role.Admin},
+				Department:    name.MustParseNull("ITO"),
+				Enabled:       true,
+				EmailVerified: true,
+				OrgID:         orgbus.DefaultID,
+				Version:       1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				nu := userbus.NewUser{
@@ -229,13 +235,16 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 		{
 			Name: "basic",
 			ExpResp: userbus.User{
-				ID:          sd.Users[0].ID,
-				Name:        name.MustParse("Jack Kennedy"),
-				Email:       *email,
-				Roles:       []role.Role{role.Admin},
-				Department:  name.MustParseNull("ITO"),
-				Enabled:     true,
-				DateCreated: sd.Users[0].DateCreated,
+				ID:            sd.Users[0].ID,
+				Name:          name.MustParse("Jack Kennedy"),
+				Email:         *email,
+				Roles:         []role.Role{role.Admin},
+				Department:    name.MustParseNull("ITO"),
+				Enabled:       true,
+				EmailVerified: true,
+				OrgID:         orgbus.DefaultID,
+				DateCreated:   sd.Users[0].DateCreated,
+				Version:       sd.Users[0].Version + 1,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				uu := userbus.UpdateUser{
@@ -271,6 +280,28 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "stale",
+			ExpResp: userbus.ErrVersionConflict,
+			ExcFunc: func(ctx context.Context) any {
+				uu := userbus.UpdateUser{
+					Name: dbtest.NamePointer("Jill Kennedy"),
+				}
+
+				// The basic update moved the user past this version.
+				_, err := busDomain.User.Update(ctx, sd.Users[0].User, uu)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, ok := got.(error)
+				if !ok || !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %v, want %v", got, exp)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
@@ -310,3 +341,76 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 	return table
 }
+
+func restore(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "deleted",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.User.QueryByID(ctx, sd.Users[1].User.ID)
+				return errors.Is(err, userbus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore",
+			ExpResp: sd.Users[1].User.ID,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.User.Restore(ctx, sd.Users[1].User.ID); err != nil {
+					return err
+				}
+
+				usr, err := busDomain.User.QueryByID(ctx, sd.Users[1].User.ID)
+				if err != nil {
+					return err
+				}
+
+				return usr.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "restore-active",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.User.Restore(ctx, sd.Users[1].User.ID)
+				return errors.Is(err, userbus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "purge",
+			ExpResp: 2,
+			ExcFunc: func(ctx context.Context) any {
+				usr, err := busDomain.User.QueryByID(ctx, sd.Users[1].ID)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.User.Delete(ctx, usr); err != nil {
+					return err
+				}
+
+				// The other admin was deleted by the delete tests as well.
+				n, err := busDomain.User.Purge(ctx, time.Now())
+				if err != nil {
+					return err
+				}
+
+				return n
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
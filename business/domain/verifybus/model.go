@@ -0,0 +1,73 @@
+package verifybus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The set of purposes a verification token can be issued for.
+var (
+	PurposeEmailVerify   = newPurpose("EMAIL_VERIFY")
+	PurposePasswordReset = newPurpose("PASSWORD_RESET")
+	PurposeMFAChallenge  = newPurpose("MFA_CHALLENGE")
+)
+
+// Set of known purposes.
+var purposes = make(map[string]Purpose)
+
+// Purpose represents what a verification token can be used for.
+type Purpose struct {
+	value string
+}
+
+func newPurpose(purpose string) Purpose {
+	p := Purpose{purpose}
+	purposes[purpose] = p
+	return p
+}
+
+// String returns the name of the purpose.
+func (p Purpose) String() string {
+	return p.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Purpose) Equal(p2 Purpose) bool {
+	return p.value == p2.value
+}
+
+// ParsePurpose parses the string value and returns a purpose if one exists.
+func ParsePurpose(value string) (Purpose, error) {
+	p, exists := purposes[value]
+	if !exists {
+		return Purpose{}, fmt.Errorf("invalid purpose %q", value)
+	}
+
+	return p, nil
+}
+
+// =============================================================================
+
+// Token represents a single use verification token. Only the hash of the
+// token is stored, the raw token is only ever sent to the user.
+type Token struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Purpose     Purpose
+	Hash        string
+	DateExpires time.Time
+	DateCreated time.Time
+	DateUsed    time.Time
+}
+
+// Used reports if the token has already been consumed.
+func (t Token) Used() bool {
+	return !t.DateUsed.IsZero()
+}
+
+// Expired reports if the token has expired as of the specified time.
+func (t Token) Expired(now time.Time) bool {
+	return !now.Before(t.DateExpires)
+}
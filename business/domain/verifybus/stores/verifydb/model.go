@@ -0,0 +1,57 @@
+package verifydb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/google/uuid"
+)
+
+type token struct {
+	ID          uuid.UUID    `db:"token_id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Purpose     string       `db:"purpose"`
+	Hash        string       `db:"token_hash"`
+	DateExpires time.Time    `db:"date_expires"`
+	DateCreated time.Time    `db:"date_created"`
+	DateUsed    sql.NullTime `db:"date_used"`
+}
+
+func toDBToken(bus verifybus.Token) token {
+	return token{
+		ID:          bus.ID,
+		UserID:      bus.UserID,
+		Purpose:     bus.Purpose.String(),
+		Hash:        bus.Hash,
+		DateExpires: bus.DateExpires.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUsed: sql.NullTime{
+			Time:  bus.DateUsed.UTC(),
+			Valid: !bus.DateUsed.IsZero(),
+		},
+	}
+}
+
+func toBusToken(db token) (verifybus.Token, error) {
+	purpose, err := verifybus.ParsePurpose(db.Purpose)
+	if err != nil {
+		return verifybus.Token{}, fmt.Errorf("parse purpose: %w", err)
+	}
+
+	bus := verifybus.Token{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		Purpose:     purpose,
+		Hash:        db.Hash,
+		DateExpires: db.DateExpires.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	if db.DateUsed.Valid {
+		bus.DateUsed = db.DateUsed.Time.In(time.Local)
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,145 @@
+// Package verifydb contains verification token related CRUD functionality.
+package verifydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for verification token database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (verifybus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new verification token into the database.
+func (s *Store) Create(ctx context.Context, tkn verifybus.Token) error {
+	const q = `
+	INSERT INTO verification_tokens
+		(token_id, user_id, purpose, token_hash, date_expires, date_created, date_used)
+	VALUES
+		(:token_id, :user_id, :purpose, :token_hash, :date_expires, :date_created, :date_used)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBToken(tkn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUsed marks the token as used. The update only succeeds if the token
+// has not already been used, which prevents the token from being consumed
+// twice by concurrent requests.
+func (s *Store) MarkUsed(ctx context.Context, tkn verifybus.Token) error {
+	const q = `
+	UPDATE
+		verification_tokens
+	SET
+		date_used = :date_used
+	WHERE
+		token_id = :token_id AND
+		date_used IS NULL
+	RETURNING
+		token_id, user_id, purpose, token_hash, date_expires, date_created, date_used`
+
+	var dbTkn token
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBToken(tkn), &dbTkn); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", verifybus.ErrUsed)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateUser marks all unused tokens for the user and purpose as used.
+func (s *Store) InvalidateUser(ctx context.Context, userID uuid.UUID, purpose verifybus.Purpose, now time.Time) error {
+	data := struct {
+		UserID   string    `db:"user_id"`
+		Purpose  string    `db:"purpose"`
+		DateUsed time.Time `db:"date_used"`
+	}{
+		UserID:   userID.String(),
+		Purpose:  purpose.String(),
+		DateUsed: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		verification_tokens
+	SET
+		date_used = :date_used
+	WHERE
+		user_id = :user_id AND
+		purpose = :purpose AND
+		date_used IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByHash gets the specified verification token from the database.
+func (s *Store) QueryByHash(ctx context.Context, hash string, purpose verifybus.Purpose) (verifybus.Token, error) {
+	data := struct {
+		Hash    string `db:"token_hash"`
+		Purpose string `db:"purpose"`
+	}{
+		Hash:    hash,
+		Purpose: purpose.String(),
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, purpose, token_hash, date_expires, date_created, date_used
+	FROM
+		verification_tokens
+	WHERE
+		token_hash = :token_hash AND
+		purpose = :purpose`
+
+	var dbTkn token
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTkn); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return verifybus.Token{}, fmt.Errorf("db: %w", verifybus.ErrNotFound)
+		}
+		return verifybus.Token{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusToken(dbTkn)
+}
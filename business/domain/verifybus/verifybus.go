@@ -0,0 +1,149 @@
+// Package verifybus provides business access to single use verification
+// tokens, such as the ones used to verify an email address or to reset a
+// forgotten password.
+package verifybus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for verification token operations.
+var (
+	ErrNotFound = errors.New("verification token not found")
+	ErrExpired  = errors.New("verification token expired")
+	ErrUsed     = errors.New("verification token already used")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, tkn Token) error
+	MarkUsed(ctx context.Context, tkn Token) error
+	InvalidateUser(ctx context.Context, userID uuid.UUID, purpose Purpose, now time.Time) error
+	QueryByHash(ctx context.Context, hash string, purpose Purpose) (Token, error)
+}
+
+// Business manages the set of APIs for verification token access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a verification token business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create issues a new token for the specified user and purpose. Any tokens
+// previously issued to the user for the same purpose are invalidated. The
+// raw token is returned and is never stored.
+func (b *Business) Create(ctx context.Context, userID uuid.UUID, purpose Purpose, ttl time.Duration) (string, Token, error) {
+	ctx, span := otel.AddSpan(ctx, "business.verifybus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	if err := b.storer.InvalidateUser(ctx, userID, purpose, now); err != nil {
+		return "", Token{}, fmt.Errorf("invalidateuser: %w", err)
+	}
+
+	raw, err := generateToken()
+	if err != nil {
+		return "", Token{}, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	tkn := Token{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Purpose:     purpose,
+		Hash:        hashToken(raw),
+		DateExpires: now.Add(ttl),
+		DateCreated: now,
+	}
+
+	if err := b.storer.Create(ctx, tkn); err != nil {
+		return "", Token{}, fmt.Errorf("create: %w", err)
+	}
+
+	return raw, tkn, nil
+}
+
+// Consume validates the raw token for the specified purpose and marks it as
+// used so it can't be presented again.
+func (b *Business) Consume(ctx context.Context, raw string, purpose Purpose) (Token, error) {
+	ctx, span := otel.AddSpan(ctx, "business.verifybus.consume")
+	defer span.End()
+
+	tkn, err := b.storer.QueryByHash(ctx, hashToken(raw), purpose)
+	if err != nil {
+		return Token{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if tkn.Used() {
+		return Token{}, ErrUsed
+	}
+
+	if tkn.Expired(now) {
+		return Token{}, ErrExpired
+	}
+
+	tkn.DateUsed = now
+
+	if err := b.storer.MarkUsed(ctx, tkn); err != nil {
+		return Token{}, fmt.Errorf("markused: %w", err)
+	}
+
+	return tkn, nil
+}
+
+// =============================================================================
+
+// generateToken produces a random, url safe token with 256 bits of entropy.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken produces the value that is stored for a raw token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package verifybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Verify(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Verify")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, consume(db.BusDomain, sd), "consume")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}},
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func consume(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	cmpErr := func(got any, exp any) string {
+		err, ok := got.(error)
+		if !ok || !errors.Is(err, exp.(error)) {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: sd.Users[0].ID,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposeEmailVerify, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				tkn, err := busDomain.Verify.Consume(ctx, raw, verifybus.PurposeEmailVerify)
+				if err != nil {
+					return err
+				}
+
+				return tkn.UserID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "used",
+			ExpResp: verifybus.ErrUsed,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposePasswordReset, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Verify.Consume(ctx, raw, verifybus.PurposePasswordReset); err != nil {
+					return err
+				}
+
+				_, err = busDomain.Verify.Consume(ctx, raw, verifybus.PurposePasswordReset)
+
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "purpose",
+			ExpResp: verifybus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposeEmailVerify, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Verify.Consume(ctx, raw, verifybus.PurposePasswordReset)
+
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "expired",
+			ExpResp: verifybus.ErrExpired,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposeEmailVerify, -time.Minute)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Verify.Consume(ctx, raw, verifybus.PurposeEmailVerify)
+
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "superseded",
+			ExpResp: verifybus.ErrUsed,
+			ExcFunc: func(ctx context.Context) any {
+				raw, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposeEmailVerify, time.Hour)
+				if err != nil {
+					return err
+				}
+
+				if _, _, err := busDomain.Verify.Create(ctx, sd.Users[0].ID, verifybus.PurposeEmailVerify, time.Hour); err != nil {
+					return err
+				}
+
+				_, err = busDomain.Verify.Consume(ctx, raw, verifybus.PurposeEmailVerify)
+
+				return err
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
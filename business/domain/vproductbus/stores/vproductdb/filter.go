@@ -16,6 +16,11 @@ func (s *Store) applyFilter(filter vproductbus.QueryFilter, data map[string]any,
 		wc = append(wc, "product_id = :product_id")
 	}
 
+	if filter.OrgID != nil {
+		data["org_id"] = *filter.OrgID
+		wc = append(wc, "org_id = :org_id")
+	}
+
 	if filter.Name != nil {
 		data["name"] = fmt.Sprintf("%%%s%%", *filter.Name)
 		wc = append(wc, "name LIKE :name")
@@ -3,13 +3,33 @@ package dbtest
 import (
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/apikeybus"
+	"github.com/ardanlabs/service/business/domain/apikeybus/stores/apikeydb"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/identitybus"
+	"github.com/ardanlabs/service/business/domain/identitybus/stores/identitydb"
+	"github.com/ardanlabs/service/business/domain/lockoutbus"
+	"github.com/ardanlabs/service/business/domain/lockoutbus/stores/lockoutdb"
+	"github.com/ardanlabs/service/business/domain/orgbus"
+	"github.com/ardanlabs/service/business/domain/orgbus/stores/orgdb"
+	"github.com/ardanlabs/service/business/domain/outboxbus"
+	"github.com/ardanlabs/service/business/domain/outboxbus/stores/outboxdb"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/service/business/domain/rolebus"
+	"github.com/ardanlabs/service/business/domain/rolebus/stores/roledb"
+	"github.com/ardanlabs/service/business/domain/sessionbus"
+	"github.com/ardanlabs/service/business/domain/sessionbus/stores/sessiondb"
+	"github.com/ardanlabs/service/business/domain/tokenbus"
+	"github.com/ardanlabs/service/business/domain/tokenbus/stores/tokendb"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/verifybus"
+	"github.com/ardanlabs/service/business/domain/verifybus/stores/verifydb"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
 	"github.com/ardanlabs/service/business/sdk/delegate"
@@ -19,25 +39,55 @@ import (
 
 // BusDomain represents all the business domain apis needed for testing.
 type BusDomain struct {
+	APIKey   *apikeybus.Business
+	Audit    *auditbus.Business
 	Delegate *delegate.Delegate
 	Home     *homebus.Business
+	Identity *identitybus.Business
+	Lockout  *lockoutbus.Business
+	Org      *orgbus.Business
+	Outbox   *outboxbus.Business
 	Product  *productbus.Business
+	Role     *rolebus.Business
+	Session  *sessionbus.Business
+	Token    *tokenbus.Business
 	User     *userbus.Business
+	Verify   *verifybus.Business
 	VProduct *vproductbus.Business
 }
 
 func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
 	delegate := delegate.New(log)
-	userBus := userbus.NewBusiness(log, delegate, usercache.NewStore(log, userdb.NewStore(log, db), time.Hour))
-	productBus := productbus.NewBusiness(log, userBus, delegate, productdb.NewStore(log, db))
-	homeBus := homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db))
+	outboxBus := outboxbus.NewBusiness(log, outboxdb.NewStore(log, db))
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, delegate, outboxBus, auditBus, usercache.NewStore(log, userdb.NewStore(log, db), time.Hour))
+	apiKeyBus := apikeybus.NewBusiness(log, userBus, apikeydb.NewStore(log, db))
+	identityBus := identitybus.NewBusiness(log, identitybus.Config{TrustedProviders: []string{"google"}}, userBus, identitydb.NewStore(log, db))
+	lockoutBus := lockoutbus.NewBusiness(log, lockoutbus.DefaultConfig, lockoutdb.NewStore(log, db))
+	orgBus := orgbus.NewBusiness(log, orgdb.NewStore(log, db))
+	productBus := productbus.NewBusiness(log, userBus, delegate, auditBus, productdb.NewStore(log, db))
+	homeBus := homebus.NewBusiness(log, userBus, delegate, auditBus, homedb.NewStore(log, db))
+	roleBus := rolebus.NewBusiness(log, roledb.NewStore(log, db))
+	tokenBus := tokenbus.NewBusiness(log, tokendb.NewStore(log, db))
+	sessionBus := sessionbus.NewBusiness(log, tokenBus, sessiondb.NewStore(log, db))
+	verifyBus := verifybus.NewBusiness(log, verifydb.NewStore(log, db))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(log, db))
 
 	return BusDomain{
+		APIKey:   apiKeyBus,
+		Audit:    auditBus,
 		Delegate: delegate,
 		Home:     homeBus,
+		Identity: identityBus,
+		Lockout:  lockoutBus,
+		Org:      orgBus,
+		Outbox:   outboxBus,
 		Product:  productBus,
+		Role:     roleBus,
+		Session:  sessionBus,
+		Token:    tokenBus,
 		User:     userBus,
+		Verify:   verifyBus,
 		VProduct: vproductBus,
 	}
 }
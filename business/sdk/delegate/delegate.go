@@ -4,8 +4,12 @@ package delegate
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/worker"
 )
 
 // These types are just for documentation so we know what keys go
@@ -15,51 +19,223 @@ type (
 	action string
 )
 
+// handler is a registered function and how it is to be executed.
+type handler struct {
+	fn    Func
+	async bool
+}
+
 // Delegate manages the set of functions to be called by domain
 // packages when an import is not possible.
 type Delegate struct {
-	log   *logger.Logger
-	funcs map[domain]map[action][]Func
+	log      *logger.Logger
+	worker   *worker.Worker
+	asyncCfg AsyncConfig
+	funcs    map[domain]map[action][]handler
 }
 
 // New constructs a delegate for indirect api access.
-func New(log *logger.Logger) *Delegate {
-	return &Delegate{
+func New(log *logger.Logger, options ...func(d *Delegate)) *Delegate {
+	d := Delegate{
 		log:   log,
-		funcs: make(map[domain]map[action][]Func),
+		funcs: make(map[domain]map[action][]handler),
+	}
+
+	for _, option := range options {
+		option(&d)
+	}
+
+	return &d
+}
+
+// WithAsync provides the worker used to execute the functions registered
+// with RegisterAsync. The number of functions executing at the same time is
+// bounded by the capacity of the worker, and a caller waits up to SlotWait
+// for a free slot when they are all in use. Without a worker, those functions
+// are executed synchronously like any other. A zero config uses
+// DefaultAsyncConfig.
+func WithAsync(w *worker.Worker, cfg AsyncConfig) func(d *Delegate) {
+	if cfg == (AsyncConfig{}) {
+		cfg = DefaultAsyncConfig
+	}
+
+	if cfg.SlotWait <= 0 {
+		cfg.SlotWait = DefaultAsyncConfig.SlotWait
+	}
+
+	return func(d *Delegate) {
+		d.worker = w
+		d.asyncCfg = cfg
 	}
 }
 
 // Register adds a function to be called for a specified domain and action.
+// The function is executed synchronously on the G making the call, so it
+// runs inside the caller's request and transaction.
 func (d *Delegate) Register(domainType string, actionType string, fn Func) {
-	aMap, ok := d.funcs[domain(domainType)]
-	if !ok {
-		aMap = make(map[action][]Func)
-		d.funcs[domain(domainType)] = aMap
-	}
+	d.register(domainType, actionType, handler{fn: fn})
+}
 
-	funcs := aMap[action(actionType)]
-	funcs = append(funcs, fn)
-	aMap[action(actionType)] = funcs
+// RegisterAsync adds a function to be called for a specified domain and
+// action that is executed as a worker job. The caller doesn't wait for the
+// function, which is given a timeout for every attempt and is retried with
+// backoff when it fails.
+func (d *Delegate) RegisterAsync(domainType string, actionType string, fn Func) {
+	d.register(domainType, actionType, handler{fn: fn, async: true})
 }
 
 // Call executes all functions registered for the specified domain and
-// action. These functions are executed synchronously on the G making the call.
+// action. Synchronous functions are executed on the G making the call and
+// asynchronous functions are scheduled on the worker. Errors reported by the
+// functions are logged and not returned.
 func (d *Delegate) Call(ctx context.Context, data Data) error {
-	d.log.Info(ctx, "delegate call", "status", "started", "domain", data.Domain, "action", data.Action, "params", data.RawParams)
+	d.log.Info(ctx, "delegate call", "status", "started", "domain", data.Domain, "action", data.Action, "version", data.Version, "params", data.RawParams)
 	defer d.log.Info(ctx, "delegate call", "status", "completed")
 
-	if dMap, ok := d.funcs[domain(data.Domain)]; ok {
-		if funcs, ok := dMap[action(data.Action)]; ok {
-			for _, fn := range funcs {
-				d.log.Info(ctx, "delegate call", "status", "sending")
+	for _, h := range d.lookup(data) {
+		if h.async && d.worker != nil {
+			d.schedule(ctx, h.fn, data)
+			continue
+		}
 
-				if err := fn(ctx, data); err != nil {
-					d.log.Error(ctx, "delegate call", "err", err)
-				}
-			}
+		d.log.Info(ctx, "delegate call", "status", "sending")
+
+		if err := h.fn(ctx, data); err != nil {
+			d.log.Error(ctx, "delegate call", "err", err)
 		}
 	}
 
 	return nil
 }
+
+// Dispatch executes all functions registered for the specified domain and
+// action and returns the errors they report so the caller can retry the
+// event. It is meant for callers that already run in the background, like
+// the outbox relay, so asynchronous functions are executed on the G making
+// the call with the per attempt timeout, and their failures are retried and
+// dead lettered with the event. A retried event runs every function again,
+// so the functions must tolerate seeing the same event more than once.
+func (d *Delegate) Dispatch(ctx context.Context, data Data) error {
+	d.log.Info(ctx, "delegate dispatch", "status", "started", "domain", data.Domain, "action", data.Action, "version", data.Version, "params", data.RawParams)
+	defer d.log.Info(ctx, "delegate dispatch", "status", "completed")
+
+	var errs []error
+	for _, h := range d.lookup(data) {
+		var err error
+		switch {
+		case h.async && d.worker != nil:
+			err = d.attempt(ctx, h.fn, data)
+		default:
+			err = h.fn(ctx, data)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// =============================================================================
+
+func (d *Delegate) register(domainType string, actionType string, h handler) {
+	aMap, ok := d.funcs[domain(domainType)]
+	if !ok {
+		aMap = make(map[action][]handler)
+		d.funcs[domain(domainType)] = aMap
+	}
+
+	aMap[action(actionType)] = append(aMap[action(actionType)], h)
+}
+
+func (d *Delegate) lookup(data Data) []handler {
+	dMap, ok := d.funcs[domain(data.Domain)]
+	if !ok {
+		return nil
+	}
+
+	return dMap[action(data.Action)]
+}
+
+// schedule starts a worker job for the function. The job isn't tied to the
+// caller's cancellation, but keeps its values for logging and tracing. The
+// caller only waits SlotWait for a free slot, and the job is then given
+// enough time for every attempt and the backoff between them.
+func (d *Delegate) schedule(ctx context.Context, fn Func, data Data) {
+	values := context.WithoutCancel(ctx)
+
+	job := func(jobCtx context.Context) {
+		defer m.running.Add(-1)
+
+		// The worker cancels the job context on shutdown and when the time
+		// given to the job is up.
+		ctx, cancel := context.WithCancel(values)
+		defer cancel()
+
+		stop := context.AfterFunc(jobCtx, cancel)
+		defer stop()
+
+		if err := d.execute(ctx, fn, data); err != nil {
+			m.failed.Add(1)
+			d.log.Error(ctx, "delegate async", "status", "failed", "domain", data.Domain, "action", data.Action, "err", err)
+			return
+		}
+
+		m.succeeded.Add(1)
+	}
+
+	ctx, cancel := context.WithTimeout(values, d.asyncCfg.SlotWait)
+	defer cancel()
+
+	m.running.Add(1)
+
+	if _, err := d.worker.StartFor(ctx, d.asyncCfg.budget(), job); err != nil {
+		m.running.Add(-1)
+		m.dropped.Add(1)
+		d.log.Error(ctx, "delegate async", "status", "dropped", "domain", data.Domain, "action", data.Action, "err", err)
+		return
+	}
+
+	m.scheduled.Add(1)
+}
+
+// execute runs the function until it succeeds or runs out of attempts.
+func (d *Delegate) execute(ctx context.Context, fn Func, data Data) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = d.attempt(ctx, fn, data)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= d.asyncCfg.MaxAttempts {
+			return fmt.Errorf("attempts[%d]: %w", attempt, err)
+		}
+
+		m.retried.Add(1)
+
+		delay := d.asyncCfg.backoff(attempt)
+		d.log.Info(ctx, "delegate async", "status", "retry", "domain", data.Domain, "action", data.Action, "attempt", attempt, "delay", delay, "err", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("attempts[%d]: %w: %w", attempt, ctx.Err(), err)
+		}
+	}
+}
+
+// attempt runs the function once within the per attempt timeout.
+func (d *Delegate) attempt(ctx context.Context, fn Func, data Data) error {
+	ctx, cancel := context.WithTimeout(ctx, d.asyncCfg.Timeout)
+	defer cancel()
+
+	err := fn(ctx, data)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		m.timeouts.Add(1)
+	}
+
+	return err
+}
@@ -0,0 +1,161 @@
+package delegate_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/worker"
+)
+
+func Test_Async(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	w, err := worker.New(2)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker : %s", err)
+	}
+
+	cfg := delegate.AsyncConfig{
+		Timeout:     50 * time.Millisecond,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+
+	d := delegate.New(log, delegate.WithAsync(w, cfg))
+
+	var syncCalls, flakyCalls, slowCalls atomic.Int32
+
+	d.Register("test", "run", func(ctx context.Context, data delegate.Data) error {
+		syncCalls.Add(1)
+		return nil
+	})
+
+	// Fails until the last attempt.
+	d.RegisterAsync("test", "run", func(ctx context.Context, data delegate.Data) error {
+		if flakyCalls.Add(1) < int32(cfg.MaxAttempts) {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	// Never finishes within the timeout.
+	d.RegisterAsync("test", "run", func(ctx context.Context, data delegate.Data) error {
+		slowCalls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := d.Call(context.Background(), delegate.Data{Domain: "test", Action: "run"}); err != nil {
+		t.Fatalf("Should be able to call the delegate : %s", err)
+	}
+
+	if syncCalls.Load() != 1 {
+		t.Fatalf("Should run the synchronous function before returning : %d", syncCalls.Load())
+	}
+
+	// Shutdown cancels running jobs, so wait for them to finish first.
+	for deadline := time.Now().Add(5 * time.Second); w.Running() > 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Should be able to wait for the jobs : %d running", w.Running())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := flakyCalls.Load(); n != int32(cfg.MaxAttempts) {
+		t.Errorf("Should retry a failing function until it succeeds : got %d, exp %d", n, cfg.MaxAttempts)
+	}
+
+	if n := slowCalls.Load(); n != int32(cfg.MaxAttempts) {
+		t.Errorf("Should retry a function that times out : got %d, exp %d", n, cfg.MaxAttempts)
+	}
+}
+
+func Test_DispatchAsync(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	w, err := worker.New(1)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker : %s", err)
+	}
+
+	cfg := delegate.AsyncConfig{
+		Timeout:     50 * time.Millisecond,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+
+	d := delegate.New(log, delegate.WithAsync(w, cfg))
+
+	var calls atomic.Int32
+
+	d.RegisterAsync("test", "run", func(ctx context.Context, data delegate.Data) error {
+		calls.Add(1)
+		return errors.New("failed")
+	})
+
+	// The relay retries and dead letters the event, so the failure has to
+	// be reported back after a single attempt.
+	if err := d.Dispatch(context.Background(), delegate.Data{Domain: "test", Action: "run"}); err == nil {
+		t.Fatal("Should get the error of the asynchronous function")
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Should run the asynchronous function once : got %d", n)
+	}
+
+	if n := w.Running(); n != 0 {
+		t.Errorf("Should not start a worker job : got %d", n)
+	}
+}
+
+func Test_SlotWait(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	w, err := worker.New(1)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker : %s", err)
+	}
+
+	cfg := delegate.AsyncConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Second,
+		SlotWait:    20 * time.Millisecond,
+	}
+
+	d := delegate.New(log, delegate.WithAsync(w, cfg))
+
+	release := make(chan struct{})
+
+	d.RegisterAsync("test", "run", func(ctx context.Context, data delegate.Data) error {
+		<-release
+		return nil
+	})
+
+	data := delegate.Data{Domain: "test", Action: "run"}
+
+	// The first call takes the only slot, the second has to give up.
+	d.Call(context.Background(), data)
+
+	start := time.Now()
+	d.Call(context.Background(), data)
+
+	if since := time.Since(start); since > time.Second {
+		t.Errorf("Should only wait for the slot wait when the worker is busy : waited %v", since)
+	}
+
+	close(release)
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown the worker : %s", err)
+	}
+}
@@ -0,0 +1,215 @@
+package delegate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Upcast converts the params of an event at the From version into the params
+// of the next version.
+type Upcast struct {
+	From int
+	Fn   func(raw []byte) ([]byte, error)
+}
+
+// Event defines a typed event with a versioned params schema. Data produced
+// by the event carries the current version, and data carrying an older
+// version is upcast one version at a time before it is decoded.
+type Event[T any] struct {
+	domain  string
+	action  string
+	version int
+	upcasts map[int]Upcast
+}
+
+// NewEvent defines an event for the domain and action whose params are of
+// type T at the specified version. Every event is recorded in the registry
+// returned by Events. Defining the same domain and action twice panics since
+// it is a programming error.
+func NewEvent[T any](domainType string, actionType string, version int, upcasts ...Upcast) Event[T] {
+	if version < 1 {
+		panic(fmt.Sprintf("delegate: event %s.%s: version must be at least 1", domainType, actionType))
+	}
+
+	evt := Event[T]{
+		domain:  domainType,
+		action:  actionType,
+		version: version,
+		upcasts: make(map[int]Upcast, len(upcasts)),
+	}
+
+	from := make([]int, 0, len(upcasts))
+	for _, uc := range upcasts {
+		if uc.From < 1 || uc.From >= version {
+			panic(fmt.Sprintf("delegate: event %s.%s: upcast from version %d is out of range", domainType, actionType, uc.From))
+		}
+		evt.upcasts[uc.From] = uc
+		from = append(from, uc.From)
+	}
+
+	slices.Sort(from)
+
+	register(EventInfo{
+		Domain:      domainType,
+		Action:      actionType,
+		Version:     version,
+		Params:      reflect.TypeFor[T](),
+		UpcastsFrom: from,
+	})
+
+	return evt
+}
+
+// Domain returns the domain of the event.
+func (e Event[T]) Domain() string {
+	return e.domain
+}
+
+// Action returns the action of the event.
+func (e Event[T]) Action() string {
+	return e.action
+}
+
+// Version returns the current version of the params.
+func (e Event[T]) Version() int {
+	return e.version
+}
+
+// Data constructs the data for the event with the params encoded as JSON.
+func (e Event[T]) Data(params T) (Data, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return Data{}, fmt.Errorf("marshal: %w", err)
+	}
+
+	data := Data{
+		Domain:    e.domain,
+		Action:    e.action,
+		Version:   e.version,
+		RawParams: raw,
+	}
+
+	return data, nil
+}
+
+// Decode upcasts the params of the data to the current version and decodes
+// them. Data without a version predates versioning and is treated as
+// version 1.
+func (e Event[T]) Decode(data Data) (T, error) {
+	var params T
+
+	if data.Domain != e.domain || data.Action != e.action {
+		return params, fmt.Errorf("event %s.%s: got %s.%s", e.domain, e.action, data.Domain, data.Action)
+	}
+
+	version := max(data.Version, 1)
+	if version > e.version {
+		return params, fmt.Errorf("event %s.%s: version %d is newer than %d", e.domain, e.action, version, e.version)
+	}
+
+	raw := data.RawParams
+	for ; version < e.version; version++ {
+		uc, exists := e.upcasts[version]
+		if !exists {
+			return params, fmt.Errorf("event %s.%s: no upcast from version %d", e.domain, e.action, version)
+		}
+
+		var err error
+		if raw, err = uc.Fn(raw); err != nil {
+			return params, fmt.Errorf("event %s.%s: upcast from version %d: %w", e.domain, e.action, version, err)
+		}
+	}
+
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return params, fmt.Errorf("event %s.%s: expected an encoded %T: %w", e.domain, e.action, params, err)
+	}
+
+	return params, nil
+}
+
+// adapt converts a typed function into a function that decodes the data first.
+func (e Event[T]) adapt(fn func(context.Context, T) error) Func {
+	return func(ctx context.Context, data Data) error {
+		params, err := e.Decode(data)
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx, params)
+	}
+}
+
+// Register adds a typed function to be called for the event. The function
+// is executed synchronously on the G making the call.
+func Register[T any](d *Delegate, evt Event[T], fn func(context.Context, T) error) {
+	d.Register(evt.domain, evt.action, evt.adapt(fn))
+}
+
+// RegisterAsync adds a typed function to be called for the event that is
+// executed as a worker job.
+func RegisterAsync[T any](d *Delegate, evt Event[T], fn func(context.Context, T) error) {
+	d.RegisterAsync(evt.domain, evt.action, evt.adapt(fn))
+}
+
+// =============================================================================
+
+// EventInfo describes an event that was defined with NewEvent.
+type EventInfo struct {
+	Domain      string
+	Action      string
+	Version     int
+	Params      reflect.Type
+	UpcastsFrom []int
+}
+
+// String implements the Stringer interface.
+func (ei EventInfo) String() string {
+	return fmt.Sprintf("%s.%s v%d %s", ei.Domain, ei.Action, ei.Version, ei.Params)
+}
+
+// registry holds every event defined in the program. Events are defined
+// by package level variables, so this is populated during initialization.
+var registry = struct {
+	mu     sync.RWMutex
+	events map[string]EventInfo
+}{
+	events: make(map[string]EventInfo),
+}
+
+func register(ei EventInfo) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	key := ei.Domain + "." + ei.Action
+	if _, exists := registry.events[key]; exists {
+		panic(fmt.Sprintf("delegate: event %s is already defined", key))
+	}
+
+	registry.events[key] = ei
+}
+
+// Events returns every event defined in the program ordered by domain and
+// action. It is meant for documentation and contract tests.
+func Events() []EventInfo {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	events := make([]EventInfo, 0, len(registry.events))
+	for _, ei := range registry.events {
+		events = append(events, ei)
+	}
+
+	slices.SortFunc(events, func(a, b EventInfo) int {
+		if c := strings.Compare(a.Domain, b.Domain); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Action, b.Action)
+	})
+
+	return events
+}
@@ -0,0 +1,78 @@
+package delegate_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+type renamedParams struct {
+	FullName string `json:"fullName"`
+}
+
+// eventRenamed is at version 2, where the name field was renamed.
+var eventRenamed = delegate.NewEvent[renamedParams]("test", "renamed", 2, delegate.Upcast{
+	From: 1,
+	Fn: func(raw []byte) ([]byte, error) {
+		return bytes.Replace(raw, []byte(`"name"`), []byte(`"fullName"`), 1), nil
+	},
+})
+
+func Test_Event(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	d := delegate.New(log)
+
+	var got []string
+	delegate.Register(d, eventRenamed, func(ctx context.Context, params renamedParams) error {
+		got = append(got, params.FullName)
+		return nil
+	})
+
+	data, err := eventRenamed.Data(renamedParams{FullName: "current"})
+	if err != nil {
+		t.Fatalf("Should be able to construct the data : %s", err)
+	}
+
+	if data.Version != 2 {
+		t.Fatalf("Should carry the current version : %d", data.Version)
+	}
+
+	// Data written before the rename and before versioning are both upcast.
+	old := []delegate.Data{
+		{Domain: "test", Action: "renamed", Version: 1, RawParams: []byte(`{"name":"v1"}`)},
+		{Domain: "test", Action: "renamed", RawParams: []byte(`{"name":"unversioned"}`)},
+	}
+
+	for _, data := range append([]delegate.Data{data}, old...) {
+		if err := d.Dispatch(context.Background(), data); err != nil {
+			t.Fatalf("Should be able to dispatch %s : %s", data, err)
+		}
+	}
+
+	exp := []string{"current", "v1", "unversioned"}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("Should decode every version : got %v, exp %v", got, exp)
+	}
+
+	newer := delegate.Data{Domain: "test", Action: "renamed", Version: 3, RawParams: []byte(`{}`)}
+	if err := d.Dispatch(context.Background(), newer); err == nil {
+		t.Fatalf("Should NOT decode a version newer than the definition")
+	}
+
+	var found bool
+	for _, ei := range delegate.Events() {
+		if ei.Domain == "test" && ei.Action == "renamed" {
+			found = ei.Version == 2 && ei.Params == reflect.TypeFor[renamedParams]() && reflect.DeepEqual(ei.UpcastsFrom, []int{1})
+		}
+	}
+
+	if !found {
+		t.Fatalf("Should list the event in the registry : %v", delegate.Events())
+	}
+}
@@ -0,0 +1,35 @@
+package delegate
+
+import "expvar"
+
+// m holds the metrics for asynchronous functions. The expvar package is
+// based on a singleton, so every delegate in the process shares them.
+var m = struct {
+	scheduled *expvar.Int
+	running   *expvar.Int
+	succeeded *expvar.Int
+	retried   *expvar.Int
+	timeouts  *expvar.Int
+	failed    *expvar.Int
+	dropped   *expvar.Int
+}{
+	scheduled: new(expvar.Int),
+	running:   new(expvar.Int),
+	succeeded: new(expvar.Int),
+	retried:   new(expvar.Int),
+	timeouts:  new(expvar.Int),
+	failed:    new(expvar.Int),
+	dropped:   new(expvar.Int),
+}
+
+// init publishes the metrics under the delegate key.
+func init() {
+	vars := expvar.NewMap("delegate")
+	vars.Set("scheduled", m.scheduled)
+	vars.Set("running", m.running)
+	vars.Set("succeeded", m.succeeded)
+	vars.Set("retried", m.retried)
+	vars.Set("timeouts", m.timeouts)
+	vars.Set("failed", m.failed)
+	vars.Set("dropped", m.dropped)
+}
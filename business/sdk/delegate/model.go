@@ -3,22 +3,67 @@ package delegate
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // Func represents a function that is registered and called by the system.
 type Func func(context.Context, Data) error
 
-// Data represents an event between domains.
+// Data represents an event between domains. Version is the version of the
+// params schema, where zero means the data predates versioning.
 type Data struct {
 	Domain    string
 	Action    string
+	Version   int
 	RawParams []byte
 }
 
 // String implements the Stringer interface.
 func (d Data) String() string {
 	return fmt.Sprintf(
-		"Event{Domain:%#v, Action:%#v, RawParams:%#v}",
-		d.Domain, d.Action, string(d.RawParams),
+		"Event{Domain:%#v, Action:%#v, Version:%d, RawParams:%#v}",
+		d.Domain, d.Action, d.Version, string(d.RawParams),
 	)
 }
+
+// AsyncConfig represents the settings used to execute asynchronous
+// functions. Every attempt is given the Timeout to complete and the backoff
+// between attempts doubles from BaseBackoff up to MaxBackoff. A caller waits
+// up to SlotWait for a free worker before the function is dropped.
+type AsyncConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	SlotWait    time.Duration
+}
+
+// DefaultAsyncConfig provides reasonable settings for asynchronous functions.
+var DefaultAsyncConfig = AsyncConfig{
+	Timeout:     10 * time.Second,
+	MaxAttempts: 3,
+	BaseBackoff: 500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	SlotWait:    100 * time.Millisecond,
+}
+
+// backoff returns how long to wait before the next attempt after the
+// specified number of failed attempts.
+func (cfg AsyncConfig) backoff(attempts int) time.Duration {
+	d := cfg.BaseBackoff
+	for i := 1; i < attempts && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	return min(d, cfg.MaxBackoff)
+}
+
+// budget returns the longest time a function can take over all attempts.
+func (cfg AsyncConfig) budget() time.Duration {
+	d := cfg.Timeout * time.Duration(cfg.MaxAttempts)
+	for attempt := 1; attempt < cfg.MaxAttempts; attempt++ {
+		d += cfg.backoff(attempt)
+	}
+
+	return d
+}
@@ -64,3 +64,416 @@ CREATE TABLE homes (
     PRIMARY KEY (home_id),
     FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 );
+
+-- Version: 1.05
+-- Description: Create tables refresh_tokens and revoked_tokens
+CREATE TABLE refresh_tokens (
+    token_id      UUID       NOT NULL,
+    user_id       UUID       NOT NULL,
+    family_id     UUID       NOT NULL,
+    token_hash    TEXT       NOT NULL,
+    date_expires  TIMESTAMP  NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_revoked  TIMESTAMP  NULL,
+
+    PRIMARY KEY (token_id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    token_id      TEXT       NOT NULL,
+    date_expires  TIMESTAMP  NOT NULL,
+    date_revoked  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (token_id)
+);
+
+-- Version: 1.06
+-- Description: Create table verification_tokens
+CREATE TABLE verification_tokens (
+    token_id      UUID       NOT NULL,
+    user_id       UUID       NOT NULL,
+    purpose       TEXT       NOT NULL,
+    token_hash    TEXT       NOT NULL,
+    date_expires  TIMESTAMP  NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_used     TIMESTAMP  NULL,
+
+    PRIMARY KEY (token_id),
+    UNIQUE (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.07
+-- Description: Create table identities
+CREATE TABLE identities (
+    identity_id       UUID       NOT NULL,
+    user_id           UUID       NOT NULL,
+    provider          TEXT       NOT NULL,
+    provider_user_id  TEXT       NOT NULL,
+    email             TEXT       NOT NULL,
+    date_created      TIMESTAMP  NOT NULL,
+    date_updated      TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (identity_id),
+    UNIQUE (provider, provider_user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.08
+-- Description: Create table api_keys
+CREATE TABLE api_keys (
+    apikey_id       UUID       NOT NULL,
+    user_id         UUID       NOT NULL,
+    name            TEXT       NOT NULL,
+    key_prefix      TEXT       NOT NULL,
+    key_hash        TEXT       NOT NULL,
+    roles           TEXT[]     NOT NULL,
+    date_expires    TIMESTAMP  NULL,
+    date_last_used  TIMESTAMP  NULL,
+    date_created    TIMESTAMP  NOT NULL,
+    date_revoked    TIMESTAMP  NULL,
+
+    PRIMARY KEY (apikey_id),
+    UNIQUE (key_prefix),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.09
+-- Description: Create table login_attempts
+CREATE TABLE login_attempts (
+    attempt_key        TEXT       NOT NULL,
+    failures           INT        NOT NULL,
+    date_last_failure  TIMESTAMP  NOT NULL,
+    date_locked_until  TIMESTAMP  NULL,
+
+    PRIMARY KEY (attempt_key)
+);
+
+-- Version: 1.10
+-- Description: Create table user_mfa and track mfa on refresh tokens
+CREATE TABLE user_mfa (
+    user_id          UUID       NOT NULL,
+    secret           BYTEA      NOT NULL,
+    recovery_hashes  TEXT[]     NOT NULL,
+    last_step        BIGINT     NOT NULL,
+    date_enabled     TIMESTAMP  NULL,
+    date_created     TIMESTAMP  NOT NULL,
+    date_updated     TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+ALTER TABLE refresh_tokens ADD COLUMN mfa BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Version: 1.11
+-- Description: Create table auth_decisions
+CREATE TABLE auth_decisions (
+    decision_id   UUID       NOT NULL,
+    subject       TEXT       NOT NULL,
+    rule          TEXT       NOT NULL,
+    input         JSONB      NOT NULL,
+    allowed       BOOLEAN    NOT NULL,
+    reason        TEXT       NOT NULL,
+    latency_us    BIGINT     NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (decision_id)
+);
+
+CREATE INDEX auth_decisions_subject_idx ON auth_decisions (subject, date_created);
+
+-- Version: 1.12
+-- Description: Create tables roles and user_roles with the built in roles
+CREATE TABLE roles (
+    name          TEXT       NOT NULL,
+    description   TEXT       NOT NULL,
+    permissions   TEXT[]     NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_updated  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (name)
+);
+
+CREATE TABLE user_roles (
+    user_id       UUID       NOT NULL,
+    role_name     TEXT       NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (user_id, role_name),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE
+);
+
+INSERT INTO roles (name, description, permissions, date_created, date_updated) VALUES
+	('ADMIN', 'Administrators', '{user:*,product:read,product:update,product:delete,home:read,home:update,home:delete,role:*,decision:read}', '2025-01-01 00:00:00', '2025-01-01 00:00:00'),
+	('USER', 'Users', '{user:read,user:update,product:write,product:read,home:write,home:read}', '2025-01-01 00:00:00', '2025-01-01 00:00:00');
+
+-- Version: 1.13
+-- Description: Create table orgs and scope users, products, homes and api keys to an org
+CREATE TABLE orgs (
+    org_id        UUID       NOT NULL,
+    name          TEXT       NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_updated  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (org_id),
+    UNIQUE (name)
+);
+
+INSERT INTO orgs (org_id, name, date_created, date_updated) VALUES
+	('00000000-0000-0000-0000-000000000001', 'Default', '2025-01-01 00:00:00', '2025-01-01 00:00:00');
+
+ALTER TABLE users ADD COLUMN org_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES orgs(org_id);
+ALTER TABLE products ADD COLUMN org_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES orgs(org_id);
+ALTER TABLE homes ADD COLUMN org_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES orgs(org_id);
+ALTER TABLE api_keys ADD COLUMN org_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES orgs(org_id);
+
+CREATE INDEX users_org_idx ON users (org_id);
+CREATE INDEX products_org_idx ON products (org_id);
+CREATE INDEX homes_org_idx ON homes (org_id);
+
+UPDATE roles SET permissions = array_append(permissions, 'org:*') WHERE name = 'ADMIN';
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.org_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id;
+
+-- Rows are also protected by row level security. The policies only apply
+-- when the app.tenant setting is present so connections that don't set it,
+-- like migrations and the tooling, keep working. Superusers and roles with
+-- BYPASSRLS are never subject to these policies.
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE POLICY users_tenant ON users
+    USING (COALESCE(current_setting('app.tenant', true), '') = '' OR org_id = current_setting('app.tenant', true)::UUID);
+
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+CREATE POLICY products_tenant ON products
+    USING (COALESCE(current_setting('app.tenant', true), '') = '' OR org_id = current_setting('app.tenant', true)::UUID);
+
+ALTER TABLE homes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE homes FORCE ROW LEVEL SECURITY;
+CREATE POLICY homes_tenant ON homes
+    USING (COALESCE(current_setting('app.tenant', true), '') = '' OR org_id = current_setting('app.tenant', true)::UUID);
+
+-- Version: 1.14
+-- Description: Create table sessions
+CREATE TABLE sessions (
+    session_id      UUID       NOT NULL,
+    user_id         UUID       NOT NULL,
+    token_id        TEXT       NOT NULL,
+    family_id       UUID       NULL,
+    device          TEXT       NOT NULL,
+    ip_address      TEXT       NOT NULL,
+    user_agent      TEXT       NOT NULL,
+    date_created    TIMESTAMP  NOT NULL,
+    date_last_seen  TIMESTAMP  NOT NULL,
+    date_expires    TIMESTAMP  NOT NULL,
+    date_revoked    TIMESTAMP  NULL,
+
+    PRIMARY KEY (session_id),
+    UNIQUE (token_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX sessions_family_id_idx ON sessions (family_id);
+
+-- Version: 1.15
+-- Description: Create tables outbox and outbox_dead_letters
+CREATE TABLE outbox (
+    seq                BIGSERIAL  NOT NULL,
+    event_id           UUID       NOT NULL,
+    aggregate_id       UUID       NOT NULL,
+    domain             TEXT       NOT NULL,
+    action             TEXT       NOT NULL,
+    params             BYTEA      NOT NULL,
+    attempts           INT        NOT NULL DEFAULT 0,
+    last_error         TEXT       NULL,
+    date_created       TIMESTAMP  NOT NULL,
+    date_next_attempt  TIMESTAMP  NOT NULL,
+    date_locked_until  TIMESTAMP  NULL,
+
+    PRIMARY KEY (event_id),
+    UNIQUE (seq)
+);
+
+CREATE INDEX outbox_aggregate_id_idx ON outbox (aggregate_id, seq);
+CREATE INDEX outbox_date_next_attempt_idx ON outbox (date_next_attempt);
+
+CREATE TABLE outbox_dead_letters (
+    event_id      UUID       NOT NULL,
+    aggregate_id  UUID       NOT NULL,
+    domain        TEXT       NOT NULL,
+    action        TEXT       NOT NULL,
+    params        BYTEA      NOT NULL,
+    attempts      INT        NOT NULL,
+    last_error    TEXT       NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_failed   TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (event_id)
+);
+
+-- Version: 1.16
+-- Description: Add the params version to outbox events
+ALTER TABLE outbox ADD COLUMN version INT NOT NULL DEFAULT 0;
+ALTER TABLE outbox_dead_letters ADD COLUMN version INT NOT NULL DEFAULT 0;
+
+-- Version: 1.17
+-- Description: Add the archived state to products and homes
+ALTER TABLE products ADD COLUMN date_archived TIMESTAMP NULL;
+ALTER TABLE homes ADD COLUMN date_archived TIMESTAMP NULL;
+
+CREATE INDEX products_user_id_idx ON products (user_id);
+CREATE INDEX homes_user_id_idx ON homes (user_id);
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.org_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.date_archived IS NULL;
+
+-- Version: 1.18
+-- Description: Soft delete users, products and homes
+ALTER TABLE users ADD COLUMN date_deleted TIMESTAMP NULL;
+ALTER TABLE products ADD COLUMN date_deleted TIMESTAMP NULL;
+ALTER TABLE homes ADD COLUMN date_deleted TIMESTAMP NULL;
+
+CREATE INDEX users_date_deleted_idx ON users (date_deleted) WHERE date_deleted IS NOT NULL;
+CREATE INDEX products_date_deleted_idx ON products (date_deleted) WHERE date_deleted IS NOT NULL;
+CREATE INDEX homes_date_deleted_idx ON homes (date_deleted) WHERE date_deleted IS NOT NULL;
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.org_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.date_archived IS NULL AND
+    p.date_deleted IS NULL AND
+    u.date_deleted IS NULL;
+
+-- Version: 1.19
+-- Description: Create table audits
+CREATE TABLE audits (
+    audit_id      UUID       NOT NULL,
+    entity        TEXT       NOT NULL,
+    entity_id     UUID       NOT NULL,
+    org_id        UUID       NOT NULL,
+    action        TEXT       NOT NULL,
+    actor_id      UUID       NULL,
+    trace_id      TEXT       NOT NULL,
+    before        JSONB      NULL,
+    after         JSONB      NULL,
+    date_created  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (audit_id)
+);
+
+CREATE INDEX audits_entity_idx ON audits (entity, entity_id, date_created);
+
+ALTER TABLE audits ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audits FORCE ROW LEVEL SECURITY;
+CREATE POLICY audits_tenant ON audits
+    USING (COALESCE(current_setting('app.tenant', true), '') = '' OR org_id = current_setting('app.tenant', true)::UUID);
+
+-- Version: 1.20
+-- Description: Add a row version to users, products and homes
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE homes ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Version: 1.21
+-- Description: Record the tenant of the subject with each auth decision
+ALTER TABLE auth_decisions ADD COLUMN org_id UUID NULL;
+
+CREATE INDEX auth_decisions_org_idx ON auth_decisions (org_id, date_created);
+
+-- Version: 1.22
+-- Description: Move org and role management from tenant admins to platform operators
+UPDATE roles SET permissions = array_remove(array_remove(permissions, 'org:*'), 'role:*') || '{role:read,user_role:*}' WHERE name = 'ADMIN';
+
+INSERT INTO roles (name, description, permissions, date_created, date_updated) VALUES
+	('PLATFORM', 'Platform operators', '{org:*,role:*,user_role:*}', '2025-01-01 00:00:00', '2025-01-01 00:00:00');
+
+-- Version: 1.23
+-- Description: Add a tenant role so row level security applies to the service
+-- The service connects as a role that owns the tables and may be a superuser,
+-- so it is never subject to the row level security policies. Transactions
+-- scoped to a tenant switch to this role, which has no way around them. Roles
+-- are shared by every database in the cluster so the role may already exist.
+DO $$
+BEGIN
+    CREATE ROLE sales_tenant NOLOGIN NOSUPERUSER NOBYPASSRLS;
+EXCEPTION WHEN duplicate_object OR unique_violation THEN
+    NULL;
+END
+$$;
+
+DO $$
+BEGIN
+    GRANT sales_tenant TO CURRENT_USER;
+EXCEPTION WHEN duplicate_object OR unique_violation THEN
+    NULL;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO sales_tenant;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO sales_tenant;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO sales_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO sales_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO sales_tenant;
+
+ALTER VIEW view_products SET (security_invoker = true);
+
+-- Version: 1.24
+-- Description: Track email verification apart from the enabled state of users
+-- Existing users and users created by an admin are treated as verified.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Version: 1.25
+-- Description: Only require emails to be unique among users that aren't deleted
+-- A deleted user keeps their email so it can be taken again until they are restored.
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_active_idx ON users (email) WHERE date_deleted IS NULL;
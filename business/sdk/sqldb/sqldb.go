@@ -283,15 +283,16 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return queryError(err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
+		// A statement like an UPDATE with a RETURNING clause can report its
+		// error when the first row is read.
+		if err := rows.Err(); err != nil {
+			return queryError(err)
+		}
 		return ErrDBNotFound
 	}
 
@@ -302,6 +303,22 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	return nil
 }
 
+// queryError maps the postgres errors a query can report to the errors
+// provided by this package.
+func queryError(err error) error {
+	var pqerr *pgconn.PgError
+	if errors.As(err, &pqerr) {
+		switch pqerr.Code {
+		case undefinedTable:
+			return ErrUndefinedTable
+		case uniqueViolation:
+			return ErrDBDuplicatedEntry
+		}
+	}
+
+	return err
+}
+
 // queryString provides a pretty print version of the query and parameters.
 func queryString(query string, args any) string {
 	query, params, err := sqlx.Named(query, args)
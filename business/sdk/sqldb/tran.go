@@ -1,8 +1,11 @@
 package sqldb
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -47,3 +50,35 @@ func GetExtContext(tx CommitRollbacker) (sqlx.ExtContext, error) {
 
 	return ec, nil
 }
+
+// SetTenant scopes the transaction to the tenant so the row level security
+// policies only expose rows that belong to it. The connection's role bypasses
+// those policies, so the transaction also switches to the sales_tenant role,
+// which doesn't. Both settings end with the transaction. This backs up the
+// tenant filters applied by the stores, it doesn't replace them.
+func SetTenant(ctx context.Context, log *logger.Logger, tx CommitRollbacker, tenant uuid.UUID) error {
+	ec, err := GetExtContext(tx)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Tenant string `db:"tenant"`
+	}{
+		Tenant: tenant.String(),
+	}
+
+	const q = `SELECT set_config('app.tenant', :tenant, true)`
+
+	if err := NamedExecContext(ctx, log, ec, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const qRole = `SET LOCAL ROLE sales_tenant`
+
+	if err := ExecContext(ctx, log, ec, qRole); err != nil {
+		return fmt.Errorf("execcontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package permission represents a permission in the system.
+package permission
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Permission represents the right to perform an action on a type of resource.
+// It is written as "<resource>:<action>" like product:read. The write action
+// covers create, update and delete and * covers every action.
+type Permission struct {
+	value string
+}
+
+// String returns the value of the permission.
+func (p Permission) String() string {
+	return p.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Permission) Equal(p2 Permission) bool {
+	return p.value == p2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (p Permission) MarshalText() ([]byte, error) {
+	return []byte(p.value), nil
+}
+
+// Grants reports whether holding the permission also gives the right
+// described by p2, following the same rules as the authorization policy.
+func (p Permission) Grants(p2 Permission) bool {
+	if p.value == p2.value {
+		return true
+	}
+
+	typ, action, _ := strings.Cut(p.value, ":")
+	typ2, action2, _ := strings.Cut(p2.value, ":")
+
+	if typ != typ2 {
+		return false
+	}
+
+	switch action {
+	case "*":
+		return true
+	case "write":
+		return action2 == "create" || action2 == "update" || action2 == "delete"
+	}
+
+	return false
+}
+
+// GrantsAll reports whether the held permissions together give every one of
+// the specified permissions.
+func GrantsAll(held []Permission, perms []Permission) bool {
+	for _, p2 := range perms {
+		if !slices.ContainsFunc(held, func(p Permission) bool { return p.Grants(p2) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// =============================================================================
+
+var permissionRegEx = regexp.MustCompile(`^[a-z][a-z_]{1,31}:(read|create|update|delete|write|\*)$`)
+
+// Parse parses the string value and returns a permission if the value
+// complies with the rules for a permission.
+func Parse(value string) (Permission, error) {
+	if !permissionRegEx.MatchString(value) {
+		return Permission{}, fmt.Errorf("invalid permission %q", value)
+	}
+
+	return Permission{value}, nil
+}
+
+// MustParse parses the string value and returns a permission if the value
+// complies with the rules for a permission. If an error occurs the function
+// panics.
+func MustParse(value string) Permission {
+	p, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// ParseToString takes a collection of permissions and converts them to a
+// slice of string.
+func ParseToString(perms []Permission) []string {
+	values := make([]string, len(perms))
+	for i, p := range perms {
+		values[i] = p.String()
+	}
+
+	return values
+}
+
+// ParseMany takes a collection of strings and converts them to a slice of
+// permissions.
+func ParseMany(values []string) ([]Permission, error) {
+	perms := make([]Permission, len(values))
+	for i, value := range values {
+		p, err := Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		perms[i] = p
+	}
+
+	return perms, nil
+}
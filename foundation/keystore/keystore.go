@@ -4,6 +4,11 @@ package keystore
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -14,18 +19,26 @@ import (
 	"io/fs"
 	"path"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // key represents key information.
 type key struct {
 	privatePEM string
 	publicPEM  string
+	public     crypto.PublicKey
+	retired    time.Time
 }
 
 // KeyStore represents an in memory store implementation of the
 // KeyLookup interface for use with the auth package.
 type KeyStore struct {
-	store map[string]key
+	mu        sync.RWMutex
+	store     map[string]key
+	activeKID string
 }
 
 // New constructs an empty KeyStore ready for use.
@@ -50,15 +63,13 @@ func (ks *KeyStore) LoadByJSON(document string) (int, error) {
 		return len(ks.store), fmt.Errorf("unable to marshal document: %w", err)
 	}
 
-	publicPEM, err := toPublicPEM(d.PEM)
+	key, err := newKey(d.PEM)
 	if err != nil {
 		return 0, fmt.Errorf("converting private PEM to public: %w", err)
 	}
 
-	key := key{
-		privatePEM: d.PEM,
-		publicPEM:  publicPEM,
-	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
 
 	ks.store[d.Key] = key
 
@@ -98,16 +109,13 @@ func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
 			return fmt.Errorf("reading auth private key: %w", err)
 		}
 
-		privatePEM := string(pem)
-		publicPEM, err := toPublicPEM(privatePEM)
+		key, err := newKey(string(pem))
 		if err != nil {
 			return fmt.Errorf("converting private PEM to public: %w", err)
 		}
 
-		key := key{
-			privatePEM: privatePEM,
-			publicPEM:  publicPEM,
-		}
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
 
 		ks.store[strings.TrimSuffix(dirEntry.Name(), ".pem")] = key
 
@@ -118,11 +126,17 @@ func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
 		return 0, fmt.Errorf("walking directory: %w", err)
 	}
 
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	return len(ks.store), nil
 }
 
 // PrivateKey searches the key store for a given kid and returns the private key.
 func (ks *KeyStore) PrivateKey(kid string) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	key, found := ks.store[kid]
 	if !found {
 		return "", errors.New("kid lookup failed")
@@ -133,6 +147,9 @@ func (ks *KeyStore) PrivateKey(kid string) (string, error) {
 
 // PublicKey searches the key store for a given kid and returns the public key.
 func (ks *KeyStore) PublicKey(kid string) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	key, found := ks.store[kid]
 	if !found {
 		return "", errors.New("kid lookup failed")
@@ -141,10 +158,172 @@ func (ks *KeyStore) PublicKey(kid string) (string, error) {
 	return key.publicPEM, nil
 }
 
-func toPublicPEM(privatePEM string) (string, error) {
+// SetActive marks the specified kid as the key to use for signing tokens.
+func (ks *KeyStore) SetActive(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, found := ks.store[kid]
+	if !found {
+		return errors.New("kid lookup failed")
+	}
+
+	key.retired = time.Time{}
+	ks.store[kid] = key
+	ks.activeKID = kid
+
+	return nil
+}
+
+// ActiveKID returns the kid of the key to use for signing tokens.
+func (ks *KeyStore) ActiveKID() string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return ks.activeKID
+}
+
+// Rotate generates a new private key of the same type as the active key and
+// makes it the active signing key. An RSA 2048 key is generated when there is
+// no active key. The previously active key is retired, it can still verify
+// tokens until the grace period has passed. Retired keys past the grace
+// period are removed. The kid of the new key is returned.
+//
+// Keys generated by Rotate only exist in memory and are lost on restart, so
+// only a single instance of a service can use it. When multiple instances
+// share keys, rotate by provisioning a new key to all instances and calling
+// SetActive instead.
+func (ks *KeyStore) Rotate(grace time.Duration) (string, error) {
+	ks.mu.RLock()
+	active := ks.store[ks.activeKID]
+	ks.mu.RUnlock()
+
+	pk, err := generateKey(active.public)
+	if err != nil {
+		return "", fmt.Errorf("generating key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	if err != nil {
+		return "", fmt.Errorf("marshaling private key: %w", err)
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	key, err := newKey(string(privatePEM))
+	if err != nil {
+		return "", fmt.Errorf("converting private PEM to public: %w", err)
+	}
+
+	kid := uuid.NewString()
+	now := time.Now()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if old, found := ks.store[ks.activeKID]; found {
+		old.retired = now
+		ks.store[ks.activeKID] = old
+	}
+
+	ks.store[kid] = key
+	ks.activeKID = kid
+
+	ks.prune(now, grace)
+
+	return kid, nil
+}
+
+// Retire marks the key as retired. It can still verify tokens until it is
+// removed by Prune. The active key can't be retired.
+func (ks *KeyStore) Retire(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if kid == ks.activeKID {
+		return errors.New("can't retire the active key")
+	}
+
+	key, found := ks.store[kid]
+	if !found {
+		return errors.New("kid lookup failed")
+	}
+
+	if key.retired.IsZero() {
+		key.retired = time.Now()
+		ks.store[kid] = key
+	}
+
+	return nil
+}
+
+// Prune removes retired keys that are past the grace period.
+func (ks *KeyStore) Prune(grace time.Duration) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.prune(time.Now(), grace)
+}
+
+func (ks *KeyStore) prune(now time.Time, grace time.Duration) {
+	for kid, key := range ks.store {
+		if kid == ks.activeKID || key.retired.IsZero() {
+			continue
+		}
+
+		if now.Sub(key.retired) >= grace {
+			delete(ks.store, kid)
+		}
+	}
+}
+
+// =============================================================================
+
+// generateKey generates a private key of the same type and size as the
+// specified public key.
+func generateKey(public crypto.PublicKey) (any, error) {
+	switch pub := public.(type) {
+	case nil:
+		return rsa.GenerateKey(rand.Reader, 2048)
+
+	case *rsa.PublicKey:
+		return rsa.GenerateKey(rand.Reader, pub.N.BitLen())
+
+	case *ecdsa.PublicKey:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	case ed25519.PublicKey:
+		_, pk, err := ed25519.GenerateKey(rand.Reader)
+		return pk, err
+	}
+
+	return nil, fmt.Errorf("unsupported public key type %T", public)
+}
+
+func newKey(privatePEM string) (key, error) {
+	public, err := toPublicKey(privatePEM)
+	if err != nil {
+		return key{}, err
+	}
+
+	publicPEM, err := toPublicPEM(public)
+	if err != nil {
+		return key{}, err
+	}
+
+	k := key{
+		privatePEM: privatePEM,
+		publicPEM:  publicPEM,
+		public:     public,
+	}
+
+	return k, nil
+}
+
+func toPublicKey(privatePEM string) (crypto.PublicKey, error) {
 	block, _ := pem.Decode([]byte(privatePEM))
 	if block == nil {
-		return "", errors.New("invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key")
+		return nil, errors.New("invalid key: Key must be a PEM encoded PKCS1, PKCS8 or SEC1 key")
 	}
 
 	var parsedKey any
@@ -152,16 +331,32 @@ func toPublicPEM(privatePEM string) (string, error) {
 	if err != nil {
 		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
-			return "", err
+			parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	pk, ok := parsedKey.(*rsa.PrivateKey)
-	if !ok {
-		return "", errors.New("key is not a valid RSA private key")
+	switch pk := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		return &pk.PublicKey, nil
+
+	case *ecdsa.PrivateKey:
+		if pk.Curve != elliptic.P256() {
+			return nil, errors.New("ecdsa key must use the P-256 curve")
+		}
+		return &pk.PublicKey, nil
+
+	case ed25519.PrivateKey:
+		return pk.Public(), nil
 	}
 
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&pk.PublicKey)
+	return nil, fmt.Errorf("unsupported private key type %T", parsedKey)
+}
+
+func toPublicPEM(public crypto.PublicKey) (string, error) {
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(public)
 	if err != nil {
 		return "", fmt.Errorf("marshaling public key: %w", err)
 	}
@@ -0,0 +1,152 @@
+package keystore_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/keystore"
+)
+
+func Test_Rotate(t *testing.T) {
+	ks := keystore.New()
+
+	kid1, err := ks.Rotate(time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to rotate in a key : %s", err)
+	}
+
+	if ks.ActiveKID() != kid1 {
+		t.Fatalf("Should have the new key active, got %q exp %q", ks.ActiveKID(), kid1)
+	}
+
+	kid2, err := ks.Rotate(time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to rotate in a second key : %s", err)
+	}
+
+	if ks.ActiveKID() != kid2 {
+		t.Fatalf("Should have the second key active, got %q exp %q", ks.ActiveKID(), kid2)
+	}
+
+	// The retired key is still in its grace period.
+	if _, err := ks.PublicKey(kid1); err != nil {
+		t.Fatalf("Should still verify with the retired key : %s", err)
+	}
+
+	jwks, err := ks.JWKS()
+	if err != nil {
+		t.Fatalf("Should be able to produce the jwks : %s", err)
+	}
+
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Should publish both keys, got %d", len(jwks.Keys))
+	}
+
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" || jwk.Algorithm != "RS256" || jwk.N == "" || jwk.E != "AQAB" {
+			t.Errorf("Got: %+v", jwk)
+			t.Error("Should publish a valid RSA key")
+		}
+	}
+
+	// Once the grace period has passed the retired key is removed.
+	ks.Prune(0)
+
+	if _, err := ks.PublicKey(kid1); err == nil {
+		t.Fatal("Should not find the retired key after the grace period")
+	}
+
+	if _, err := ks.PrivateKey(kid2); err != nil {
+		t.Fatalf("Should keep the active key : %s", err)
+	}
+}
+
+func Test_RotateKeyType(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ecdsa key : %s", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate an ed25519 key : %s", err)
+	}
+
+	tests := []struct {
+		name string
+		key  any
+		kty  string
+		alg  string
+	}{
+		{name: "p256", key: ecKey, kty: "EC", alg: "ES256"},
+		{name: "ed25519", key: edKey, kty: "OKP", alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, err := x509.MarshalPKCS8PrivateKey(tt.key)
+			if err != nil {
+				t.Fatalf("Should be able to marshal the key : %s", err)
+			}
+
+			doc, err := json.Marshal(map[string]string{
+				"key": "active",
+				"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			})
+			if err != nil {
+				t.Fatalf("Should be able to marshal the document : %s", err)
+			}
+
+			ks := keystore.New()
+
+			if _, err := ks.LoadByJSON(string(doc)); err != nil {
+				t.Fatalf("Should be able to load the key : %s", err)
+			}
+
+			if err := ks.SetActive("active"); err != nil {
+				t.Fatalf("Should be able to activate the key : %s", err)
+			}
+
+			kid, err := ks.Rotate(time.Hour)
+			if err != nil {
+				t.Fatalf("Should be able to rotate in a key : %s", err)
+			}
+
+			jwks, err := ks.JWKS()
+			if err != nil {
+				t.Fatalf("Should be able to produce the jwks : %s", err)
+			}
+
+			for _, jwk := range jwks.Keys {
+				if jwk.KeyType != tt.kty || jwk.Algorithm != tt.alg {
+					t.Errorf("Got: %+v", jwk)
+					t.Errorf("Should publish a %s key for kid %s", tt.alg, kid)
+				}
+			}
+		})
+	}
+}
+
+func Test_SetActive(t *testing.T) {
+	ks := keystore.New()
+
+	if err := ks.SetActive("unknown"); err == nil {
+		t.Fatal("Should not be able to activate an unknown key")
+	}
+
+	kid, err := ks.Rotate(time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to rotate in a key : %s", err)
+	}
+
+	if err := ks.Retire(kid); err == nil {
+		t.Fatal("Should not be able to retire the active key")
+	}
+}
@@ -0,0 +1,156 @@
+// Package mailer provides support for sending email messages. The Mailer
+// interface allows the transport to be swapped between a real SMTP server and
+// an in-memory implementation for testing.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message represents an email message to be sent.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer declares the behavior required to send an email message.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// =============================================================================
+
+// SMTPConfig represents the information required to connect to an SMTP server.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP sends email messages through an SMTP server.
+type SMTP struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP constructs a mailer that sends messages through the specified
+// SMTP server. Authentication is only used when a username is provided.
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+
+	if cfg.From == "" {
+		return nil, errors.New("smtp from address is required")
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	s := SMTP{
+		addr: net.JoinHostPort(cfg.Host, fmt.Sprint(cfg.Port)),
+		host: cfg.Host,
+		auth: auth,
+		from: cfg.From,
+	}
+
+	return &s, nil
+}
+
+// Send delivers the message to the SMTP server.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	ch := make(chan error, 1)
+
+	go func() {
+		ch <- smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, s.build(msg))
+	}()
+
+	select {
+	case err := <-ch:
+		if err != nil {
+			return fmt.Errorf("sendmail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTP) build(msg Message) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
+
+// =============================================================================
+
+// Memory stores email messages in memory instead of sending them. This is
+// useful for testing and local development.
+type Memory struct {
+	mu       sync.RWMutex
+	messages []Message
+}
+
+// NewMemory constructs an in-memory mailer.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Send records the message.
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// Messages returns a copy of all the messages that were sent.
+func (m *Memory) Messages() []Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgs := make([]Message, len(m.messages))
+	copy(msgs, m.messages)
+
+	return msgs
+}
+
+// Last returns the most recent message sent to the specified address.
+func (m *Memory) Last(to string) (Message, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
@@ -0,0 +1,45 @@
+package mailer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/mailer"
+)
+
+func Test_Memory(t *testing.T) {
+	m := mailer.NewMemory()
+
+	msgs := []mailer.Message{
+		{To: "bill@ardanlabs.com", Subject: "one", Body: "first"},
+		{To: "jill@ardanlabs.com", Subject: "two", Body: "second"},
+		{To: "bill@ardanlabs.com", Subject: "three", Body: "third"},
+	}
+
+	for _, msg := range msgs {
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Should be able to send a message : %s", err)
+		}
+	}
+
+	if got := len(m.Messages()); got != len(msgs) {
+		t.Errorf("Exp: %d", len(msgs))
+		t.Errorf("Got: %d", got)
+		t.Fatal("Should have recorded all the messages")
+	}
+
+	msg, found := m.Last("bill@ardanlabs.com")
+	if !found {
+		t.Fatal("Should find a message for bill")
+	}
+
+	if msg.Subject != "three" {
+		t.Errorf("Exp: %s", "three")
+		t.Errorf("Got: %s", msg.Subject)
+		t.Error("Should get the most recent message")
+	}
+
+	if _, found := m.Last("nobody@ardanlabs.com"); found {
+		t.Error("Should not find a message for an unknown address")
+	}
+}
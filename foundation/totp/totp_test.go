@@ -0,0 +1,104 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/totp"
+)
+
+func Test_Code(t *testing.T) {
+
+	// The SHA1 test vectors from RFC 6238 appendix B truncated to 6 digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tt := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tst := range tt {
+		got, err := totp.Code(secret, time.Unix(tst.unix, 0))
+		if err != nil {
+			t.Fatalf("Should be able to produce a code : %s", err)
+		}
+
+		if got != tst.code {
+			t.Errorf("Exp: %s", tst.code)
+			t.Errorf("Got: %s", got)
+			t.Errorf("Should get the expected code for time %d", tst.unix)
+		}
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Should be able to generate a secret : %s", err)
+	}
+
+	now := time.Now()
+
+	code, err := totp.Code(secret, now)
+	if err != nil {
+		t.Fatalf("Should be able to produce a code : %s", err)
+	}
+
+	step, ok := totp.Validate(secret, code, now)
+	if !ok {
+		t.Fatalf("Should validate the current code")
+	}
+
+	if step != totp.Step(now) {
+		t.Errorf("Exp: %d", totp.Step(now))
+		t.Errorf("Got: %d", step)
+		t.Errorf("Should return the step the code matched")
+	}
+
+	if _, ok := totp.Validate(secret, code, now.Add(totp.Period)); !ok {
+		t.Errorf("Should accept the code one step later")
+	}
+
+	if _, ok := totp.Validate(secret, code, now.Add(3*totp.Period)); ok {
+		t.Errorf("Should reject the code three steps later")
+	}
+
+	if _, ok := totp.Validate(secret, "12345", now); ok {
+		t.Errorf("Should reject a code with the wrong number of digits")
+	}
+}
+
+func Test_URI(t *testing.T) {
+	uri := totp.URI("Ardan Labs", "bill@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("Should be able to parse the uri : %s", err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("Got: %s", uri)
+		t.Errorf("Should produce an otpauth totp uri")
+	}
+
+	if u.Path != "/Ardan Labs:bill@example.com" {
+		t.Errorf("Exp: %s", "/Ardan Labs:bill@example.com")
+		t.Errorf("Got: %s", u.Path)
+		t.Errorf("Should label the uri with the issuer and account")
+	}
+
+	if got := u.Query().Get("secret"); got != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Exp: %s", "JBSWY3DPEHPK3PXP")
+		t.Errorf("Got: %s", got)
+		t.Errorf("Should include the secret")
+	}
+}
@@ -87,8 +87,31 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 }
 
 // Start lookups a job by key and launches a goroutine to perform the work. A
-// work key is returned so the caller can cancel work early.
+// work key is returned so the caller can cancel work early. The deadline of
+// the context bounds both the wait for a free slot and the work itself.
 func (w *Worker) Start(ctx context.Context, jobFn JobFn) (string, error) {
+	deadline := func() time.Time {
+		if d, ok := ctx.Deadline(); ok {
+			return d
+		}
+		return time.Now().Add(time.Second)
+	}
+
+	return w.start(ctx, jobFn, deadline)
+}
+
+// StartFor launches a goroutine to perform the work like Start, but the
+// context only bounds the wait for a free slot. The work is given the
+// specified amount of time once it starts.
+func (w *Worker) StartFor(ctx context.Context, timeout time.Duration, jobFn JobFn) (string, error) {
+	deadline := func() time.Time {
+		return time.Now().Add(timeout)
+	}
+
+	return w.start(ctx, jobFn, deadline)
+}
+
+func (w *Worker) start(ctx context.Context, jobFn JobFn, deadline func() time.Time) (string, error) {
 
 	// We need to block here waiting to capture a semaphore, timeout or shutdown.
 	// The shutdown is first to handle that event as priority.
@@ -103,14 +126,8 @@ func (w *Worker) Start(ctx context.Context, jobFn JobFn) (string, error) {
 	// Need a unique key for this work.
 	workKey := uuid.NewString()
 
-	// Let's continue with the current context's deadline.
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		deadline = time.Now().Add(time.Second)
-	}
-
 	// Create a cancel function and keep it for stop/shutdown purposes.
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline())
 
 	// Register this new G as running.
 	w.trackWork(workKey, cancel)